@@ -61,4 +61,325 @@ CREATE TABLE IF NOT EXISTS instances (
 -- Add indexes for faster lookups on foreign keys and soft delete columns
 CREATE INDEX IF NOT EXISTS idx_components_figma_file_id ON components (figma_file_id);
 
-CREATE INDEX IF NOT EXISTS idx_instances_component_id ON instances (component_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_instances_component_id ON instances (component_id);
+
+-- Table for tracking asynchronous parse jobs
+CREATE TABLE IF NOT EXISTS parse_jobs (
+    id SERIAL PRIMARY KEY,
+    figma_url TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, running, succeeded, failed
+    figma_file_id INTEGER, -- set once the job succeeded
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_parse_jobs_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_parse_jobs_status ON parse_jobs (status);
+
+-- Instance owning a parse job, which keeps it alive with a heartbeat. Jobs of a restarted or gone instance are swept
+ALTER TABLE parse_jobs
+    ADD COLUMN IF NOT EXISTS worker_id VARCHAR(255) NOT NULL DEFAULT '', -- PARSE_WORKER_ID of the replica holding the job request
+    ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_parse_jobs_worker_id ON parse_jobs (worker_id);
+
+
+-- Table for storing the full document hierarchy of a parsed Figma file (pages, frames, groups, ...)
+CREATE TABLE IF NOT EXISTS nodes (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    parent_id INTEGER, -- NULL for the DOCUMENT root
+    parent_node_id VARCHAR(100) NOT NULL DEFAULT '',
+    node_id VARCHAR(100) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    depth INTEGER NOT NULL,
+    sort_order INTEGER NOT NULL, -- position among siblings
+    x DOUBLE PRECISION,
+    y DOUBLE PRECISION,
+    width DOUBLE PRECISION,
+    height DOUBLE PRECISION,
+    visible BOOLEAN DEFAULT TRUE NOT NULL,
+    properties JSONB,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_nodes_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT fk_nodes_parent FOREIGN KEY (parent_id) REFERENCES nodes (id) ON DELETE CASCADE,
+    CONSTRAINT unique_node_per_file UNIQUE (figma_file_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_nodes_figma_file_id ON nodes (figma_file_id);
+
+CREATE INDEX IF NOT EXISTS idx_nodes_parent_id ON nodes (parent_id);
+
+-- Table for storing the pages (CANVAS nodes) of a parsed Figma file
+CREATE TABLE IF NOT EXISTS pages (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    node_id VARCHAR(100) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    background_color VARCHAR(9) NOT NULL DEFAULT '', -- #RRGGBB or #RRGGBBAA
+    sort_order INTEGER NOT NULL,
+    x DOUBLE PRECISION, -- content bounds, computed from the page children
+    y DOUBLE PRECISION,
+    width DOUBLE PRECISION,
+    height DOUBLE PRECISION,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_pages_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT unique_page_per_file UNIQUE (figma_file_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pages_figma_file_id ON pages (figma_file_id);
+
+-- Link components and instances to the page they are placed on (NULL for remote components)
+ALTER TABLE components ADD COLUMN IF NOT EXISTS page_id INTEGER REFERENCES pages (id) ON DELETE SET NULL;
+
+ALTER TABLE instances ADD COLUMN IF NOT EXISTS page_id INTEGER REFERENCES pages (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_components_page_id ON components (page_id);
+
+CREATE INDEX IF NOT EXISTS idx_instances_page_id ON instances (page_id);
+
+-- Table for storing the copy and typography of TEXT nodes, linked to the closest component or instance owning them
+CREATE TABLE IF NOT EXISTS text_nodes (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    page_id INTEGER,
+    component_id INTEGER,
+    instance_id INTEGER,
+    node_id VARCHAR(100) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    characters TEXT NOT NULL DEFAULT '',
+    font_family VARCHAR(255) NOT NULL DEFAULT '',
+    font_weight DOUBLE PRECISION,
+    font_size DOUBLE PRECISION,
+    style JSONB, -- full typography: line height, letter spacing, text case, decoration, alignment
+    style_overrides JSONB, -- characterStyleOverrides and styleOverrideTable, NULL without overrides
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_text_nodes_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT fk_text_nodes_page FOREIGN KEY (page_id) REFERENCES pages (id) ON DELETE SET NULL,
+    CONSTRAINT fk_text_nodes_component FOREIGN KEY (component_id) REFERENCES components (id) ON DELETE SET NULL,
+    CONSTRAINT fk_text_nodes_instance FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE SET NULL,
+    CONSTRAINT unique_text_node_per_file UNIQUE (figma_file_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_text_nodes_figma_file_id ON text_nodes (figma_file_id);
+
+CREATE INDEX IF NOT EXISTS idx_text_nodes_page_id ON text_nodes (page_id);
+
+-- Position relative to the parent frame, rotation and constraints, next to the absolute canvas coordinates
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS relative_x DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS relative_y DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rotation DOUBLE PRECISION NOT NULL DEFAULT 0, -- degrees, counter clockwise
+    ADD COLUMN IF NOT EXISTS constraint_horizontal VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS constraint_vertical VARCHAR(20) NOT NULL DEFAULT '';
+
+ALTER TABLE instances
+    ADD COLUMN IF NOT EXISTS relative_x DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS relative_y DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rotation DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS constraint_horizontal VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS constraint_vertical VARCHAR(20) NOT NULL DEFAULT '';
+
+ALTER TABLE nodes
+    ADD COLUMN IF NOT EXISTS relative_x DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS relative_y DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rotation DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS constraint_horizontal VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS constraint_vertical VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Component properties: variants link to their component set and keep their key/value pairs,
+-- sets and components keep their property definitions (VARIANT, BOOLEAN, TEXT, INSTANCE_SWAP)
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS component_set_id INTEGER REFERENCES components (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS variant_properties JSONB, -- e.g. {"Size": "Large", "State": "Default"}
+    ADD COLUMN IF NOT EXISTS property_definitions JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_components_component_set_id ON components (component_set_id);
+
+CREATE INDEX IF NOT EXISTS idx_components_variant_properties ON components USING GIN (variant_properties);
+
+-- Instance overrides: component property values set on the instance and the fields it overrides,
+-- override_count sums the overridden fields to find override heavy usage
+ALTER TABLE instances
+    ADD COLUMN IF NOT EXISTS component_properties JSONB, -- e.g. {"Label#2:0": {"type": "TEXT", "value": "Get started"}}
+    ADD COLUMN IF NOT EXISTS overrides JSONB,            -- e.g. [{"id": "I1:2;2:4", "overriddenFields": ["characters"]}]
+    ADD COLUMN IF NOT EXISTS override_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_instances_override_count ON instances (override_count);
+
+-- Nested instances: the instance an instance is nested in, and the component whose definition holds it
+ALTER TABLE instances
+    ADD COLUMN IF NOT EXISTS parent_instance_id INTEGER REFERENCES instances (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS containing_component_id INTEGER REFERENCES components (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_instances_parent_instance_id ON instances (parent_instance_id);
+
+CREATE INDEX IF NOT EXISTS idx_instances_containing_component_id ON instances (containing_component_id);
+
+-- External components: stubs for components defined in another file (e.g. a team library) so their instances
+-- are kept. component_key is the Figma published key, shared by a component across files
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS component_key VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS is_external BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_components_component_key ON components (component_key);
+
+-- Team libraries: components, component sets and styles published by a Figma team, refreshed by POST /libraries/sync.
+-- External components of parsed files link to the library component with the same key
+CREATE TABLE IF NOT EXISTS library_components (
+    id SERIAL PRIMARY KEY,
+    team_id VARCHAR(50) NOT NULL,
+    component_key VARCHAR(255) NOT NULL UNIQUE,
+    file_key VARCHAR(255) NOT NULL,
+    node_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL, -- COMPONENT or COMPONENT_SET
+    description TEXT NOT NULL DEFAULT '',
+    component_set_node_id VARCHAR(255) NOT NULL DEFAULT '',
+    page_name VARCHAR(255) NOT NULL DEFAULT '',
+    frame_name VARCHAR(255) NOT NULL DEFAULT '',
+    thumbnail_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_library_components_team_id ON library_components (team_id);
+
+CREATE TABLE IF NOT EXISTS library_styles (
+    id SERIAL PRIMARY KEY,
+    team_id VARCHAR(50) NOT NULL,
+    style_key VARCHAR(255) NOT NULL UNIQUE,
+    file_key VARCHAR(255) NOT NULL,
+    node_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    style_type VARCHAR(20) NOT NULL, -- FILL, TEXT, EFFECT or GRID
+    description TEXT NOT NULL DEFAULT '',
+    thumbnail_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_library_styles_team_id ON library_styles (team_id);
+
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS library_component_id INTEGER REFERENCES library_components (id) ON DELETE SET NULL;
+
+-- Styles: shared color, text, effect and grid styles of a file, with the value resolved from a node using them,
+-- and which components and instances use them (on the node itself or on a node inside it)
+CREATE TABLE IF NOT EXISTS styles (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    node_id VARCHAR(100) NOT NULL, -- style ID
+    style_key VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(500) NOT NULL,
+    style_type VARCHAR(20) NOT NULL, -- FILL, TEXT, EFFECT or GRID
+    description TEXT NOT NULL DEFAULT '',
+    is_external BOOLEAN NOT NULL DEFAULT FALSE,
+    value JSONB,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_styles_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT unique_style_per_file UNIQUE (figma_file_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_styles_name ON styles (figma_file_id, name);
+
+CREATE TABLE IF NOT EXISTS style_usages (
+    id SERIAL PRIMARY KEY,
+    style_id INTEGER NOT NULL REFERENCES styles (id) ON DELETE CASCADE,
+    component_id INTEGER REFERENCES components (id) ON DELETE CASCADE,
+    instance_id INTEGER REFERENCES instances (id) ON DELETE CASCADE,
+    node_id VARCHAR(100) NOT NULL, -- node the style is applied to
+    property VARCHAR(20) NOT NULL, -- fill, stroke, text, effect or grid
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT style_usage_owner CHECK (component_id IS NOT NULL OR instance_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_style_usages_style_id ON style_usages (style_id);
+
+-- Variables: Figma variable collections with their modes, variables with a value per mode (a literal or an alias
+-- to another variable, resolved down to a literal), and the components and instances bound to them
+CREATE TABLE IF NOT EXISTS variable_collections (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    figma_collection_id VARCHAR(255) NOT NULL,
+    collection_key VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(500) NOT NULL,
+    modes JSONB NOT NULL DEFAULT '[]', -- [{"mode_id": "1:0", "name": "Light"}, ...]
+    default_mode_id VARCHAR(100) NOT NULL DEFAULT '',
+    is_external BOOLEAN NOT NULL DEFAULT FALSE,
+    is_published BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_variable_collections_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT unique_variable_collection_per_file UNIQUE (figma_file_id, figma_collection_id)
+);
+
+CREATE TABLE IF NOT EXISTS variables (
+    id SERIAL PRIMARY KEY,
+    figma_file_id INTEGER NOT NULL,
+    variable_collection_id INTEGER NOT NULL REFERENCES variable_collections (id) ON DELETE CASCADE,
+    figma_variable_id VARCHAR(255) NOT NULL,
+    variable_key VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(500) NOT NULL,
+    resolved_type VARCHAR(20) NOT NULL, -- COLOR, FLOAT, STRING or BOOLEAN
+    description TEXT NOT NULL DEFAULT '',
+    scopes JSONB,
+    is_external BOOLEAN NOT NULL DEFAULT FALSE,
+    is_published BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT fk_variables_figma_file FOREIGN KEY (figma_file_id) REFERENCES figma_files (id) ON DELETE CASCADE,
+    CONSTRAINT unique_variable_per_file UNIQUE (figma_file_id, figma_variable_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_variables_name ON variables (figma_file_id, name);
+
+CREATE TABLE IF NOT EXISTS variable_values (
+    id SERIAL PRIMARY KEY,
+    variable_id INTEGER NOT NULL REFERENCES variables (id) ON DELETE CASCADE,
+    mode_id VARCHAR(100) NOT NULL,
+    mode_name VARCHAR(255) NOT NULL DEFAULT '',
+    value JSONB, -- literal value, colors as hex strings; NULL for aliases
+    alias_of VARCHAR(255) NOT NULL DEFAULT '', -- Figma ID of the aliased variable
+    alias_name VARCHAR(500) NOT NULL DEFAULT '',
+    resolved_value JSONB, -- NULL when the alias can't be resolved
+    resolution_error TEXT NOT NULL DEFAULT '', -- e.g. alias cycle: a -> b -> a
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT unique_variable_value_per_mode UNIQUE (variable_id, mode_id)
+);
+
+CREATE TABLE IF NOT EXISTS variable_bindings (
+    id SERIAL PRIMARY KEY,
+    variable_id INTEGER NOT NULL REFERENCES variables (id) ON DELETE CASCADE,
+    component_id INTEGER REFERENCES components (id) ON DELETE CASCADE,
+    instance_id INTEGER REFERENCES instances (id) ON DELETE CASCADE,
+    node_id VARCHAR(100) NOT NULL, -- node the variable is bound to
+    property VARCHAR(255) NOT NULL, -- bound field, e.g. fills or itemSpacing
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    active BOOLEAN DEFAULT TRUE NOT NULL,
+    CONSTRAINT variable_binding_owner CHECK (component_id IS NOT NULL OR instance_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_variable_bindings_variable_id ON variable_bindings (variable_id);
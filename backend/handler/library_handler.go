@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"parser-service/internal/errors"
+	"parser-service/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LibraryHandler struct {
+	LibraryService *services.LibraryService
+}
+
+func NewLibraryHandler(libraryService *services.LibraryService) *LibraryHandler {
+	return &LibraryHandler{LibraryService: libraryService}
+}
+
+// SyncLibrary fetches the components, component sets and styles a Figma team published and stores them,
+// linking the external components of parsed files to them
+func (h *LibraryHandler) SyncLibrary(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request struct {
+		TeamID string `json:"team_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondWithError(c, errors.InvalidInput("Please provide the team_id of the Figma team library"), "Invalid request body")
+		return
+	}
+
+	result, err := h.LibraryService.SyncTeamLibrary(ctx, strings.TrimSpace(request.TeamID))
+	if err != nil {
+		respondWithError(c, err, "Failed to sync team library")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// GetLibrary returns the synced library of a team
+func (h *LibraryHandler) GetLibrary(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	library, err := h.LibraryService.GetTeamLibrary(ctx, c.Param("team_id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to get team library")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": library})
+}
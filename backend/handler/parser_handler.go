@@ -1,50 +1,130 @@
 package handler
 
 import (
+	"io"
+	"log"
 	"net/http"
+	"parser-service/internal/codegen"
 	"parser-service/internal/errors"
+	"parser-service/internal/token_exporter"
+	"parser-service/repositories"
 	"parser-service/services"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sseKeepAliveInterval is how often an idle progress stream sends a keep-alive comment
+const sseKeepAliveInterval = 15 * time.Second
+
+// defaultMaxFigmaJSONSize caps uploaded Figma file exports. The largest team files export to a couple hundred MB,
+// which are decoded as they are read rather than buffered first
+const defaultMaxFigmaJSONSize = 200 << 20
+
 type ParserHandler struct {
-	ParserService services.ParserService
+	ParserService    services.ParserService
+	ParseJobsService *services.ParseJobsService
+	maxFigmaJSONSize int64 // upload limit of ParseAndSaveFigmaJSON in bytes
 }
 
-func NewParserHandler(parserService services.ParserService) *ParserHandler {
+func NewParserHandler(parserService services.ParserService, parseJobsService *services.ParseJobsService) *ParserHandler {
 	return &ParserHandler{
-		ParserService: parserService,
+		ParserService:    parserService,
+		ParseJobsService: parseJobsService,
+		maxFigmaJSONSize: defaultMaxFigmaJSONSize,
 	}
 }
 
+// WithMaxFigmaJSONSize overrides the upload limit of ParseAndSaveFigmaJSON, in bytes
+func (h *ParserHandler) WithMaxFigmaJSONSize(maxFigmaJSONSize int64) *ParserHandler {
+	h.maxFigmaJSONSize = maxFigmaJSONSize
+	return h
+}
+
 func (h *ParserHandler) ParseAndSaveFigmaFile(c *gin.Context) {
 	ctx := c.Request.Context()
 	var request struct {
 		FigmaURL string `json:"figma_file_url" binding:"required"`
+		Async    bool   `json:"async"` // queue a parse job instead of parsing within the request
 	}
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, errors.ErrorResponse{
-			Err:     "Invalid request body",
-			Status:  http.StatusBadRequest,
-			Message: "Please provide a valid Figma file URL",
-		})
+		respondWithError(c, errors.InvalidInput("Please provide a valid Figma file URL"), "Invalid request body")
+		return
+	}
+
+	if request.Async || c.Query("async") == "true" {
+		job, err := h.ParseJobsService.EnqueueParseJob(ctx, request.FigmaURL)
+		if err != nil {
+			respondWithError(c, err, "Failed to queue parse job")
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": job})
 		return
 	}
 
 	savedFile, err := h.ParserService.ParseAndSaveFigmaFile(ctx, request.FigmaURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ErrorResponse{
-			Err:     "Failed to parse Figma file",
-			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
-		})
+		respondWithError(c, err, "Failed to parse Figma file")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": savedFile})
 }
 
+// ParseAndSaveFigmaJSON parses a saved GET /v1/files response body, sent either as the raw
+// request body or as the "file" field of a multipart form. figma_file_url is optional
+// (query parameter or form field) and only used to record the file key and URL.
+func (h *ParserHandler) ParseAndSaveFigmaJSON(c *gin.Context) {
+	ctx := c.Request.Context()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxFigmaJSONSize)
+
+	fileJSON, figmaURL, err := openFigmaJSON(c)
+	if err != nil {
+		respondWithUploadError(c, err)
+		return
+	}
+	defer fileJSON.Close()
+
+	savedFile, err := h.ParserService.ParseAndSaveFigmaJSON(ctx, fileJSON, figmaURL)
+	if err != nil {
+		respondWithUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": savedFile})
+}
+
+// openFigmaJSON opens the uploaded file JSON, to be decoded as it is read, and reads the optional Figma URL from a
+// multipart or raw request
+func openFigmaJSON(c *gin.Context) (io.ReadCloser, string, error) {
+	if c.ContentType() == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		return file, c.PostForm("figma_file_url"), nil
+	}
+
+	return c.Request.Body, c.Query("figma_file_url"), nil
+}
+
+// respondWithUploadError answers 413 with the limit when the upload is over it, and the error status otherwise
+func respondWithUploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		respondWithError(c, errors.TooLarge("Figma file JSON exports are limited to %d bytes", maxBytesErr.Limit), "Request body too large")
+	case errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart):
+		respondWithError(c, errors.InvalidInput("Please provide a Figma file JSON export as the request body or as the 'file' form field"), "Invalid request body")
+	default:
+		respondWithError(c, err, "Failed to parse Figma file JSON")
+	}
+}
+
 func (h *ParserHandler) GetFigmaFileDetails(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -52,23 +132,448 @@ func (h *ParserHandler) GetFigmaFileDetails(c *gin.Context) {
 	fileIDStr := c.Param("id")
 	fileID, err := strconv.ParseInt(fileIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, errors.ErrorResponse{
-			Err:     "Invalid file ID",
-			Status:  http.StatusBadRequest,
-			Message: "File ID must be a valid number",
-		})
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
 		return
 	}
 
-	fileDetails, err := h.ParserService.GetFigmaFileWithDetails(ctx, fileID)
+	pageID, err := pageIDQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ErrorResponse{
-			Err:     "Failed to get file details",
-			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
-		})
+		respondWithError(c, err, "Invalid page ID")
+		return
+	}
+
+	fileDetails, err := h.ParserService.GetFigmaFileWithDetails(ctx, fileID, pageID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get file details")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": fileDetails})
 }
+
+// GetFigmaFilePages returns the pages of a file with their content bounds
+func (h *ParserHandler) GetFigmaFilePages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	pages, err := h.ParserService.GetFigmaFilePages(ctx, fileID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get pages")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pages})
+}
+
+// GetFigmaFileComponents returns the components of a file. ?page_id= limits them to a page, ?component_set_id= to
+// the variants of a set, ?variant=Size=Large (repeatable) to variants having these properties, ?style= to
+// components using a style and ?variable= to components bound to a variable, both by name
+func (h *ParserHandler) GetFigmaFileComponents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	pageID, err := pageIDQuery(c)
+	if err != nil {
+		respondWithError(c, err, "Invalid page ID")
+		return
+	}
+
+	filter := repositories.ComponentFilter{PageID: pageID}
+	if componentSetIDStr := c.Query("component_set_id"); componentSetIDStr != "" {
+		filter.ComponentSetID, err = strconv.ParseInt(componentSetIDStr, 10, 64)
+		if err != nil || filter.ComponentSetID <= 0 {
+			respondWithError(c, errors.InvalidInput("component_set_id must be a valid component ID"), "Invalid component set ID")
+			return
+		}
+	}
+	for _, variant := range c.QueryArray("variant") {
+		key, value, ok := strings.Cut(variant, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			respondWithError(c, errors.InvalidInput("variant must be a key=value pair, got %q", variant), "Invalid variant filter")
+			return
+		}
+		if filter.Variant == nil {
+			filter.Variant = make(map[string]string)
+		}
+		filter.Variant[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	filter.Style = strings.TrimSpace(c.Query("style"))
+	filter.Variable = strings.TrimSpace(c.Query("variable"))
+
+	components, err := h.ParserService.GetFigmaFileComponents(ctx, fileID, filter)
+	if err != nil {
+		respondWithError(c, err, "Failed to get components")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": components})
+}
+
+// GetFigmaFileInstances returns the instances of a file. ?page_id= limits them to a page, ?style= and ?variable= to
+// instances using a style or bound to a variable, by name, and ?min_overrides= to instances overriding at least that
+// many fields, most overridden first
+func (h *ParserHandler) GetFigmaFileInstances(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	pageID, err := pageIDQuery(c)
+	if err != nil {
+		respondWithError(c, err, "Invalid page ID")
+		return
+	}
+
+	filter := repositories.InstanceFilter{
+		PageID:   pageID,
+		Style:    strings.TrimSpace(c.Query("style")),
+		Variable: strings.TrimSpace(c.Query("variable")),
+	}
+	if minOverridesStr := c.Query("min_overrides"); minOverridesStr != "" {
+		filter.MinOverrides, err = strconv.Atoi(minOverridesStr)
+		if err != nil || filter.MinOverrides < 0 {
+			respondWithError(c, errors.InvalidInput("min_overrides must be a non negative number"), "Invalid min_overrides")
+			return
+		}
+	}
+
+	instances, err := h.ParserService.GetFigmaFileInstances(ctx, fileID, filter)
+	if err != nil {
+		respondWithError(c, err, "Failed to get instances")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": instances})
+}
+
+// GetFigmaFileTextNodes returns the copy and typography of the file text nodes, ?page_id= limits them to a page
+func (h *ParserHandler) GetFigmaFileTextNodes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	pageID, err := pageIDQuery(c)
+	if err != nil {
+		respondWithError(c, err, "Invalid page ID")
+		return
+	}
+
+	textNodes, err := h.ParserService.GetFigmaFileTextNodes(ctx, fileID, pageID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get text nodes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": textNodes})
+}
+
+// GetFigmaFileStyles returns the shared styles of a file with their values and usage counts, ?type= limits them
+// to FILL, TEXT, EFFECT or GRID styles
+func (h *ParserHandler) GetFigmaFileStyles(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	styleType := strings.ToUpper(c.Query("type"))
+	switch styleType {
+	case "", "FILL", "TEXT", "EFFECT", "GRID":
+	default:
+		respondWithError(c, errors.InvalidInput("type must be one of FILL, TEXT, EFFECT or GRID"), "Invalid style type")
+		return
+	}
+
+	styles, err := h.ParserService.GetFigmaFileStyles(ctx, fileID, styleType)
+	if err != nil {
+		respondWithError(c, err, "Failed to get styles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": styles})
+}
+
+// GetFigmaFileVariables returns the variable collections of a file with their modes and variables, each variable
+// with its value in every mode and how many components and instances are bound to it
+func (h *ParserHandler) GetFigmaFileVariables(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	collections, err := h.ParserService.GetFigmaFileVariables(ctx, fileID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get variables")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": collections})
+}
+
+// GetComponentCode generates the code of a component, ?target= is react (default). The code is sent as a file
+// named after the component
+func (h *ParserHandler) GetComponentCode(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	componentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("Component ID must be a valid number"), "Invalid component ID")
+		return
+	}
+
+	target, err := codegen.ParseTarget(c.DefaultQuery("target", string(codegen.TargetReact)))
+	if err != nil {
+		respondWithError(c, err, "Invalid codegen target")
+		return
+	}
+
+	file, err := h.ParserService.GenerateComponentCode(ctx, componentID, target)
+	if err != nil {
+		respondWithError(c, err, "Failed to generate component code")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+file.Name+`"`)
+	c.Data(http.StatusOK, file.ContentType, file.Code)
+}
+
+// GetFigmaFileTokens exports the design tokens of a file, ?format= is one of dtcg (default), style-dictionary, css,
+// scss or ts
+func (h *ParserHandler) GetFigmaFileTokens(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	format, err := token_exporter.ParseFormat(c.DefaultQuery("format", string(token_exporter.FormatDTCG)))
+	if err != nil {
+		respondWithError(c, err, "Invalid token format")
+		return
+	}
+
+	tokens, err := h.ParserService.ExportFigmaFileTokens(ctx, fileID, format)
+	if err != nil {
+		respondWithError(c, err, "Failed to export tokens")
+		return
+	}
+
+	c.Data(http.StatusOK, format.ContentType(), tokens)
+}
+
+// ExportFigmaFileHTML exports a page or frame of a file as a zipped static site, ?page= is a page ID or the
+// Figma node ID of a frame
+func (h *ParserHandler) ExportFigmaFileHTML(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	page := c.Query("page")
+	if page == "" {
+		respondWithError(c, errors.InvalidInput("page must be a page ID or a frame node ID"), "Missing page")
+		return
+	}
+
+	export, err := h.ParserService.ExportFigmaFileHTML(ctx, fileID, page)
+	if err != nil {
+		respondWithError(c, err, "Failed to export HTML")
+		return
+	}
+
+	// the archive is streamed as it is written, a failure past this point can only cut it short
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	if err := export.WriteZip(ctx, c.Writer); err != nil {
+		log.Printf("Failed to write the HTML export of figma file %d: %v", fileID, err)
+	}
+}
+
+// ExportFigmaNodeSVG draws a node of a file as SVG, ?node= is its Figma node ID
+func (h *ParserHandler) ExportFigmaNodeSVG(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	nodeID := c.Query("node")
+	if nodeID == "" {
+		respondWithError(c, errors.InvalidInput("node must be a Figma node ID"), "Missing node")
+		return
+	}
+
+	file, err := h.ParserService.ExportFigmaNodeSVG(ctx, fileID, nodeID)
+	if err != nil {
+		respondWithError(c, err, "Failed to export SVG")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+file.Name+`"`)
+	c.Data(http.StatusOK, file.ContentType, file.Code)
+}
+
+// GetFigmaFileComponentGraph returns which components of a file use which other components, as nodes and edges
+func (h *ParserHandler) GetFigmaFileComponentGraph(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	graph, err := h.ParserService.GetFigmaFileComponentGraph(ctx, fileID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get component graph")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": graph})
+}
+
+// pageIDQuery reads the optional ?page_id= filter, 0 when absent
+func pageIDQuery(c *gin.Context) (int64, error) {
+	pageIDStr := c.Query("page_id")
+	if pageIDStr == "" {
+		return 0, nil
+	}
+	pageID, err := strconv.ParseInt(pageIDStr, 10, 64)
+	if err != nil || pageID <= 0 {
+		return 0, errors.InvalidInput("page_id must be a valid page ID")
+	}
+	return pageID, nil
+}
+
+// GetFigmaFileTree returns the node hierarchy of a file. ?root= picks a subtree by Figma node ID
+// and ?depth= limits how many levels below it are returned
+func (h *ParserHandler) GetFigmaFileTree(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("File ID must be a valid number"), "Invalid file ID")
+		return
+	}
+
+	maxDepth := -1
+	if depthStr := c.Query("depth"); depthStr != "" {
+		maxDepth, err = strconv.Atoi(depthStr)
+		if err != nil || maxDepth < 0 {
+			respondWithError(c, errors.InvalidInput("depth must be a non-negative number"), "Invalid depth")
+			return
+		}
+	}
+
+	tree, err := h.ParserService.GetFigmaFileTree(ctx, fileID, c.Query("root"), maxDepth)
+	if err != nil {
+		respondWithError(c, err, "Failed to get file tree")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+// GetParseJob reports the status of an asynchronous parse job, including the figma_file id once it succeeded
+func (h *ParserHandler) GetParseJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("Job ID must be a valid number"), "Invalid job ID")
+		return
+	}
+
+	job, err := h.ParseJobsService.GetParseJob(ctx, jobID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get parse job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// GetParseJobEvents streams the progress of a parse job as Server-Sent Events, ending with a done or error event
+func (h *ParserHandler) GetParseJobEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, errors.InvalidInput("Job ID must be a valid number"), "Invalid job ID")
+		return
+	}
+
+	history, events, unsubscribe, err := h.ParseJobsService.SubscribeParseJobProgress(ctx, jobID)
+	if err != nil {
+		respondWithError(c, err, "Failed to get parse job")
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering so events arrive as they happen
+
+	// Replay what happened before the client connected
+	for _, event := range history {
+		c.SSEvent(string(event.Stage), event)
+		if event.IsFinal() {
+			c.Writer.Flush()
+			return
+		}
+	}
+	c.Writer.Flush()
+	if events == nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Stage), event)
+			return !event.IsFinal()
+		case <-keepAlive.C:
+			// SSE comment line, keeps idle connections from being closed by proxies
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"parser-service/handler"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/services"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseAndSaveFigmaJSON_TooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parserService := services.ParserService{FigmaManager: figma_manager.NewFigmaManager()}
+	h := handler.NewParserHandler(parserService, nil).WithMaxFigmaJSONSize(1024)
+	router := gin.New()
+	router.POST("/parse-figma-json", h.ParseAndSaveFigmaJSON)
+
+	fileJSON := `{"name": "` + strings.Repeat("x", 2048) + `"}`
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "export.json")
+	part.Write([]byte(fileJSON))
+	writer.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{name: "Raw body", contentType: "application/json", body: []byte(fileJSON)},
+		{name: "Multipart form", contentType: writer.FormDataContentType(), body: form.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/parse-figma-json", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("Expected status 413, got %d: %s", w.Code, w.Body.String())
+			}
+			var response errors.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Expected an error response, got %q", w.Body.String())
+			}
+			if response.Code != "payload_too_large" || !strings.Contains(response.Message, "1024 bytes") {
+				t.Errorf("Expected the error to state the 1024 bytes limit, got %+v", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"parser-service/internal/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithError answers with the status and error code matching the error kind (400/401/403/404/413/429/502, 500 otherwise).
+// Rate limited responses also tell the caller when to retry.
+func respondWithError(c *gin.Context, err error, title string) {
+	if retryAfter := errors.RetryAfterHeader(err); retryAfter != "" {
+		c.Header("Retry-After", retryAfter)
+	}
+	response := errors.NewErrorResponse(err, title)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,282 @@
+// Package codegen turns parsed components into UI code. A component is generated from its stored node tree: its
+// property definitions become props, Auto Layout becomes flexbox, fills, strokes, effects and typography become
+// styles, and the instances it holds become imports of the code generated for their own component. Pages and
+// frames are exported the same way as a static HTML page and its stylesheet, and shapes are drawn as SVG from their
+// vector geometry.
+package codegen
+
+import (
+	"encoding/json"
+	"parser-service/internal/errors"
+	"parser-service/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Target is a language or framework components are generated for
+type Target string
+
+const (
+	TargetReact Target = "react" // TSX function component with inline styles
+)
+
+// Targets lists every supported target
+var Targets = []Target{TargetReact}
+
+// ParseTarget validates a target name
+func ParseTarget(name string) (Target, error) {
+	for _, target := range Targets {
+		if string(target) == strings.ToLower(name) {
+			return target, nil
+		}
+	}
+	names := make([]string, len(Targets))
+	for i, target := range Targets {
+		names[i] = string(target)
+	}
+	return "", errors.InvalidInput("target must be one of %s, got %q", strings.Join(names, ", "), name)
+}
+
+// Source is what a component is generated from, as stored for its file
+type Source struct {
+	Component  models.Component   // component to generate, the component set for variants
+	Nodes      []models.Node      // node tree of Component, with its variants for a set
+	Components []models.Component // components of the file, naming the components instances import
+	Instances  []models.Instance  // instances of the file, with the property values they set
+	TextNodes  []models.TextNode  // text nodes of the file, with their copy and typography
+}
+
+// File is a generated source file
+type File struct {
+	Name          string // e.g. Button.tsx
+	ComponentName string // exported component, e.g. Button, empty for drawings
+	ContentType   string // MIME type of the code
+	Code          []byte
+}
+
+// Generate writes the code of source.Component for target. The output only depends on the source, so it can be
+// committed and diffed between parses
+func Generate(source Source, target Target) (*File, error) {
+	if source.Component.Type != "COMPONENT" && source.Component.Type != "COMPONENT_SET" {
+		return nil, errors.InvalidInput("%s is a %s, only components and component sets can be generated", source.Component.Name, source.Component.Type)
+	}
+	root := buildTree(source.Nodes, source.Component.NodeID)
+	if root == nil {
+		return nil, errors.NotFound("component %s has no node tree, parse the file again to capture it", source.Component.NodeID)
+	}
+
+	switch target {
+	case TargetReact:
+		return generateReact(newGenerator(source, root))
+	default:
+		return nil, errors.InvalidInput("unsupported codegen target %q", target)
+	}
+}
+
+// node is a node of the tree with its decoded properties
+type node struct {
+	models.Node
+	properties models.NodeProperties
+	children   []*node
+}
+
+// hidden tells whether the node is hidden in the design, before any property changes it
+func (n *node) hidden() bool {
+	return !n.Visible || (n.properties.Visible != nil && !*n.properties.Visible)
+}
+
+// buildTree links nodes to their parent by Figma node ID, children sorted in their layer order, and returns
+// the node rootNodeID. Nil when it isn't part of nodes
+func buildTree(nodes []models.Node, rootNodeID string) *node {
+	byNodeID := make(map[string]*node, len(nodes))
+	for _, model := range nodes {
+		n := &node{Node: model}
+		_ = json.Unmarshal(model.Properties, &n.properties)
+		byNodeID[model.NodeID] = n
+	}
+	for _, model := range nodes {
+		if parent, exists := byNodeID[model.ParentNodeID]; exists && model.NodeID != rootNodeID {
+			parent.children = append(parent.children, byNodeID[model.NodeID])
+		}
+	}
+	for _, n := range byNodeID {
+		sort.SliceStable(n.children, func(i, j int) bool { return n.children[i].SortOrder < n.children[j].SortOrder })
+	}
+	return byNodeID[rootNodeID]
+}
+
+// generator holds the lookups shared by targets
+type generator struct {
+	source     Source
+	root       *node
+	name       string // name of the generated component
+	props      []prop
+	components map[string]models.Component // by Figma node ID
+	byID       map[int64]models.Component
+	instances  map[string]models.Instance // by Figma node ID
+	textNodes  map[string]models.TextNode // by Figma node ID
+}
+
+func newGenerator(source Source, root *node) *generator {
+	g := &generator{
+		source:     source,
+		root:       root,
+		name:       componentName(source.Component.Name),
+		props:      componentProps(source.Component.PropertyDefinitions),
+		components: make(map[string]models.Component, len(source.Components)),
+		byID:       make(map[int64]models.Component, len(source.Components)),
+		instances:  make(map[string]models.Instance, len(source.Instances)),
+		textNodes:  make(map[string]models.TextNode, len(source.TextNodes)),
+	}
+	for _, component := range source.Components {
+		g.components[component.NodeID] = component
+		if component.ID != 0 {
+			g.byID[component.ID] = component
+		}
+	}
+	for _, instance := range source.Instances {
+		g.instances[instance.NodeID] = instance
+	}
+	for _, textNode := range source.TextNodes {
+		g.textNodes[textNode.NodeID] = textNode
+	}
+	return g
+}
+
+// instanceComponent returns the main component of an instance node
+func (g *generator) instanceComponent(n *node) (models.Instance, models.Component, bool) {
+	instance, exists := g.instances[n.NodeID]
+	if !exists {
+		return instance, models.Component{}, false
+	}
+	var properties models.NodeProperties
+	_ = json.Unmarshal(instance.Properties, &properties)
+	component, exists := g.components[properties.FigmaComponentID]
+	return instance, component, exists
+}
+
+// publicComponent returns the component code refers to: the set of a variant, the component itself otherwise
+func (g *generator) publicComponent(component models.Component) models.Component {
+	if set, exists := g.components[component.ComponentSetNodeID]; exists && component.ComponentSetNodeID != "" {
+		return set
+	}
+	if component.ComponentSetID != nil {
+		if set, exists := g.byID[*component.ComponentSetID]; exists {
+			return set
+		}
+	}
+	return component
+}
+
+// prop is a component property exposed as a prop
+type prop struct {
+	Definition string // Figma property name, e.g. "Label#2:0"
+	Name       string // identifier, e.g. label
+	models.ComponentPropertyDefinition
+}
+
+// componentProps returns the props of a component, sorted by name
+func componentProps(definitions json.RawMessage) []prop {
+	var decoded map[string]models.ComponentPropertyDefinition
+	if len(definitions) == 0 || json.Unmarshal(definitions, &decoded) != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(decoded))
+	for name := range decoded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	props := make([]prop, 0, len(names))
+	taken := map[string]bool{"style": true}
+	for _, name := range names {
+		identifier := propName(name)
+		for i := 2; taken[identifier]; i++ {
+			identifier = propName(name) + strconv.Itoa(i)
+		}
+		taken[identifier] = true
+		props = append(props, prop{Definition: name, Name: identifier, ComponentPropertyDefinition: decoded[name]})
+	}
+	sort.SliceStable(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+	return props
+}
+
+// propByDefinition finds a prop by its Figma property name
+func propByDefinition(props []prop, definition string) (prop, bool) {
+	for _, p := range props {
+		if p.Definition == definition {
+			return p, true
+		}
+	}
+	return prop{}, false
+}
+
+// propertySuffix is the ID Figma appends to the names of non variant properties, e.g. "#2:0"
+var propertySuffix = regexp.MustCompile(`#\d+:\d+$`)
+
+// reservedWords can't be used as prop names
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+}
+
+// propName turns a property name into a camelCase identifier, "Show icon#2:5" is showIcon
+func propName(definition string) string {
+	words := splitWords(propertySuffix.ReplaceAllString(definition, ""))
+	for i, word := range words {
+		if i == 0 {
+			words[i] = lowerFirst(word)
+		} else {
+			words[i] = upperFirst(word)
+		}
+	}
+	name := strings.Join(words, "")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "prop" + upperFirst(name)
+	}
+	if reservedWords[name] {
+		name += "Prop"
+	}
+	return name
+}
+
+// componentName turns a component name into a PascalCase identifier, "Primary button" is PrimaryButton
+func componentName(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		words[i] = upperFirst(word)
+	}
+	identifier := strings.Join(words, "")
+	if identifier == "" || unicode.IsDigit(rune(identifier[0])) {
+		identifier = "Component" + identifier
+	}
+	return identifier
+}
+
+var nonWordCharacters = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func splitWords(name string) []string {
+	return strings.Fields(nonWordCharacters.ReplaceAllString(name, " "))
+}
+
+// lowerFirst lowercases the first letter of a word, or the whole word when it is an acronym such as URL
+func lowerFirst(word string) string {
+	if strings.ToUpper(word) == word {
+		return strings.ToLower(word)
+	}
+	return strings.ToLower(word[:1]) + word[1:]
+}
+
+func upperFirst(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
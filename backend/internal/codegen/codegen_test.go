@@ -0,0 +1,377 @@
+package codegen_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"parser-service/internal/codegen"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files: go test ./internal/codegen -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func parseSampleFile(t *testing.T) *figma_manager.ParsedFigmaData {
+	t.Helper()
+	var response figma_manager.FigmaAPIResponse
+	if err := json.Unmarshal(figmatest.SampleFile(), &response); err != nil {
+		t.Fatalf("Failed to decode sample file: %v", err)
+	}
+	parsedData, err := figma_manager.NewFigmaParser().ParseFile(&response, figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+	return parsedData
+}
+
+// sampleSource builds the source of a component of the sample file, as the service reads it from the database
+func sampleSource(t *testing.T, parsedData *figma_manager.ParsedFigmaData, nodeID string) codegen.Source {
+	t.Helper()
+	source := codegen.Source{
+		Components: parsedData.Components,
+		Instances:  parsedData.Instances,
+		TextNodes:  parsedData.TextNodes,
+	}
+	for _, component := range parsedData.Components {
+		if component.NodeID == nodeID {
+			source.Component = component
+		}
+	}
+	if source.Component.NodeID == "" {
+		t.Fatalf("Component %s not found in the sample file", nodeID)
+	}
+
+	inTree := map[string]bool{nodeID: true}
+	for _, node := range parsedData.Nodes {
+		if node.NodeID == nodeID || inTree[node.ParentNodeID] {
+			inTree[node.NodeID] = true
+			source.Nodes = append(source.Nodes, node)
+		}
+	}
+	return source
+}
+
+func TestGenerate_React(t *testing.T) {
+	parsedData := parseSampleFile(t)
+
+	for _, test := range []struct {
+		nodeID string
+		golden string
+	}{
+		{"2:1", "Button.tsx"}, // component set, one branch per variant
+		{"3:1", "Card.tsx"},   // component holding an instance of a variant
+	} {
+		t.Run(test.golden, func(t *testing.T) {
+			file, err := codegen.Generate(sampleSource(t, parsedData, test.nodeID), codegen.TargetReact)
+			if err != nil {
+				t.Fatalf("Failed to generate %s: %v", test.nodeID, err)
+			}
+			if file.Name != test.golden {
+				t.Errorf("Expected file name %s, got %s", test.golden, file.Name)
+			}
+
+			golden := filepath.Join("testdata", test.golden+".golden")
+			if *update {
+				if err := os.WriteFile(golden, file.Code, 0o644); err != nil {
+					t.Fatalf("Failed to update %s: %v", golden, err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read %s, run the tests with -update to create it: %v", golden, err)
+			}
+			if string(file.Code) != string(expected) {
+				t.Errorf("Generated code differs from %s:\n%s", golden, file.Code)
+			}
+
+			again, _ := codegen.Generate(sampleSource(t, parsedData, test.nodeID), codegen.TargetReact)
+			if string(again.Code) != string(file.Code) {
+				t.Error("Expected the generated code to be deterministic")
+			}
+		})
+	}
+}
+
+func TestGenerate_VariantSetFromDatabase(t *testing.T) {
+	source := sampleSource(t, parseSampleFile(t), "3:1")
+
+	// components read back from the database only know their set by row ID
+	ids := make(map[string]int64, len(source.Components))
+	for i := range source.Components {
+		ids[source.Components[i].NodeID] = int64(i + 1)
+		source.Components[i].ID = int64(i + 1)
+	}
+	for i, component := range source.Components {
+		if component.ComponentSetNodeID != "" {
+			setID := ids[component.ComponentSetNodeID]
+			source.Components[i].ComponentSetID = &setID
+			source.Components[i].ComponentSetNodeID = ""
+		}
+	}
+
+	file, err := codegen.Generate(source, codegen.TargetReact)
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	code := string(file.Code)
+	for _, expected := range []string{`import { Button } from "./Button";`, `<Button size="Small"`} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the variant to be used through its set, %s missing:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerate_BoundProperties(t *testing.T) {
+	definitions := json.RawMessage(`{
+		"Show icon#1:1": {"type": "BOOLEAN", "defaultValue": false},
+		"Icon#1:2": {"type": "INSTANCE_SWAP", "defaultValue": "9:1"},
+		"class": {"type": "TEXT", "defaultValue": "a \"quoted\" {label}"}
+	}`)
+	properties := func(t *testing.T, properties models.NodeProperties) json.RawMessage {
+		t.Helper()
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			t.Fatalf("Failed to encode properties: %v", err)
+		}
+		return encoded
+	}
+	hidden := false
+	source := codegen.Source{
+		Component: models.Component{NodeID: "1:0", Name: "icon button", Type: "COMPONENT", PropertyDefinitions: definitions},
+		Components: []models.Component{
+			{NodeID: "1:0", Name: "icon button", Type: "COMPONENT"},
+			{NodeID: "9:1", Name: "Star", Type: "COMPONENT"},
+		},
+		Nodes: []models.Node{
+			{NodeID: "1:0", Type: "COMPONENT", Visible: true, Width: 40, Height: 40},
+			{NodeID: "1:3", ParentNodeID: "1:0", Type: "INSTANCE", Visible: false, X: 8, Y: 8, Width: 24, Height: 24,
+				Properties: properties(t, models.NodeProperties{Visible: &hidden, PropertyReferences: map[string]string{"visible": "Show icon#1:1", "mainComponent": "Icon#1:2"}})},
+			{NodeID: "1:4", ParentNodeID: "1:0", Type: "RECTANGLE", Visible: false, SortOrder: 1},
+		},
+	}
+
+	file, err := codegen.Generate(source, codegen.TargetReact)
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	code := string(file.Code)
+	for _, expected := range []string{
+		`import { Star } from "./Star";`,
+		`classProp = "a \"quoted\" {label}"`,
+		`icon = <Star />`,
+		`showIcon = false`,
+		"{showIcon && icon}",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the code to contain %s:\n%s", expected, code)
+		}
+	}
+	if file.ComponentName != "IconButton" || strings.Contains(code, "1:4") {
+		t.Errorf("Expected an IconButton component without the hidden rectangle:\n%s", code)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	if _, err := codegen.ParseTarget("vue"); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Expected an invalid input error for an unknown target, got %v", err)
+	}
+
+	_, err := codegen.Generate(codegen.Source{Component: models.Component{NodeID: "9:9", Name: "Avatar", Type: "COMPONENT"}}, codegen.TargetReact)
+	if !errors.Is(err, errors.ErrNotFound) {
+		t.Errorf("Expected a not found error without a node tree, got %v", err)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	parsedData := parseSampleFile(t)
+
+	site, err := codegen.ExportHTML(codegen.PageSource{
+		Name:       "Home",
+		RootNodeID: "0:1",
+		Nodes:      parsedData.Nodes,
+		TextNodes:  parsedData.TextNodes,
+		Background: "#F5F5F5",
+	})
+	if err != nil {
+		t.Fatalf("Failed to export the Home page: %v", err)
+	}
+	if site.Name != "home" {
+		t.Errorf("Expected site name home, got %s", site.Name)
+	}
+
+	for _, output := range []struct {
+		golden string
+		code   []byte
+	}{
+		{"home.html", site.HTML},
+		{"home.css", site.CSS},
+	} {
+		golden := filepath.Join("testdata", output.golden+".golden")
+		if *update {
+			if err := os.WriteFile(golden, output.code, 0o644); err != nil {
+				t.Fatalf("Failed to update %s: %v", golden, err)
+			}
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Failed to read %s, run the tests with -update to create it: %v", golden, err)
+		}
+		if string(output.code) != string(expected) {
+			t.Errorf("Exported %s differs from %s:\n%s", output.golden, golden, output.code)
+		}
+	}
+
+	if _, err := codegen.ExportHTML(codegen.PageSource{Name: "Home", RootNodeID: "0:9", Nodes: parsedData.Nodes}); !errors.Is(err, errors.ErrNotFound) {
+		t.Errorf("Expected a not found error for an unknown node, got %v", err)
+	}
+}
+
+func TestExportHTML_ImageFills(t *testing.T) {
+	properties := func(t *testing.T, properties models.NodeProperties) json.RawMessage {
+		t.Helper()
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			t.Fatalf("Failed to encode properties: %v", err)
+		}
+		return encoded
+	}
+	image := func(ref, scaleMode string) []models.Paint {
+		return []models.Paint{{Type: "IMAGE", Visible: true, Opacity: 1, ImageRef: ref, ScaleMode: scaleMode}}
+	}
+	site, err := codegen.ExportHTML(codegen.PageSource{
+		Name:       "Hero <1>",
+		RootNodeID: "1:0",
+		Nodes: []models.Node{
+			{NodeID: "1:0", Name: "Hero", Type: "FRAME", Visible: true, Width: 800, Height: 400,
+				Properties: properties(t, models.NodeProperties{Fills: image("cover-ref", "FILL")})},
+			{NodeID: "1:1", ParentNodeID: "1:0", Name: "Logo", Type: "RECTANGLE", Visible: true, X: 16, Y: 16, Width: 64, Height: 64,
+				Properties: properties(t, models.NodeProperties{Fills: image("logo-ref", "FIT")})},
+			{NodeID: "1:2", ParentNodeID: "1:0", Name: "Hidden", Type: "RECTANGLE", Visible: false, SortOrder: 1,
+				Properties: properties(t, models.NodeProperties{Fills: image("hidden-ref", "FILL")})},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	if strings.Join(site.Assets, ",") != "cover-ref,logo-ref" {
+		t.Errorf("Expected the visible image fills as assets, got %v", site.Assets)
+	}
+	for _, expected := range []string{
+		"<title>Hero &lt;1&gt;</title>",
+		`<main class="hero">`,
+		`<img class="logo" src="assets/logo-ref" alt="Logo">`,
+	} {
+		if !strings.Contains(string(site.HTML), expected) {
+			t.Errorf("Expected the HTML to contain %s:\n%s", expected, site.HTML)
+		}
+	}
+	for _, expected := range []string{
+		`background: url("assets/cover-ref") center / cover no-repeat;`,
+		"object-fit: contain;",
+	} {
+		if !strings.Contains(string(site.CSS), expected) {
+			t.Errorf("Expected the stylesheet to contain %s:\n%s", expected, site.CSS)
+		}
+	}
+
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	if file := codegen.AssetFile("cover-ref", jpeg); file != "assets/cover-ref.jpg" {
+		t.Errorf("Expected the JPEG image to be named assets/cover-ref.jpg, got %s", file)
+	}
+	if file := codegen.AssetFile("logo-ref", []byte("not an image")); file != "assets/logo-ref" {
+		t.Errorf("Expected an unknown image type to keep assets/logo-ref, got %s", file)
+	}
+
+	htmlContent, css := site.LinkAssets(map[string]string{"logo-ref": "assets/logo-ref.gif"})
+	if !strings.Contains(string(htmlContent), `src="assets/logo-ref.gif"`) {
+		t.Errorf("Expected the HTML to refer to the downloaded image:\n%s", htmlContent)
+	}
+	if !strings.Contains(string(css), `url("assets/cover-ref")`) {
+		t.Errorf("Expected the stylesheet to keep the path of an image that wasn't downloaded:\n%s", css)
+	}
+}
+
+func TestExportSVG(t *testing.T) {
+	properties := func(t *testing.T, properties models.NodeProperties) json.RawMessage {
+		t.Helper()
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			t.Fatalf("Failed to encode properties: %v", err)
+		}
+		return encoded
+	}
+	solid := func(color string) []models.Paint {
+		return []models.Paint{{Type: "SOLID", Visible: true, Opacity: 1, Color: color}}
+	}
+	weight := 2.0
+	halfOpaque := 0.5
+	nodes := []models.Node{
+		{NodeID: "1:0", Name: "Icon / Check", Type: "COMPONENT", Visible: true, X: 100, Y: 50, Width: 24, Height: 24},
+		{NodeID: "1:1", ParentNodeID: "1:0", Type: "VECTOR", Visible: true, X: 104, Y: 56, Width: 16, Height: 12,
+			Properties: properties(t, models.NodeProperties{
+				Fills:          solid("#1E88E580"),
+				FillGeometry:   []models.Path{{Path: "M0 6L6 12L16 0", WindingRule: "EVENODD"}},
+				Strokes:        solid("#000000"),
+				StrokeGeometry: []models.Path{{Path: "M0 0L16 0", WindingRule: "NONZERO"}},
+				Transform:      &models.Transform{{0, -1, 104}, {1, 0, 68}},
+			})},
+		{NodeID: "1:2", ParentNodeID: "1:0", Type: "ELLIPSE", Visible: true, X: 100, Y: 50, Width: 24, Height: 24, SortOrder: 1,
+			Properties: properties(t, models.NodeProperties{Opacity: &halfOpaque, Strokes: solid("#FF0000"), StrokeWeight: &weight})},
+		{NodeID: "1:3", ParentNodeID: "1:0", Type: "VECTOR", Visible: true, SortOrder: 2,
+			Properties: properties(t, models.NodeProperties{Fills: solid("#000000")})},
+		{NodeID: "1:4", ParentNodeID: "1:0", Type: "TEXT", Visible: true, SortOrder: 3},
+	}
+
+	file, err := codegen.ExportSVG(nodes, "1:0")
+	if err != nil {
+		t.Fatalf("Failed to export SVG: %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24" fill="none">
+  <path d="M0 6L6 12L16 0" fill-rule="evenodd" transform="matrix(0 1 -1 0 4 18)" fill="#1E88E5" fill-opacity="0.5"/>
+  <path d="M0 0L16 0" transform="matrix(0 1 -1 0 4 18)" fill="#000000"/>
+  <g opacity="0.5">
+    <ellipse cx="12" cy="12" rx="12" ry="12" stroke="#FF0000" stroke-width="2"/>
+  </g>
+</svg>
+`
+	if string(file.Code) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, file.Code)
+	}
+	if file.Name != "icon-check.svg" || file.ContentType != "image/svg+xml" {
+		t.Errorf("Expected icon-check.svg as image/svg+xml, got %s as %s", file.Name, file.ContentType)
+	}
+
+	t.Run("gradient", func(t *testing.T) {
+		gradient := []models.Paint{{Type: "GRADIENT_LINEAR", Visible: true, Opacity: 1,
+			GradientHandlePositions: []models.Vector{{X: 0, Y: 0}, {X: 1, Y: 1}},
+			GradientStops:           []models.GradientStop{{Position: 0, Color: "#FFFFFF"}, {Position: 1, Color: "#00000080"}}}}
+		file, err := codegen.ExportSVG([]models.Node{{NodeID: "2:0", Name: "Blob", Type: "VECTOR", Visible: true, X: 10, Y: 10, Width: 8, Height: 8,
+			Properties: properties(t, models.NodeProperties{Fills: gradient, FillGeometry: []models.Path{{Path: "M0 0L8 8"}}})}}, "2:0")
+		if err != nil {
+			t.Fatalf("Failed to export SVG: %v", err)
+		}
+		for _, expected := range []string{
+			`<linearGradient id="gradient-1" x1="0" y1="0" x2="1" y2="1">`,
+			`<stop offset="1" stop-color="#000000" stop-opacity="0.5"/>`,
+			`<path d="M0 0L8 8" fill="url(#gradient-1)"/>`,
+		} {
+			if !strings.Contains(string(file.Code), expected) {
+				t.Errorf("Expected the SVG to contain %s:\n%s", expected, file.Code)
+			}
+		}
+	})
+
+	t.Run("nothing to draw", func(t *testing.T) {
+		_, err := codegen.ExportSVG(nodes[3:4], "1:3")
+		if !errors.Is(err, errors.ErrNotFound) {
+			t.Errorf("Expected a not found error for a vector without geometry, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,344 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"parser-service/internal/errors"
+	"parser-service/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PageSource is a page, or a frame of a page, exported as a static site
+type PageSource struct {
+	Name       string            // page or frame name, the site title
+	RootNodeID string            // Figma node ID of the page (CANVAS) or of the frame
+	Nodes      []models.Node     // node tree of RootNodeID
+	TextNodes  []models.TextNode // text nodes of the file, with their copy and typography
+	Background string            // page background color, empty to keep the browser default
+}
+
+// Site is a static HTML/CSS export: index.html linking styles.css, both referring to image fills as assets
+type Site struct {
+	Name   string   // file name friendly name of the page or frame, e.g. landing
+	HTML   []byte   // index.html
+	CSS    []byte   // styles.css
+	Assets []string // image fill references the site uses, referred to at AssetPath(imageRef), sorted
+}
+
+// AssetPath is where the site refers to the image of an image fill, relative to index.html and styles.css.
+// Image fills can be PNG, JPEG or GIF, which is only known once downloaded, see AssetFile and LinkAssets
+func AssetPath(imageRef string) string {
+	return "assets/" + nonFileNameCharacters.ReplaceAllString(imageRef, "-")
+}
+
+var nonFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// assetExtensions are the file extensions of the image types Figma accepts as image fills
+var assetExtensions = map[string]string{
+	"image/png": ".png", "image/jpeg": ".jpg", "image/gif": ".gif", "image/webp": ".webp",
+}
+
+// AssetFile names the downloaded image of an image fill after its type, AssetPath when the type is unknown
+func AssetFile(imageRef string, image []byte) string {
+	return AssetPath(imageRef) + assetExtensions[http.DetectContentType(image)]
+}
+
+// LinkAssets returns the HTML and stylesheet of the site referring to the images at files, by image reference,
+// instead of at AssetPath. Images missing from files keep their AssetPath
+func (s *Site) LinkAssets(files map[string]string) (htmlContent, css []byte) {
+	// Asset paths are always quoted, in src="..." as in url("..."), which keeps one from matching the start of another
+	replacements := make([]string, 0, 2*len(files))
+	for imageRef, file := range files {
+		replacements = append(replacements, strconv.Quote(AssetPath(imageRef)), strconv.Quote(file))
+	}
+	replacer := strings.NewReplacer(replacements...)
+	return []byte(replacer.Replace(string(s.HTML))), []byte(replacer.Replace(string(s.CSS)))
+}
+
+// ExportHTML writes the node tree of a page or frame as semantic HTML and a stylesheet. Auto Layout becomes
+// flexbox, everything else is placed absolutely as in the design. Instances are written out like frames
+func ExportHTML(source PageSource) (*Site, error) {
+	root := buildTree(source.Nodes, source.RootNodeID)
+	if root == nil {
+		return nil, errors.NotFound("node %s has no node tree, parse the file again to capture it", source.RootNodeID)
+	}
+	if root.Type == "CANVAS" {
+		fitChildren(root)
+	}
+
+	x := &htmlExporter{
+		textNodes: make(map[string]models.TextNode, len(source.TextNodes)),
+		classes:   make(map[string]bool),
+		assets:    make(map[string]bool),
+	}
+	for _, textNode := range source.TextNodes {
+		x.textNodes[textNode.NodeID] = textNode
+	}
+	main := x.element(root, nil, false)
+
+	name := slug(source.Name)
+	if name == "" {
+		name = "page"
+	}
+	site := &Site{Name: name, HTML: x.writeHTML(source, main), CSS: x.writeCSS(source, root)}
+	for imageRef := range x.assets {
+		site.Assets = append(site.Assets, imageRef)
+	}
+	sort.Strings(site.Assets)
+	return site, nil
+}
+
+// fitChildren gives a page the bounds of its visible children, which are placed relative to them
+func fitChildren(page *node) {
+	first := true
+	var minX, minY, maxX, maxY float64
+	for _, child := range page.children {
+		if child.hidden() {
+			continue
+		}
+		if first || child.X < minX {
+			minX = child.X
+		}
+		if first || child.Y < minY {
+			minY = child.Y
+		}
+		if first || child.X+child.Width > maxX {
+			maxX = child.X + child.Width
+		}
+		if first || child.Y+child.Height > maxY {
+			maxY = child.Y + child.Height
+		}
+		first = false
+	}
+	page.X, page.Y, page.Width, page.Height = minX, minY, maxX-minX, maxY-minY
+}
+
+// htmlExporter writes a node tree as HTML elements, each styled by a class rule
+type htmlExporter struct {
+	textNodes map[string]models.TextNode // by Figma node ID
+	classes   map[string]bool            // class names given so far
+	rules     []htmlRule                 // in document order
+	assets    map[string]bool            // image references used
+}
+
+type htmlRule struct {
+	class string
+	style []Declaration
+}
+
+// htmlElement is an HTML element, attributes and text already escaped
+type htmlElement struct {
+	tag        string
+	class      string
+	attributes []string // e.g. type="button"
+	children   []*htmlElement
+	text       string
+}
+
+// element builds the HTML of a node, nil when the node is hidden. inButton tells whether it is inside a button,
+// which only holds phrasing content
+func (x *htmlExporter) element(n *node, parent *node, inButton bool) *htmlElement {
+	if parent != nil && n.hidden() {
+		return nil
+	}
+
+	e := &htmlElement{class: x.className(n)}
+	// the rule is listed before the rules of the children, in document order
+	rule := len(x.rules)
+	x.rules = append(x.rules, htmlRule{class: e.class})
+	style := placement(n, parent)
+
+	switch {
+	case n.Type == "TEXT":
+		textNode := x.textNodes[n.NodeID]
+		var textStyle models.TextStyle
+		_ = json.Unmarshal(textNode.Style, &textStyle)
+		e.tag = textTag(textStyle, inButton)
+		e.text = strings.ReplaceAll(html.EscapeString(textNode.Characters), "\n", "<br>")
+		style = append(style, textStyles(textStyle, n.properties.Fills)...)
+
+	case parent != nil && len(n.children) == 0 && imageFill(n.properties.Fills) != nil:
+		// a picture rather than a box filled with one
+		paint := imageFill(n.properties.Fills)
+		x.assets[paint.ImageRef] = true
+		e.tag = "img"
+		e.attributes = []string{`src="` + html.EscapeString(AssetPath(paint.ImageRef)) + `"`, `alt="` + html.EscapeString(n.Name) + `"`}
+		fit := "cover"
+		switch paint.ScaleMode {
+		case "FIT":
+			fit = "contain"
+		case "STRETCH":
+			fit = "fill"
+		}
+		style = append(style, Declaration{"object-fit", fit})
+		style = append(style, boxStyles(n.properties, nil)...)
+
+	default:
+		e.tag, e.attributes = "main", nil
+		if parent != nil {
+			e.tag, e.attributes = containerTag(n, inButton)
+		}
+		inButton = inButton || e.tag == "button"
+		style = append(style, boxStyles(n.properties, x.imageURL)...)
+		positioned := false
+		for _, child := range n.children {
+			if childElement := x.element(child, n, inButton); childElement != nil {
+				e.children = append(e.children, childElement)
+				positioned = positioned || isAbsolute(child, n)
+			}
+		}
+		// absolutely placed children are placed relative to their parent
+		if positioned && (parent == nil || !isAbsolute(n, parent)) {
+			style = append([]Declaration{{"position", "relative"}}, style...)
+		}
+	}
+
+	x.rules[rule].style = style
+	return e
+}
+
+// imageURL locates the image of an image fill in the stylesheet, recording it as an asset
+func (x *htmlExporter) imageURL(imageRef string) string {
+	x.assets[imageRef] = true
+	return AssetPath(imageRef)
+}
+
+// imageFill returns the image fill of a node filled with nothing else, nil otherwise
+func imageFill(fills []models.Paint) *models.Paint {
+	var image *models.Paint
+	for i, paint := range fills {
+		if !paint.Visible {
+			continue
+		}
+		if paint.Type != "IMAGE" || paint.ImageRef == "" || image != nil {
+			return nil
+		}
+		image = &fills[i]
+	}
+	return image
+}
+
+// textTag picks a heading for large text and a paragraph otherwise, spans inside buttons
+func textTag(style models.TextStyle, inButton bool) string {
+	switch {
+	case inButton:
+		return "span"
+	case style.FontSize >= 32:
+		return "h1"
+	case style.FontSize >= 24:
+		return "h2"
+	case style.FontSize >= 20:
+		return "h3"
+	default:
+		return "p"
+	}
+}
+
+// containerTag picks a semantic element from the layer name, e.g. a "Header" frame is a header
+func containerTag(n *node, inButton bool) (string, []string) {
+	if inButton {
+		return "span", nil
+	}
+	if n.Type == "SECTION" {
+		return "section", nil
+	}
+	for _, word := range splitWords(strings.ToLower(n.Name)) {
+		switch word {
+		case "header", "footer", "nav", "section", "aside":
+			return word, nil
+		case "navbar", "navigation", "menu":
+			return "nav", nil
+		case "button", "btn", "cta":
+			return "button", []string{`type="button"`}
+		case "card", "article", "post":
+			return "article", nil
+		}
+	}
+	return "div", nil
+}
+
+// className gives a node a class named after its layer, numbered when the name is taken
+func (x *htmlExporter) className(n *node) string {
+	base := slug(n.Name)
+	if base == "" {
+		base = strings.ToLower(n.Type)
+	}
+	if base[0] >= '0' && base[0] <= '9' {
+		base = "layer-" + base
+	}
+	name := base
+	for i := 2; x.classes[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	x.classes[name] = true
+	return name
+}
+
+// slug lowercases a name into hyphen separated words, "Hero / Title" is hero-title
+func slug(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+func (x *htmlExporter) writeHTML(source PageSource, main *htmlElement) []byte {
+	var w bytes.Buffer
+	w.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n")
+	w.WriteString("  <meta charset=\"utf-8\">\n")
+	w.WriteString("  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&w, "  <title>%s</title>\n", html.EscapeString(source.Name))
+	w.WriteString("  <link rel=\"stylesheet\" href=\"styles.css\">\n</head>\n<body>\n")
+	main.write(&w, 1)
+	w.WriteString("</body>\n</html>\n")
+	return w.Bytes()
+}
+
+// write prints the element at level, on a single line when it has no element children
+func (e *htmlElement) write(w *bytes.Buffer, level int) {
+	indent := strings.Repeat("  ", level)
+	opening := "<" + strings.Join(append([]string{e.tag, `class="` + e.class + `"`}, e.attributes...), " ") + ">"
+	switch {
+	case e.tag == "img":
+		w.WriteString(indent + opening + "\n")
+	case len(e.children) == 0:
+		w.WriteString(indent + opening + e.text + "</" + e.tag + ">\n")
+	default:
+		w.WriteString(indent + opening + "\n")
+		for _, child := range e.children {
+			child.write(w, level+1)
+		}
+		w.WriteString(indent + "</" + e.tag + ">\n")
+	}
+}
+
+func (x *htmlExporter) writeCSS(source PageSource, root *node) []byte {
+	var w bytes.Buffer
+	kind := "frame"
+	if root.Type == "CANVAS" {
+		kind = "page"
+	}
+	fmt.Fprintf(&w, "/* Generated from the Figma %s %s (%s), do not edit */\n\n", kind, strings.ReplaceAll(strconv.Quote(source.Name), "*/", "*\\/"), root.NodeID)
+	w.WriteString("*,\n*::before,\n*::after {\n  box-sizing: border-box;\n}\n\n")
+	w.WriteString("body {\n  margin: 0;\n")
+	if source.Background != "" {
+		fmt.Fprintf(&w, "  background-color: %s;\n", source.Background)
+	}
+	w.WriteString("}\n\n")
+	w.WriteString("h1,\nh2,\nh3,\np {\n  margin: 0;\n}\n\n")
+	w.WriteString("button {\n  padding: 0;\n  border: none;\n  background: none;\n  color: inherit;\n  font: inherit;\n  text-align: inherit;\n}\n")
+	for _, rule := range x.rules {
+		if len(rule.style) == 0 {
+			continue
+		}
+		fmt.Fprintf(&w, "\n.%s {\n", rule.class)
+		for _, declaration := range rule.style {
+			fmt.Fprintf(&w, "  %s: %s;\n", declaration.Property, declaration.Value)
+		}
+		w.WriteString("}\n")
+	}
+	return w.Bytes()
+}
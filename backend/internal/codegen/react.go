@@ -0,0 +1,460 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"parser-service/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxLineLength is the length after which elements, props and style objects are split over several lines
+const maxLineLength = 100
+
+// reactGenerator writes a component as a TSX function component with inline styles
+type reactGenerator struct {
+	*generator
+	imports map[string]bool // generated components imported from their own file
+}
+
+func generateReact(g *generator) (*File, error) {
+	r := &reactGenerator{generator: g, imports: make(map[string]bool)}
+
+	var body bytes.Buffer
+	if g.root.Type == "COMPONENT_SET" {
+		r.writeVariants(&body)
+	} else {
+		r.writeReturn(&body, r.element(g.root, nil), 1)
+	}
+	signature := r.signature()
+
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Generated from the Figma component %s (%s), do not edit\n", strconv.Quote(g.source.Component.Name), g.root.NodeID)
+	code.WriteString("import * as React from \"react\";\n")
+	imports := make([]string, 0, len(r.imports))
+	for name := range r.imports {
+		imports = append(imports, name)
+	}
+	sort.Strings(imports)
+	for _, name := range imports {
+		fmt.Fprintf(&code, "import { %s } from \"./%s\";\n", name, name)
+	}
+
+	fmt.Fprintf(&code, "\nexport interface %sProps {\n", g.name)
+	for _, p := range g.props {
+		fmt.Fprintf(&code, "  %s?: %s;\n", p.Name, propType(p))
+	}
+	code.WriteString("  style?: React.CSSProperties;\n}\n\n")
+
+	if description := strings.TrimSpace(g.source.Component.Description); description != "" {
+		fmt.Fprintf(&code, "/** %s */\n", strings.ReplaceAll(strings.Join(strings.Fields(description), " "), "*/", "*\\/"))
+	}
+	code.WriteString(signature)
+	code.Write(body.Bytes())
+	code.WriteString("}\n")
+
+	return &File{Name: g.name + ".tsx", ComponentName: g.name, ContentType: "application/typescript; charset=utf-8", Code: code.Bytes()}, nil
+}
+
+// signature writes the function declaration, props destructured with their Figma default values
+func (r *reactGenerator) signature() string {
+	params := make([]string, 0, len(r.props)+1)
+	for _, p := range r.props {
+		if value, ok := r.propDefault(p); ok {
+			params = append(params, p.Name+" = "+value)
+		} else {
+			params = append(params, p.Name)
+		}
+	}
+	params = append(params, "style")
+
+	line := fmt.Sprintf("export function %s({ %s }: %sProps) {\n", r.name, strings.Join(params, ", "), r.name)
+	if len(line) <= maxLineLength+1 {
+		return line
+	}
+	return fmt.Sprintf("export function %s({\n  %s,\n}: %sProps) {\n", r.name, strings.Join(params, ",\n  "), r.name)
+}
+
+func propType(p prop) string {
+	switch p.Type {
+	case "BOOLEAN":
+		return "boolean"
+	case "INSTANCE_SWAP":
+		return "React.ReactNode"
+	case "VARIANT":
+		if len(p.VariantOptions) > 0 {
+			options := make([]string, len(p.VariantOptions))
+			for i, option := range p.VariantOptions {
+				options[i] = jsString(option)
+			}
+			return strings.Join(options, " | ")
+		}
+	}
+	return "string"
+}
+
+// propDefault writes the default value of a prop, false when it has none
+func (r *reactGenerator) propDefault(p prop) (string, bool) {
+	switch p.Type {
+	case "BOOLEAN":
+		value, ok := p.DefaultValue.(bool)
+		return strconv.FormatBool(value), ok
+	case "INSTANCE_SWAP":
+		nodeID, _ := p.DefaultValue.(string)
+		return r.componentElement(nodeID)
+	default:
+		if p.DefaultValue == nil {
+			return "", false
+		}
+		return jsString(fmt.Sprint(p.DefaultValue)), true
+	}
+}
+
+// componentElement writes an element of the generated component of a component node, importing it
+func (r *reactGenerator) componentElement(nodeID string) (string, bool) {
+	component, exists := r.components[nodeID]
+	if !exists {
+		return "", false
+	}
+	return "<" + r.importComponent(component) + " />", true
+}
+
+// importComponent returns the name of the generated component code refers to for component, and imports it
+func (r *reactGenerator) importComponent(component models.Component) string {
+	name := componentName(r.publicComponent(component).Name)
+	if name != r.name {
+		r.imports[name] = true
+	}
+	return name
+}
+
+// writeVariants returns the variant matching the variant props, the default variant when none does
+func (r *reactGenerator) writeVariants(w *bytes.Buffer) {
+	var variants []*node
+	for _, child := range r.root.children {
+		if child.Type == "COMPONENT" {
+			variants = append(variants, child)
+		}
+	}
+	if len(variants) == 0 {
+		r.writeReturn(w, &element{tag: "div", styleSpread: "style"}, 1)
+		return
+	}
+
+	defaultVariant := variants[0]
+	for _, variant := range variants {
+		if r.isDefaultVariant(variant) {
+			defaultVariant = variant
+			break
+		}
+	}
+	for _, variant := range variants {
+		if variant == defaultVariant {
+			continue
+		}
+		fmt.Fprintf(w, "  if (%s) {\n", r.variantCondition(variant))
+		r.writeReturn(w, r.element(variant, nil), 2)
+		w.WriteString("  }\n")
+	}
+	r.writeReturn(w, r.element(defaultVariant, nil), 1)
+}
+
+func (r *reactGenerator) variantValues(variant *node) map[string]string {
+	var values map[string]string
+	_ = json.Unmarshal(r.components[variant.NodeID].VariantProperties, &values)
+	return values
+}
+
+func (r *reactGenerator) isDefaultVariant(variant *node) bool {
+	values := r.variantValues(variant)
+	for _, p := range r.props {
+		if p.Type == "VARIANT" && values[p.Definition] != fmt.Sprint(p.DefaultValue) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *reactGenerator) variantCondition(variant *node) string {
+	values := r.variantValues(variant)
+	var conditions []string
+	for _, p := range r.props {
+		if value, exists := values[p.Definition]; exists && p.Type == "VARIANT" {
+			conditions = append(conditions, p.Name+" === "+jsString(value))
+		}
+	}
+	if len(conditions) == 0 {
+		return "false"
+	}
+	return strings.Join(conditions, " && ")
+}
+
+func (r *reactGenerator) writeReturn(w *bytes.Buffer, root *element, level int) {
+	indent := strings.Repeat("  ", level)
+	w.WriteString(indent + "return (\n")
+	for _, line := range root.lines(len(indent) + 2) {
+		w.WriteString(line + "\n")
+	}
+	w.WriteString(indent + ");\n")
+}
+
+// element is a JSX element, or a {expression} when expression is set
+type element struct {
+	tag         string
+	attributes  []string // e.g. size="Small"
+	style       []Declaration
+	styleSpread string // object spread last in the style, the style prop of the root
+	children    []*element
+	text        string // text content, escaped for JSX
+	expression  string
+	condition   string // boolean prop the element is rendered under
+}
+
+// element builds the JSX of a node, nil when the node is hidden for good
+func (r *reactGenerator) element(n *node, parent *node) *element {
+	var condition string
+	if reference, bound := n.properties.PropertyReferences["visible"]; bound {
+		if p, exists := propByDefinition(r.props, reference); exists && p.Type == "BOOLEAN" {
+			condition = p.Name
+		}
+	}
+	if condition == "" && n.hidden() && parent != nil {
+		return nil
+	}
+
+	e := &element{condition: condition, style: placement(n, parent)}
+	if parent == nil {
+		e.styleSpread = "style"
+	}
+
+	switch {
+	case n.Type == "INSTANCE" && parent != nil:
+		if reference, bound := n.properties.PropertyReferences["mainComponent"]; bound {
+			if p, exists := propByDefinition(r.props, reference); exists {
+				e.expression = p.Name
+				return e
+			}
+		}
+		if instance, component, exists := r.instanceComponent(n); exists {
+			e.tag = r.importComponent(component)
+			e.attributes = r.instanceAttributes(instance, component)
+			return e
+		}
+	case n.Type == "TEXT":
+		e.tag = "span"
+		textNode := r.textNodes[n.NodeID]
+		var style models.TextStyle
+		_ = json.Unmarshal(textNode.Style, &style)
+		e.style = append(e.style, textStyles(style, n.properties.Fills)...)
+		e.text = jsxText(textNode.Characters)
+		if reference, bound := n.properties.PropertyReferences["characters"]; bound {
+			if p, exists := propByDefinition(r.props, reference); exists {
+				e.text = "{" + p.Name + "}"
+			}
+		}
+		return e
+	}
+
+	e.tag = "div"
+	e.style = append(e.style, boxStyles(n.properties, nil)...)
+	positioned := false
+	for _, child := range n.children {
+		if childElement := r.element(child, n); childElement != nil {
+			e.children = append(e.children, childElement)
+			positioned = positioned || isAbsolute(child, n)
+		}
+	}
+	// absolutely placed children are placed relative to their parent
+	if positioned && (parent == nil || !isAbsolute(n, parent)) {
+		e.style = append([]Declaration{{"position", "relative"}}, e.style...)
+	}
+	return e
+}
+
+// instanceAttributes writes the property values an instance sets, variant values included, when they differ
+// from the defaults of its component
+func (r *reactGenerator) instanceAttributes(instance models.Instance, component models.Component) []string {
+	values := make(map[string]interface{})
+	var variantValues map[string]string
+	_ = json.Unmarshal(component.VariantProperties, &variantValues)
+	for name, value := range variantValues {
+		values[name] = value
+	}
+	var instanceValues map[string]models.InstanceComponentProperty
+	_ = json.Unmarshal(instance.ComponentProperties, &instanceValues)
+	for name, property := range instanceValues {
+		values[name] = property.Value
+	}
+
+	var attributes []string
+	for _, p := range componentProps(r.publicComponent(component).PropertyDefinitions) {
+		value, exists := values[p.Definition]
+		if !exists || value == nil || fmt.Sprint(value) == fmt.Sprint(p.DefaultValue) {
+			continue
+		}
+		switch p.Type {
+		case "BOOLEAN":
+			if enabled, _ := value.(bool); enabled {
+				attributes = append(attributes, p.Name)
+			} else {
+				attributes = append(attributes, p.Name+"={false}")
+			}
+		case "INSTANCE_SWAP":
+			if swapped, ok := r.componentElement(fmt.Sprint(value)); ok {
+				attributes = append(attributes, p.Name+"={"+swapped+"}")
+			}
+		default:
+			attributes = append(attributes, p.Name+"="+jsxAttributeString(fmt.Sprint(value)))
+		}
+	}
+	return attributes
+}
+
+// lines prints the element at indent, on a single line when it fits
+func (e *element) lines(indent int) []string {
+	pad := strings.Repeat(" ", indent)
+	if e.condition != "" {
+		inner := *e
+		inner.condition = ""
+		if inner.expression != "" {
+			return []string{pad + "{" + e.condition + " && " + inner.expression + "}"}
+		}
+		if line, ok := inner.singleLine(); ok && indent+len(e.condition)+len(line)+6 <= maxLineLength {
+			return []string{pad + "{" + e.condition + " && " + line + "}"}
+		}
+		lines := []string{pad + "{" + e.condition + " && ("}
+		lines = append(lines, inner.lines(indent+2)...)
+		return append(lines, pad+")}")
+	}
+	if line, ok := e.singleLine(); ok && indent+len(line) <= maxLineLength {
+		return []string{pad + line}
+	}
+
+	var lines []string
+	opening := e.openingTag()
+	if len(e.children) == 0 && e.text == "" {
+		opening = strings.TrimSuffix(opening, ">") + " />"
+	}
+	if indent+len(opening) <= maxLineLength {
+		lines = append(lines, pad+opening)
+	} else {
+		lines = append(lines, pad+"<"+e.tag)
+		for _, attribute := range e.attributes {
+			lines = append(lines, pad+"  "+attribute)
+		}
+		if properties := e.styleProperties(); len(properties) > 0 {
+			lines = append(lines, pad+"  style={{")
+			for _, property := range properties {
+				lines = append(lines, pad+"    "+property+",")
+			}
+			lines = append(lines, pad+"  }}")
+		}
+		if len(e.children) == 0 && e.text == "" {
+			return append(lines, pad+"/>")
+		}
+		lines = append(lines, pad+">")
+	}
+	if len(e.children) == 0 && e.text == "" {
+		return lines
+	}
+
+	if e.text != "" {
+		lines = append(lines, pad+"  "+e.text)
+	}
+	for _, child := range e.children {
+		lines = append(lines, child.lines(indent+2)...)
+	}
+	return append(lines, pad+"</"+e.tag+">")
+}
+
+// singleLine prints an element without element children on one line
+func (e *element) singleLine() (string, bool) {
+	if e.expression != "" {
+		return "{" + e.expression + "}", true
+	}
+	if len(e.children) > 0 {
+		return "", false
+	}
+	if e.text == "" {
+		return strings.TrimSuffix(e.openingTag(), ">") + " />", true
+	}
+	return e.openingTag() + e.text + "</" + e.tag + ">", true
+}
+
+func (e *element) openingTag() string {
+	parts := append([]string{"<" + e.tag}, e.attributes...)
+	if properties := e.styleProperties(); len(properties) > 0 {
+		parts = append(parts, "style={{ "+strings.Join(properties, ", ")+" }}")
+	}
+	return strings.Join(parts, " ") + ">"
+}
+
+// styleProperties writes the style as the properties of a React.CSSProperties object
+func (e *element) styleProperties() []string {
+	properties := make([]string, 0, len(e.style)+1)
+	for _, declaration := range e.style {
+		properties = append(properties, camelCase(declaration.Property)+": "+reactStyleValue(declaration))
+	}
+	if e.styleSpread != "" {
+		properties = append(properties, "..."+e.styleSpread)
+	}
+	return properties
+}
+
+var (
+	plainNumber = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	pixels      = regexp.MustCompile(`^-?\d+(\.\d+)?px$`)
+)
+
+// reactStyleValue writes numbers and pixel values as numbers, React adding the px unit back. Line heights keep
+// their unit as React reads a plain number as a multiple of the font size
+func reactStyleValue(declaration Declaration) string {
+	value := declaration.Value
+	if plainNumber.MatchString(value) {
+		return value
+	}
+	if pixels.MatchString(value) && declaration.Property != "line-height" {
+		return strings.TrimSuffix(value, "px")
+	}
+	return jsString(value)
+}
+
+// camelCase turns a CSS property into its React name, background-color is backgroundColor
+func camelCase(property string) string {
+	parts := strings.Split(property, "-")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = upperFirst(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
+// jsString quotes a string for JavaScript
+func jsString(value string) string {
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(output.String(), "\n")
+}
+
+// jsxAttributeString writes a string attribute value, as an expression when JSX quoting can't hold it
+func jsxAttributeString(value string) string {
+	if strings.ContainsAny(value, "\"\\{}\n") {
+		return "{" + jsString(value) + "}"
+	}
+	return `"` + value + `"`
+}
+
+// jsxText writes text content, as an expression when it holds characters JSX would read as markup or trim
+func jsxText(text string) string {
+	if text == "" {
+		return ""
+	}
+	if strings.ContainsAny(text, "{}<>&\n") || strings.TrimSpace(text) != text {
+		return "{" + jsString(text) + "}"
+	}
+	return text
+}
@@ -0,0 +1,348 @@
+package codegen
+
+import (
+	"fmt"
+	"math"
+	"parser-service/models"
+	"strconv"
+	"strings"
+)
+
+// Declaration is a CSS declaration, e.g. {"border-radius", "8px"}. Targets write them in their own syntax
+type Declaration struct {
+	Property string
+	Value    string
+}
+
+// boxStyles returns the styles of a node drawn as a box: its Auto Layout when it lays out its children,
+// then its opacity, fills, strokes, corners and effects. Image fills are drawn from imageURL when not nil
+func boxStyles(properties models.NodeProperties, imageURL func(imageRef string) string) []Declaration {
+	var styles []Declaration
+	add := func(property, value string) {
+		if value != "" {
+			styles = append(styles, Declaration{property, value})
+		}
+	}
+
+	if layout := properties.Layout; layout != nil && layout.Mode != "" {
+		styles = append(styles, flexStyles(layout)...)
+	}
+	if properties.Opacity != nil && *properties.Opacity < 1 {
+		add("opacity", formatNumber(*properties.Opacity))
+	}
+
+	if properties.NodeType == "ELLIPSE" {
+		add("border-radius", "50%")
+	} else if corners := properties.CornerRadii; corners != nil {
+		add("border-radius", boxSides(corners.TopLeft, corners.TopRight, corners.BottomRight, corners.BottomLeft))
+	}
+	background, backgroundColor := backgroundStyles(properties.Fills, imageURL)
+	add("background-color", backgroundColor)
+	add("background", background)
+	if properties.StrokeWeight != nil && *properties.StrokeWeight > 0 {
+		if color, ok := solidColor(properties.Strokes); ok {
+			add("border", px(*properties.StrokeWeight)+" solid "+color)
+			// Figma draws inside strokes within the node bounds
+			if properties.StrokeAlign == "INSIDE" {
+				add("box-sizing", "border-box")
+			}
+		}
+	}
+
+	var shadows, filters, backdropFilters []string
+	for _, effect := range properties.Effects {
+		if !effect.Visible {
+			continue
+		}
+		switch effect.Type {
+		case "DROP_SHADOW", "INNER_SHADOW":
+			shadows = append(shadows, shadow(effect))
+		case "LAYER_BLUR":
+			filters = append(filters, "blur("+px(effect.Radius)+")")
+		case "BACKGROUND_BLUR":
+			backdropFilters = append(backdropFilters, "blur("+px(effect.Radius)+")")
+		}
+	}
+	add("box-shadow", strings.Join(shadows, ", "))
+	add("filter", strings.Join(filters, " "))
+	add("backdrop-filter", strings.Join(backdropFilters, " "))
+
+	return styles
+}
+
+// placement returns how a node is sized and placed in its parent: along the parent Auto Layout, or at its
+// position in the parent otherwise. The root is only sized
+func placement(n *node, parent *node) []Declaration {
+	layout := n.properties.Layout
+	if layout == nil {
+		layout = &models.Layout{}
+	}
+	sizing := func(value string) string {
+		if value == "" && n.Type == "TEXT" {
+			return "HUG"
+		}
+		return value
+	}
+	horizontal, vertical := sizing(layout.SizingHorizontal), sizing(layout.SizingVertical)
+
+	var styles []Declaration
+	switch {
+	case parent == nil:
+		if horizontal != "HUG" {
+			styles = append(styles, Declaration{"width", px(n.Width)})
+		}
+		if vertical != "HUG" {
+			styles = append(styles, Declaration{"height", px(n.Height)})
+		}
+	case isAbsolute(n, parent):
+		styles = append(styles,
+			Declaration{"position", "absolute"},
+			Declaration{"left", px(n.X - parent.X)},
+			Declaration{"top", px(n.Y - parent.Y)},
+			Declaration{"width", px(n.Width)},
+			Declaration{"height", px(n.Height)})
+	default:
+		rowParent := parent.properties.Layout.Mode == "HORIZONTAL"
+		for _, axis := range []struct {
+			property string
+			sizing   string
+			size     float64
+			primary  bool
+		}{
+			{"width", horizontal, n.Width, rowParent},
+			{"height", vertical, n.Height, !rowParent},
+		} {
+			switch {
+			case axis.primary && (axis.sizing == "FILL" || layout.Grow > 0):
+				styles = append(styles, Declaration{"flex", "1"})
+			case axis.sizing == "FILL":
+				styles = append(styles, Declaration{"align-self", "stretch"})
+			case axis.sizing != "HUG":
+				styles = append(styles, Declaration{axis.property, px(axis.size)})
+			}
+		}
+	}
+	return styles
+}
+
+// isAbsolute tells whether a node is placed at its position in its parent, rather than by an Auto Layout
+func isAbsolute(n *node, parent *node) bool {
+	parentLayout := parent.properties.Layout
+	if parentLayout == nil || parentLayout.Mode == "" {
+		return true
+	}
+	return n.properties.Layout != nil && n.properties.Layout.Positioning == "ABSOLUTE"
+}
+
+// flexStyles lays the children of an Auto Layout container out with flexbox
+func flexStyles(layout *models.Layout) []Declaration {
+	styles := []Declaration{{"display", "flex"}}
+	if layout.Mode == "VERTICAL" {
+		styles = append(styles, Declaration{"flex-direction", "column"})
+	}
+	if layout.Wrap {
+		styles = append(styles, Declaration{"flex-wrap", "wrap"})
+	}
+	if justify := flexAlignment[layout.PrimaryAxisAlign]; justify != "" {
+		styles = append(styles, Declaration{"justify-content", justify})
+	}
+	if align := flexAlignment[layout.CounterAxisAlign]; align != "" {
+		styles = append(styles, Declaration{"align-items", align})
+	}
+	if layout.ItemSpacing > 0 && layout.PrimaryAxisAlign != "SPACE_BETWEEN" {
+		styles = append(styles, Declaration{"gap", px(layout.ItemSpacing)})
+	}
+	// wrapped lines are spaced along the counter axis
+	if layout.Wrap && layout.CounterAxisSpacing > 0 {
+		gap := "row-gap"
+		if layout.Mode == "VERTICAL" {
+			gap = "column-gap"
+		}
+		styles = append(styles, Declaration{gap, px(layout.CounterAxisSpacing)})
+	}
+	if padding := layout.Padding; padding != nil && (padding.Top != 0 || padding.Right != 0 || padding.Bottom != 0 || padding.Left != 0) {
+		styles = append(styles, Declaration{"padding", boxSides(padding.Top, padding.Right, padding.Bottom, padding.Left)})
+	}
+	return styles
+}
+
+// flexAlignment maps Auto Layout alignments to flexbox ones, MIN being the flexbox default
+var flexAlignment = map[string]string{
+	"CENTER":        "center",
+	"MAX":           "flex-end",
+	"SPACE_BETWEEN": "space-between",
+	"BASELINE":      "baseline",
+}
+
+// textStyles returns the typography of a text node, its color coming from its fills
+func textStyles(style models.TextStyle, fills []models.Paint) []Declaration {
+	var styles []Declaration
+	add := func(property, value string) {
+		if value != "" {
+			styles = append(styles, Declaration{property, value})
+		}
+	}
+
+	if color, ok := solidColor(fills); ok {
+		add("color", color)
+	}
+	add("font-family", style.FontFamily)
+	if style.FontWeight > 0 {
+		add("font-weight", formatNumber(style.FontWeight))
+	}
+	if style.FontSize > 0 {
+		add("font-size", px(style.FontSize))
+	}
+	if style.Italic {
+		add("font-style", "italic")
+	}
+	switch {
+	case style.LineHeightUnit == "FONT_SIZE_%" && style.LineHeightPercent > 0:
+		add("line-height", formatNumber(style.LineHeightPercent)+"%")
+	case style.LineHeightUnit != "INTRINSIC_%" && style.LineHeightPx > 0:
+		add("line-height", px(style.LineHeightPx))
+	}
+	if style.LetterSpacing != 0 {
+		add("letter-spacing", px(style.LetterSpacing))
+	}
+	add("text-align", map[string]string{"CENTER": "center", "RIGHT": "right", "JUSTIFIED": "justify"}[style.TextAlignHorizontal])
+	add("text-transform", map[string]string{"UPPER": "uppercase", "LOWER": "lowercase", "TITLE": "capitalize"}[style.TextCase])
+	add("text-decoration", map[string]string{"UNDERLINE": "underline", "STRIKETHROUGH": "line-through"}[style.TextDecoration])
+	return styles
+}
+
+// backgroundStyles returns a background-color for a single solid fill, otherwise a background of every visible
+// fill, topmost first as CSS expects. Image fills are only rendered when imageURL locates their image
+func backgroundStyles(fills []models.Paint, imageURL func(imageRef string) string) (background, backgroundColor string) {
+	var layers, solids []string
+	for i := len(fills) - 1; i >= 0; i-- {
+		paint := fills[i]
+		if !paint.Visible {
+			continue
+		}
+		switch paint.Type {
+		case "SOLID":
+			color := paintColor(paint)
+			solids = append(solids, color)
+			layers = append(layers, "linear-gradient("+color+", "+color+")")
+		case "GRADIENT_LINEAR", "GRADIENT_RADIAL":
+			if gradient := gradient(paint); gradient != "" {
+				layers = append(layers, gradient)
+			}
+		case "IMAGE":
+			if imageURL != nil && paint.ImageRef != "" {
+				layers = append(layers, "url("+strconv.Quote(imageURL(paint.ImageRef))+") "+imageScaling(paint.ScaleMode))
+			}
+		}
+	}
+
+	if len(layers) == 1 && len(solids) == 1 {
+		return "", solids[0]
+	}
+	return strings.Join(layers, ", "), ""
+}
+
+// imageScaling writes the background position, size and repeat of an image fill scale mode. FILL, and CROP whose
+// transform isn't kept, cover the node
+func imageScaling(scaleMode string) string {
+	switch scaleMode {
+	case "FIT":
+		return "center / contain no-repeat"
+	case "STRETCH":
+		return "0 0 / 100% 100% no-repeat"
+	case "TILE":
+		return "0 0 repeat"
+	default:
+		return "center / cover no-repeat"
+	}
+}
+
+// gradient writes a linear or radial gradient paint as a CSS gradient
+func gradient(paint models.Paint) string {
+	if len(paint.GradientStops) == 0 {
+		return ""
+	}
+	stops := make([]string, len(paint.GradientStops))
+	for i, stop := range paint.GradientStops {
+		stops[i] = withOpacity(stop.Color, paint.Opacity) + " " + formatNumber(stop.Position*100) + "%"
+	}
+
+	if paint.Type == "GRADIENT_RADIAL" {
+		return "radial-gradient(" + strings.Join(stops, ", ") + ")"
+	}
+	angle := 180.0 // top to bottom, the CSS default
+	if handles := paint.GradientHandlePositions; len(handles) >= 2 {
+		// CSS angles start at the top and turn clockwise, y going down as in Figma
+		angle = math.Atan2(handles[1].X-handles[0].X, -(handles[1].Y-handles[0].Y)) * 180 / math.Pi
+		if angle < 0 {
+			angle += 360
+		}
+	}
+	return "linear-gradient(" + formatNumber(angle) + "deg, " + strings.Join(stops, ", ") + ")"
+}
+
+func shadow(effect models.Effect) string {
+	var x, y float64
+	if effect.Offset != nil {
+		x, y = effect.Offset.X, effect.Offset.Y
+	}
+	value := strings.Join([]string{px(x), px(y), px(effect.Radius), px(effect.Spread), effect.Color}, " ")
+	if effect.Type == "INNER_SHADOW" {
+		value = "inset " + value
+	}
+	return value
+}
+
+// solidColor returns the color of the first visible solid paint
+func solidColor(paints []models.Paint) (string, bool) {
+	for _, paint := range paints {
+		if paint.Type == "SOLID" && paint.Visible && paint.Color != "" {
+			return paintColor(paint), true
+		}
+	}
+	return "", false
+}
+
+func paintColor(paint models.Paint) string {
+	return withOpacity(paint.Color, paint.Opacity)
+}
+
+// withOpacity multiplies the alpha of a #RRGGBB or #RRGGBBAA color by opacity
+func withOpacity(hex string, opacity float64) string {
+	if opacity >= 1 || opacity < 0 || (len(hex) != 7 && len(hex) != 9) {
+		return hex
+	}
+	alpha := 1.0
+	if len(hex) == 9 {
+		value, err := strconv.ParseUint(hex[7:], 16, 8)
+		if err != nil {
+			return hex
+		}
+		alpha = float64(value) / 255
+	}
+	return fmt.Sprintf("%s%02X", hex[:7], int(math.Round(alpha*opacity*255)))
+}
+
+// boxSides writes the CSS shorthand of four sides (or corners), clockwise from the top (left)
+func boxSides(top, right, bottom, left float64) string {
+	switch {
+	case top == right && top == bottom && top == left:
+		return px(top)
+	case top == bottom && right == left:
+		return px(top) + " " + px(right)
+	default:
+		return strings.Join([]string{px(top), px(right), px(bottom), px(left)}, " ")
+	}
+}
+
+// formatNumber keeps two decimals, hiding float noise such as 1.2000000476837158
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+func px(value float64) string {
+	if formatNumber(value) == "0" || formatNumber(value) == "-0" {
+		return "0"
+	}
+	return formatNumber(value) + "px"
+}
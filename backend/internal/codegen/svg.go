@@ -0,0 +1,246 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"parser-service/internal/errors"
+	"parser-service/models"
+	"strconv"
+	"strings"
+)
+
+// ExportSVG draws a node and the shapes below it as an SVG document the size of the node. Shapes are drawn from
+// the vector paths captured with geometry=paths, rectangles, ellipses and lines from their bounds when the file was
+// parsed without them. Frames draw their fills as a rectangle. Text and image fills aren't drawn
+func ExportSVG(nodes []models.Node, rootNodeID string) (*File, error) {
+	root := buildTree(nodes, rootNodeID)
+	if root == nil {
+		return nil, errors.NotFound("node %s has no node tree, parse the file again to capture it", rootNodeID)
+	}
+
+	s := &svgWriter{root: root}
+	s.write(root, 1)
+	if s.shapes == 0 {
+		return nil, errors.NotFound("node %s has no vector geometry to draw, parse the file again to capture it", rootNodeID)
+	}
+
+	var code bytes.Buffer
+	width, height := formatNumber(root.Width), formatNumber(root.Height)
+	fmt.Fprintf(&code, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" fill="none">`+"\n", width, height, width, height)
+	if s.defs.Len() > 0 {
+		code.WriteString("  <defs>\n")
+		code.Write(s.defs.Bytes())
+		code.WriteString("  </defs>\n")
+	}
+	code.Write(s.body.Bytes())
+	code.WriteString("</svg>\n")
+
+	name := slug(root.Name)
+	if name == "" {
+		name = "node"
+	}
+	return &File{Name: name + ".svg", ContentType: "image/svg+xml", Code: code.Bytes()}, nil
+}
+
+// svgWriter writes the shapes of a node tree, in the coordinates of its root
+type svgWriter struct {
+	root      *node
+	body      bytes.Buffer
+	defs      bytes.Buffer // gradients
+	gradients int
+	shapes    int
+}
+
+func (s *svgWriter) write(n *node, level int) {
+	if n != s.root && n.hidden() {
+		return
+	}
+	indent := strings.Repeat("  ", level)
+
+	group := n != s.root && n.properties.Opacity != nil && *n.properties.Opacity < 1
+	if group {
+		fmt.Fprintf(&s.body, "%s<g opacity=\"%s\">\n", indent, formatNumber(*n.properties.Opacity))
+		level++
+		indent += "  "
+	}
+
+	switch {
+	case len(n.properties.FillGeometry) > 0 || len(n.properties.StrokeGeometry) > 0:
+		s.writePaths(n, indent)
+	case n.Type == "RECTANGLE" || n.Type == "ELLIPSE" || n.Type == "LINE":
+		s.writePrimitive(n, indent)
+	case n.Type == "TEXT" || models.IsShapeType(n.Type):
+		// text and shapes without geometry can't be drawn
+	default:
+		// frames draw their background below their children
+		if n.Type != "GROUP" && n.Type != "CANVAS" {
+			s.writePrimitive(n, indent)
+		}
+		for _, child := range n.children {
+			s.write(child, level)
+		}
+	}
+
+	if group {
+		fmt.Fprintf(&s.body, "%s</g>\n", indent[2:])
+	}
+}
+
+// writePaths fills the paths of a shape with each of its fills, then its outlined strokes with each of its strokes
+func (s *svgWriter) writePaths(n *node, indent string) {
+	transform := s.transform(n)
+	for _, layer := range []struct {
+		paints []models.Paint
+		paths  []models.Path
+	}{
+		{n.properties.Fills, n.properties.FillGeometry},
+		{n.properties.Strokes, n.properties.StrokeGeometry},
+	} {
+		for _, paint := range layer.paints {
+			fill, ok := s.paint("fill", paint)
+			if !ok {
+				continue
+			}
+			for _, path := range layer.paths {
+				attributes := []string{`d="` + html.EscapeString(path.Path) + `"`}
+				if path.WindingRule == "EVENODD" {
+					attributes = append(attributes, `fill-rule="evenodd"`)
+				}
+				if transform != "" {
+					attributes = append(attributes, `transform="`+transform+`"`)
+				}
+				s.body.WriteString(indent + "<path " + strings.Join(append(attributes, fill...), " ") + "/>\n")
+				s.shapes++
+			}
+		}
+	}
+}
+
+// transform places the node coordinates in the root bounds, from the node bounds when the node has no transform
+func (s *svgWriter) transform(n *node) string {
+	t := models.Transform{{1, 0, n.X}, {0, 1, n.Y}}
+	if n.properties.Transform != nil {
+		t = *n.properties.Transform
+	}
+	x, y := t[0][2]-s.root.X, t[1][2]-s.root.Y
+	if t[0][0] != 1 || t[0][1] != 0 || t[1][0] != 0 || t[1][1] != 1 {
+		return "matrix(" + strings.Join([]string{
+			formatNumber(t[0][0]), formatNumber(t[1][0]), formatNumber(t[0][1]), formatNumber(t[1][1]), formatNumber(x), formatNumber(y),
+		}, " ") + ")"
+	}
+	if formatNumber(x) == "0" && formatNumber(y) == "0" {
+		return ""
+	}
+	return "translate(" + formatNumber(x) + " " + formatNumber(y) + ")"
+}
+
+// writePrimitive draws a rectangle, ellipse or line from the node bounds, filled and stroked with each of its fills
+// and strokes
+func (s *svgWriter) writePrimitive(n *node, indent string) {
+	x, y := n.X-s.root.X, n.Y-s.root.Y
+	var shape []string
+	switch n.Type {
+	case "ELLIPSE":
+		shape = []string{"<ellipse", attribute("cx", x+n.Width/2), attribute("cy", y+n.Height/2), attribute("rx", n.Width/2), attribute("ry", n.Height/2)}
+	case "LINE":
+		shape = []string{"<line", attribute("x1", x), attribute("y1", y), attribute("x2", x+n.Width), attribute("y2", y+n.Height)}
+	default:
+		shape = []string{"<rect", attribute("x", x), attribute("y", y), attribute("width", n.Width), attribute("height", n.Height)}
+		if corners := n.properties.CornerRadii; corners != nil && corners.TopLeft > 0 &&
+			corners.TopLeft == corners.TopRight && corners.TopLeft == corners.BottomRight && corners.TopLeft == corners.BottomLeft {
+			shape = append(shape, attribute("rx", corners.TopLeft))
+		}
+	}
+
+	if n.Type != "LINE" {
+		for _, paint := range n.properties.Fills {
+			if fill, ok := s.paint("fill", paint); ok {
+				s.body.WriteString(indent + strings.Join(append(shape, fill...), " ") + "/>\n")
+				s.shapes++
+			}
+		}
+	}
+	if n.properties.StrokeWeight == nil || *n.properties.StrokeWeight <= 0 {
+		return
+	}
+	for _, paint := range n.properties.Strokes {
+		if stroke, ok := s.paint("stroke", paint); ok {
+			stroke = append(stroke, attribute("stroke-width", *n.properties.StrokeWeight))
+			s.body.WriteString(indent + strings.Join(append(shape, stroke...), " ") + "/>\n")
+			s.shapes++
+		}
+	}
+}
+
+// paint writes a visible solid or gradient paint as the fill or stroke attributes, false for other paints
+func (s *svgWriter) paint(property string, paint models.Paint) ([]string, bool) {
+	if !paint.Visible {
+		return nil, false
+	}
+	switch paint.Type {
+	case "SOLID":
+		if paint.Color == "" {
+			return nil, false
+		}
+		color, opacity := splitAlpha(paintColor(paint))
+		attributes := []string{property + `="` + color + `"`}
+		if opacity < 1 {
+			attributes = append(attributes, attribute(property+"-opacity", opacity))
+		}
+		return attributes, true
+	case "GRADIENT_LINEAR", "GRADIENT_RADIAL":
+		if len(paint.GradientStops) == 0 {
+			return nil, false
+		}
+		s.gradients++
+		id := "gradient-" + strconv.Itoa(s.gradients)
+		s.writeGradient(id, paint)
+		return []string{property + `="url(#` + id + `)"`}, true
+	}
+	return nil, false
+}
+
+// writeGradient defines a gradient, its handles being normalized to the bounds of the shape it paints
+func (s *svgWriter) writeGradient(id string, paint models.Paint) {
+	start, end := models.Vector{X: 0.5, Y: 0}, models.Vector{X: 0.5, Y: 1}
+	if handles := paint.GradientHandlePositions; len(handles) >= 2 {
+		start, end = handles[0], handles[1]
+	}
+	if paint.Type == "GRADIENT_RADIAL" {
+		radius := math.Hypot(end.X-start.X, end.Y-start.Y)
+		fmt.Fprintf(&s.defs, "    <radialGradient id=\"%s\" %s %s %s>\n", id, attribute("cx", start.X), attribute("cy", start.Y), attribute("r", radius))
+	} else {
+		fmt.Fprintf(&s.defs, "    <linearGradient id=\"%s\" %s %s %s %s>\n", id, attribute("x1", start.X), attribute("y1", start.Y), attribute("x2", end.X), attribute("y2", end.Y))
+	}
+	for _, stop := range paint.GradientStops {
+		color, opacity := splitAlpha(withOpacity(stop.Color, paint.Opacity))
+		stopOpacity := ""
+		if opacity < 1 {
+			stopOpacity = " " + attribute("stop-opacity", opacity)
+		}
+		fmt.Fprintf(&s.defs, "      <stop %s stop-color=\"%s\"%s/>\n", attribute("offset", stop.Position), color, stopOpacity)
+	}
+	if paint.Type == "GRADIENT_RADIAL" {
+		s.defs.WriteString("    </radialGradient>\n")
+	} else {
+		s.defs.WriteString("    </linearGradient>\n")
+	}
+}
+
+// splitAlpha splits a #RRGGBBAA color into #RRGGBB and an opacity, as SVG 1.1 readers don't read 8 digit colors
+func splitAlpha(hex string) (string, float64) {
+	if len(hex) != 9 {
+		return hex, 1
+	}
+	alpha, err := strconv.ParseUint(hex[7:], 16, 8)
+	if err != nil {
+		return hex[:7], 1
+	}
+	return hex[:7], float64(alpha) / 255
+}
+
+func attribute(name string, value float64) string {
+	return name + `="` + formatNumber(value) + `"`
+}
@@ -50,6 +50,11 @@ func InitPgsqlConnection() *DB {
 	return db
 }
 
+// NewDB wraps an already opened *sql.DB, e.g. one backed by a test driver
+func NewDB(sqlDB *sql.DB) *DB {
+	return &DB{db: sqlDB}
+}
+
 func (d *DB) CreateRecord(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	tx := GetTransactionFromContext(ctx)
 	if tx != nil {
@@ -74,6 +79,15 @@ func (d *DB) UpdateRecord(ctx context.Context, query string, args ...interface{}
 	return d.db.QueryRowContext(ctx, query, args...)
 }
 
+// UpdateRecords executes an update that may touch any number of rows
+func (d *DB) UpdateRecords(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tx := GetTransactionFromContext(ctx)
+	if tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	return d.db.ExecContext(ctx, query, args...)
+}
+
 func (d *DB) DeleteRecord(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	tx := GetTransactionFromContext(ctx)
 	if tx != nil {
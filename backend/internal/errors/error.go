@@ -2,36 +2,192 @@ package errors
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 type ErrorResponse struct {
 	Err     string `json:"err"`
 	Status  int    `json:"status"`
+	Code    string `json:"code"` // machine readable error code, e.g. "not_found"
 	Message string `json:"message"`
 }
 
-func HandleAPIError(statusCode int, body []byte) error {
+// Error kinds shared by the manager, service and handler layers. Check them with errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized") // missing, invalid or expired Figma token
+	ErrForbidden    = errors.New("forbidden")    // valid token without access to the resource
+	ErrRateLimited  = errors.New("rate limited")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrTooLarge     = errors.New("too large") // request body over its size limit
+	ErrUpstream     = errors.New("upstream error")
+)
+
+// Is and As are re-exported so callers importing this package don't need the standard errors package too
+var (
+	Is = errors.Is
+	As = errors.As
+)
+
+// Error is a typed error of one of the kinds above, optionally wrapping the underlying cause
+type Error struct {
+	Kind    error
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is/As
+func (e *Error) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Kind, e.Cause}
+	}
+	return []error{e.Kind}
+}
+
+func newError(kind error, cause error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Cause: cause}
+}
+
+func NotFound(format string, args ...any) error {
+	return newError(ErrNotFound, nil, format, args...)
+}
+
+func Unauthorized(format string, args ...any) error {
+	return newError(ErrUnauthorized, nil, format, args...)
+}
+
+func Forbidden(format string, args ...any) error {
+	return newError(ErrForbidden, nil, format, args...)
+}
+
+func InvalidInput(format string, args ...any) error {
+	return newError(ErrInvalidInput, nil, format, args...)
+}
+
+func TooLarge(format string, args ...any) error {
+	return newError(ErrTooLarge, nil, format, args...)
+}
+
+func Upstream(format string, args ...any) error {
+	return newError(ErrUpstream, nil, format, args...)
+}
+
+// Wrap attaches an error kind to an existing error, keeping it as the cause
+func Wrap(kind error, cause error, format string, args ...any) error {
+	return newError(kind, cause, format, args...)
+}
+
+// RateLimitError is returned when a Figma request is throttled, either by our own client side
+// limiter or by Figma itself once retries are exhausted
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration // zero when unknown
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: %s", e.Message)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) match rate limit errors
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// IsRateLimited reports whether err (or any error it wraps) is a RateLimitError
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// RetryAfter returns the suggested wait for a rate limited error, zero if unknown or not rate limited
+func RetryAfter(err error) time.Duration {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.RetryAfter
+	}
+	return 0
+}
+
+// RetryAfterHeader formats the suggested wait of a rate limited error as a Retry-After header value, in seconds
+// rounded up. Empty when there is no wait to suggest
+func RetryAfterHeader(err error) string {
+	retryAfter := RetryAfter(err)
+	if retryAfter <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+}
+
+// HTTPStatus maps an error to the HTTP status code and machine readable code returned to API callers
+func HTTPStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		return http.StatusBadRequest, "invalid_input"
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized, "unauthorized"
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, "payload_too_large"
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests, "rate_limited"
+	case errors.Is(err, ErrUpstream):
+		return http.StatusBadGateway, "upstream_error"
+	default:
+		return http.StatusInternalServerError, "internal_error"
+	}
+}
+
+// NewErrorResponse builds the API error body for err, with title as the short human readable error
+func NewErrorResponse(err error, title string) ErrorResponse {
+	status, code := HTTPStatus(err)
+	return ErrorResponse{
+		Err:     title,
+		Status:  status,
+		Code:    code,
+		Message: err.Error(),
+	}
+}
+
+// HandleAPIError converts a non-2xx Figma API response into a typed error. retryAfter is the wait Figma asked for
+// in its Retry-After header, zero when absent, and is passed on to API callers on rate limited responses
+func HandleAPIError(statusCode int, body []byte, retryAfter time.Duration) error {
+	// Rate limit responses don't always carry a JSON body
+	if statusCode == 429 {
+		return &RateLimitError{Message: "too many requests to Figma API", RetryAfter: retryAfter}
+	}
+
 	var errorResponse ErrorResponse
 	// Try to parse the error response
 	if err := json.Unmarshal(body, &errorResponse); err != nil {
-		return fmt.Errorf("HTTP %d: %s", statusCode, string(body))
-	}
-
-	switch statusCode {
-	case 400:
-		return fmt.Errorf("bad request: %s", errorResponse.Err)
-	case 401:
-		return fmt.Errorf("unauthorized: invalid Figma API token")
-	case 403:
-		return fmt.Errorf("forbidden: insufficient permissions for this file")
-	case 404:
-		return fmt.Errorf("not found: file does not exist or is not accessible")
-	case 429:
-		return fmt.Errorf("rate limit exceeded: too many requests")
-	case 500:
-		return fmt.Errorf("Figma server error: %s", errorResponse.Err)
+		errorResponse.Err = string(body)
+	}
+
+	switch {
+	case statusCode == 400:
+		return InvalidInput("bad request: %s", errorResponse.Err)
+	case statusCode == 401:
+		return Unauthorized("unauthorized: invalid or expired Figma API token")
+	case statusCode == 403:
+		return Forbidden("forbidden: insufficient permissions for this file")
+	case statusCode == 404:
+		return NotFound("not found: file does not exist or is not accessible")
+	case statusCode >= 500:
+		return Upstream("Figma server error (HTTP %d): %s", statusCode, errorResponse.Err)
 	default:
-		return fmt.Errorf("HTTP %d: %s", statusCode, errorResponse.Err)
+		return Upstream("HTTP %d: %s", statusCode, errorResponse.Err)
 	}
 }
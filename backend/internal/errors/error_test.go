@@ -0,0 +1,79 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http"
+	"parser-service/internal/errors"
+	"testing"
+	"time"
+)
+
+func TestHandleAPIError_MapsToHTTPStatus(t *testing.T) {
+	tests := []struct {
+		figmaStatus    int
+		expectedStatus int
+		expectedCode   string
+	}{
+		{400, http.StatusBadRequest, "invalid_input"},
+		{401, http.StatusUnauthorized, "unauthorized"},
+		{403, http.StatusForbidden, "forbidden"},
+		{404, http.StatusNotFound, "not_found"},
+		{429, http.StatusTooManyRequests, "rate_limited"},
+		{500, http.StatusBadGateway, "upstream_error"},
+		{503, http.StatusBadGateway, "upstream_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Figma %d", tt.figmaStatus), func(t *testing.T) {
+			err := errors.HandleAPIError(tt.figmaStatus, []byte(`{"status":0,"err":"boom"}`), 0)
+			// kinds must survive wrapping by the manager and service layers
+			wrapped := fmt.Errorf("failed to parse Figma file: %w", fmt.Errorf("failed to get file from Figma API: %w", err))
+
+			status, code := errors.HTTPStatus(wrapped)
+			if status != tt.expectedStatus || code != tt.expectedCode {
+				t.Errorf("Expected %d/%s, got %d/%s", tt.expectedStatus, tt.expectedCode, status, code)
+			}
+		})
+	}
+}
+
+func TestHandleAPIError_KeepsRetryAfter(t *testing.T) {
+	err := fmt.Errorf("failed to get file from Figma API: %w", errors.HandleAPIError(429, nil, 30*time.Second))
+	if !errors.IsRateLimited(err) || errors.RetryAfter(err) != 30*time.Second {
+		t.Errorf("Expected a rate limit error asking to retry after 30s, got %v (retry after %s)", err, errors.RetryAfter(err))
+	}
+	if header := errors.RetryAfterHeader(errors.HandleAPIError(429, nil, 1500*time.Millisecond)); header != "2" {
+		t.Errorf("Expected Retry-After to round up to 2 seconds, got %q", header)
+	}
+	if header := errors.RetryAfterHeader(errors.HandleAPIError(429, nil, 0)); header != "" {
+		t.Errorf("Expected no Retry-After when Figma suggests none, got %q", header)
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	cause := fmt.Errorf("unexpected end of JSON input")
+	err := errors.Wrap(errors.ErrInvalidInput, cause, "failed to decode Figma file JSON")
+
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Error("Expected wrapped error to match its kind")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected wrapped error to match its cause")
+	}
+	if errors.Is(err, errors.ErrNotFound) {
+		t.Error("Expected wrapped error not to match other kinds")
+	}
+
+	if status, code := errors.HTTPStatus(fmt.Errorf("plain error")); status != http.StatusInternalServerError || code != "internal_error" {
+		t.Errorf("Expected untyped errors to map to 500, got %d/%s", status, code)
+	}
+
+	if status, code := errors.HTTPStatus(errors.TooLarge("body over %d bytes", 1024)); status != http.StatusRequestEntityTooLarge || code != "payload_too_large" {
+		t.Errorf("Expected too large errors to map to 413, got %d/%s", status, code)
+	}
+
+	response := errors.NewErrorResponse(errors.NotFound("figma file %d not found", 42), "Failed to get file details")
+	if response.Status != http.StatusNotFound || response.Code != "not_found" || response.Message != "figma file 42 not found" {
+		t.Errorf("Unexpected error response: %+v", response)
+	}
+}
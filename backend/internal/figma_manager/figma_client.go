@@ -1,13 +1,16 @@
 package figma_manager
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"parser-service/internal/errors"
+	"parser-service/internal/progress"
 	"strings"
 	"time"
 )
@@ -15,50 +18,75 @@ import (
 const (
 	FigmaBaseURL   = "https://api.figma.com/v1"
 	DefaultTimeout = 30 * time.Second
+	// DefaultMaxImageSize caps a downloaded image. Figma keeps images under 4096px a side, so larger ones are unexpected
+	DefaultMaxImageSize = 32 << 20
 )
 
 type FigmaClient struct {
-	httpClient *http.Client
-	baseURL    string
+	httpClient   *http.Client
+	baseURL      string
+	retry        RetryConfig
+	limiter      *RateLimiter
+	maxImageSize int64
 }
 
 func NewFigmaClient() *FigmaClient {
-	return &FigmaClient{
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-		baseURL: FigmaBaseURL,
-	}
+	return NewFigmaClientWithConfig(FigmaBaseURL, nil)
 }
 
 // not used for now, but can be used to create a client with custom timeout
 func NewFigmaClientWithTimeout(timeout time.Duration) *FigmaClient {
+	return NewFigmaClientWithConfig(FigmaBaseURL, &http.Client{Timeout: timeout})
+}
+
+// NewFigmaClientWithConfig creates a client against a custom base URL (e.g. a fake Figma server in tests)
+// and http.Client. Empty baseURL and nil httpClient fall back to the defaults.
+func NewFigmaClientWithConfig(baseURL string, httpClient *http.Client) *FigmaClient {
+	if baseURL == "" {
+		baseURL = FigmaBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: DefaultTimeout,
+		}
+	}
 	return &FigmaClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		baseURL: FigmaBaseURL,
+		httpClient:   httpClient,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		retry:        DefaultRetryConfig(),
+		limiter:      NewRateLimiter(DefaultRateLimiterConfig()),
+		maxImageSize: DefaultMaxImageSize,
 	}
 }
 
+// WithRetryConfig overrides the retry behaviour for rate limited and failing requests
+func (c *FigmaClient) WithRetryConfig(config RetryConfig) *FigmaClient {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	c.retry = config
+	return c
+}
+
 // GetFile retrieves complete file data from Figma API
 func (c *FigmaClient) GetFile(ctx context.Context, fileKeyOrURL string) (*FigmaAPIResponse, error) {
 	if fileKeyOrURL == "" {
-		return nil, fmt.Errorf("file key cannot be empty")
+		return nil, errors.InvalidInput("file key cannot be empty")
 	}
 
 	// Clean the file key (remove any URL parts if a full URL was provided)
 	fileKey := c.extractFileKeyFromURL(fileKeyOrURL)
-	endpoint := fmt.Sprintf("%s/files/%s", c.baseURL, fileKey)
+	// geometry=paths adds the vector paths of shapes and the transforms placing them
+	endpoint := fmt.Sprintf("%s/files/%s?geometry=paths", c.baseURL, fileKey)
 
-	response, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	response, err := c.makeRequest(ctx, EndpointFiles, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file from Figma API: %w", err)
 	}
 
 	var figmaResponse FigmaAPIResponse
 	if err := json.Unmarshal(response, &figmaResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse Figma API response: %w", err)
+		return nil, errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma API response")
 	}
 
 	return &figmaResponse, nil
@@ -67,10 +95,10 @@ func (c *FigmaClient) GetFile(ctx context.Context, fileKeyOrURL string) (*FigmaA
 // GetFileImages retrieves rendered images for specific nodes
 func (c *FigmaClient) GetFileImages(ctx context.Context, fileKey string, nodeIDs []string) (map[string]string, error) {
 	if fileKey == "" {
-		return nil, fmt.Errorf("file key cannot be empty")
+		return nil, errors.InvalidInput("file key cannot be empty")
 	}
 	if len(nodeIDs) == 0 {
-		return nil, fmt.Errorf("node IDs cannot be empty")
+		return nil, errors.InvalidInput("node IDs cannot be empty")
 	}
 
 	// Clean the file key
@@ -86,7 +114,7 @@ func (c *FigmaClient) GetFileImages(ctx context.Context, fileKey string, nodeIDs
 	params.Add("scale", "1")    // Default scale, could be configurable
 	endpoint += "?" + params.Encode()
 
-	response, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	response, err := c.makeRequest(ctx, EndpointImages, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file images from Figma API: %w", err)
 	}
@@ -97,27 +125,156 @@ func (c *FigmaClient) GetFileImages(ctx context.Context, fileKey string, nodeIDs
 	}
 
 	if err := json.Unmarshal(response, &imageResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse Figma images response: %w", err)
+		return nil, errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma images response")
 	}
 
 	if imageResponse.Err != "" {
-		return nil, fmt.Errorf("Figma API error: %s", imageResponse.Err)
+		return nil, errors.Upstream("Figma API error: %s", imageResponse.Err)
 	}
 
 	return imageResponse.Images, nil
 }
 
-// makeRequest is a helper method to make HTTP requests to Figma API
-func (c *FigmaClient) makeRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, endpoint, body)
+// GetImageFills retrieves the download URLs of the images used as image fills in a file, by image reference.
+// URLs expire after 14 days at most
+func (c *FigmaClient) GetImageFills(ctx context.Context, fileKey string) (map[string]string, error) {
+	if fileKey == "" {
+		return nil, errors.InvalidInput("file key cannot be empty")
+	}
+
+	endpoint := fmt.Sprintf("%s/files/%s/images", c.baseURL, c.extractFileKeyFromURL(fileKey))
+	response, err := c.makeRequest(ctx, EndpointImages, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image fills from Figma API: %w", err)
+	}
+
+	var imageFillsResponse struct {
+		Meta struct {
+			Images map[string]string `json:"images"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(response, &imageFillsResponse); err != nil {
+		return nil, errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma image fills response")
+	}
+
+	return imageFillsResponse.Meta.Images, nil
+}
+
+// DownloadImage downloads an image from a URL returned by the images endpoints. These URLs point to Figma's
+// storage, not to the API, so the Figma token isn't sent
+func (c *FigmaClient) DownloadImage(ctx context.Context, imageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrUpstream, err, "image download failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Upstream("image download failed with status %d", resp.StatusCode)
+	}
+	// one byte over the limit tells a truncated image apart from one of exactly the limit
+	image, err := io.ReadAll(io.LimitReader(resp.Body, c.maxImageSize+1))
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrUpstream, err, "failed to read image")
+	}
+	if int64(len(image)) > c.maxImageSize {
+		return nil, errors.Upstream("image is larger than the %d bytes limit", c.maxImageSize)
+	}
+	return image, nil
+}
+
+// WithMaxImageSize overrides the size limit of downloaded images, in bytes
+func (c *FigmaClient) WithMaxImageSize(maxImageSize int64) *FigmaClient {
+	c.maxImageSize = maxImageSize
+	return c
+}
+
+// WithRateLimiter replaces the client side rate limiter, nil disables throttling
+func (c *FigmaClient) WithRateLimiter(limiter *RateLimiter) *FigmaClient {
+	c.limiter = limiter
+	return c
+}
+
+// makeRequest is a helper method to make HTTP requests to Figma API.
+// Requests are throttled per token and endpoint class; 429 and 5xx responses (and transport errors) are retried with backoff until the retry budget is spent.
+func (c *FigmaClient) makeRequest(ctx context.Context, class EndpointClass, method, endpoint string, body io.Reader) ([]byte, error) {
 	// Get the Figma token from context
 	figmaToken, ok := ctx.Value("figma_token").(string)
 	if !ok || figmaToken == "" {
-		return nil, fmt.Errorf("figma token is required")
+		return nil, errors.Unauthorized("figma token is required")
+	}
+
+	// Buffer the body so it can be replayed on retries
+	var requestBody []byte
+	if body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		// Every attempt, retries included, counts against the per-token rate limit
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, figmaToken, class); err != nil {
+				return nil, err
+			}
+		}
+
+		statusCode, responseBody, retryAfter, err := c.doRequest(ctx, method, endpoint, figmaToken, requestBody)
+		if err == nil && statusCode >= 200 && statusCode < 300 {
+			return responseBody, nil
+		}
+
+		// Build the error we return if this attempt turns out to be the last one
+		attemptErr := err
+		if attemptErr == nil {
+			attemptErr = errors.HandleAPIError(statusCode, responseBody, retryAfter)
+		}
+
+		retryable := (err != nil && ctx.Err() == nil) || (err == nil && isRetryableStatus(statusCode))
+		if !retryable {
+			return nil, attemptErr
+		}
+		if attempt >= c.retry.MaxAttempts {
+			log.Printf("Figma API %s %s: giving up after %d attempts: %v", method, endpoint, attempt, attemptErr)
+			return nil, attemptErr
+		}
+
+		delay := c.retry.nextDelay(attempt, retryAfter)
+		if waited+delay > c.retry.MaxRetryTime {
+			log.Printf("Figma API %s %s: retry budget of %s exhausted after %d attempts: %v", method, endpoint, c.retry.MaxRetryTime, attempt, attemptErr)
+			return nil, attemptErr
+		}
+		log.Printf("Figma API %s %s: attempt %d/%d failed (%v), retrying in %s", method, endpoint, attempt, c.retry.MaxAttempts, attemptErr, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request cancelled while waiting to retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+		waited += delay
+	}
+}
+
+// doRequest performs a single HTTP request and returns the status, body and any Retry-After wait.
+// A non-nil error means no response was received.
+func (c *FigmaClient) doRequest(ctx context.Context, method, endpoint, figmaToken string, requestBody []byte) (int, []byte, time.Duration, error) {
+	var body io.Reader
+	if requestBody != nil {
+		body = bytes.NewReader(requestBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Set required headers
@@ -128,22 +285,38 @@ func (c *FigmaClient) makeRequest(ctx context.Context, method, endpoint string,
 	// Make the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return 0, nil, 0, errors.Wrap(errors.ErrUpstream, err, "HTTP request failed")
 	}
 	defer resp.Body.Close()
 
-	// Read the response body
-	responseBody, err := io.ReadAll(resp.Body)
+	// Read the response body, reporting download progress for big files
+	responseBody, err := io.ReadAll(&progressReader{reader: resp.Body, reporter: progress.FromContext(ctx)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return 0, nil, 0, errors.Wrap(errors.ErrUpstream, err, "failed to read response body")
 	}
 
-	// Check for HTTP errors
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, errors.HandleAPIError(resp.StatusCode, responseBody)
-	}
+	return resp.StatusCode, responseBody, parseRetryAfter(resp.Header.Get("Retry-After")), nil
+}
+
+// bytesDownloadedReportInterval is how often (in bytes) download progress is reported
+const bytesDownloadedReportInterval = 1 << 20
 
-	return responseBody, nil
+// progressReader reports bytes read so far every bytesDownloadedReportInterval bytes and once at EOF
+type progressReader struct {
+	reader       io.Reader
+	reporter     progress.Reporter
+	read         int64
+	lastReported int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read-r.lastReported >= bytesDownloadedReportInterval || (err == io.EOF && r.read != r.lastReported) {
+		r.lastReported = r.read
+		r.reporter.Report(progress.Event{Stage: progress.StageBytesDownloaded, Count: r.read})
+	}
+	return n, err
 }
 
 func (c *FigmaClient) extractFileKeyFromURL(input string) string {
@@ -171,21 +344,18 @@ func (c *FigmaClient) extractFileKeyFromURL(input string) string {
 // ValidateAPIToken checks if the API token is valid by making a simple request
 func (c *FigmaClient) ValidateAPIToken(apiToken string) error {
 	if apiToken == "" {
-		return fmt.Errorf("Figma token is required")
+		return errors.InvalidInput("Figma token is required")
 	}
 
 	endpoint := fmt.Sprintf("%s/me", c.baseURL)
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, "figma_token", apiToken)
 
-	_, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	_, err := c.makeRequest(ctx, EndpointMe, "GET", endpoint, nil)
 	if err != nil {
-		// For token validation, provide more specific error messages
-		if strings.Contains(err.Error(), "forbidden") {
-			return fmt.Errorf("invalid or expired Figma token")
-		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			return fmt.Errorf("invalid Figma token format or token not recognized")
+		// For token validation, provide more specific error messages. /me only refuses tokens, whatever the status
+		if errors.Is(err, errors.ErrUnauthorized) || errors.Is(err, errors.ErrForbidden) {
+			return errors.Wrap(errors.ErrUnauthorized, err, "invalid or expired Figma token")
 		}
 		return fmt.Errorf("token validation failed: %w", err)
 	}
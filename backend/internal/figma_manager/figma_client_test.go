@@ -0,0 +1,124 @@
+package figma_manager_test
+
+import (
+	"context"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+	"time"
+)
+
+func TestFigmaClient_Retry(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	newClient := func(server *figmatest.Server, config figma_manager.RetryConfig) *figma_manager.FigmaClient {
+		return figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRetryConfig(config)
+	}
+
+	t.Run("Retries 5xx and 429 until success", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files", 503, 1, nil)
+		server.FailNext("/v1/files", 429, 1, map[string]string{"Retry-After": "0"})
+
+		if _, err := newClient(server, fastRetryConfig).GetFile(ctx, figmatest.SampleFileKey); err != nil {
+			t.Fatalf("Expected request to succeed after retries, got: %v", err)
+		}
+		if count := server.RequestCount("/v1/files"); count != 3 {
+			t.Errorf("Expected 3 attempts, got %d", count)
+		}
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files", 500, 10, nil)
+
+		if _, err := newClient(server, fastRetryConfig).GetFile(ctx, figmatest.SampleFileKey); err == nil {
+			t.Fatal("Expected request to fail")
+		}
+		if count := server.RequestCount("/v1/files"); count != fastRetryConfig.MaxAttempts {
+			t.Errorf("Expected %d attempts, got %d", fastRetryConfig.MaxAttempts, count)
+		}
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+
+		if _, err := newClient(server, fastRetryConfig).GetFile(ctx, "UnknownFileKey123"); err == nil {
+			t.Fatal("Expected request to fail")
+		}
+		if count := server.RequestCount("/v1/files"); count != 1 {
+			t.Errorf("Expected a single attempt for a 404, got %d", count)
+		}
+	})
+
+	t.Run("Honors Retry-After within the retry budget", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files", 429, 1, map[string]string{"Retry-After": "30"})
+
+		config := fastRetryConfig
+		config.MaxDelay = time.Minute
+		config.MaxRetryTime = time.Second
+
+		start := time.Now()
+		_, err := newClient(server, config).GetFile(ctx, figmatest.SampleFileKey)
+		if !errors.IsRateLimited(err) {
+			t.Fatalf("Expected a rate limit error when Retry-After exceeds the retry budget, got %v", err)
+		}
+		if retryAfter := errors.RetryAfter(err); retryAfter != 30*time.Second {
+			t.Errorf("Expected the error to carry Figma's 30s Retry-After, got %s", retryAfter)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected to give up without waiting, took %s", elapsed)
+		}
+		if count := server.RequestCount("/v1/files"); count != 1 {
+			t.Errorf("Expected a single attempt, got %d", count)
+		}
+	})
+
+	t.Run("Waits the full Retry-After", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files", 429, 1, map[string]string{"Retry-After": "1"})
+
+		// Retry-After is longer than MaxDelay, which only bounds our own backoff
+		config := fastRetryConfig
+		config.MaxRetryTime = 5 * time.Second
+
+		start := time.Now()
+		if _, err := newClient(server, config).GetFile(ctx, figmatest.SampleFileKey); err != nil {
+			t.Fatalf("Expected request to succeed after waiting, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("Expected to wait the 1s Retry-After before retrying, retried after %s", elapsed)
+		}
+		if count := server.RequestCount("/v1/files"); count != 2 {
+			t.Errorf("Expected 2 attempts, got %d", count)
+		}
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files", 429, 1, map[string]string{"Retry-After": "5"})
+
+		config := fastRetryConfig
+		config.MaxDelay = time.Minute
+		config.MaxRetryTime = time.Minute
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := newClient(server, config).GetFile(ctx, figmatest.SampleFileKey); err == nil {
+			t.Fatal("Expected request to fail on context cancellation")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected cancellation to interrupt the wait, took %s", elapsed)
+		}
+	})
+}
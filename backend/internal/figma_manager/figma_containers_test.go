@@ -0,0 +1,40 @@
+package figma_manager_test
+
+import (
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+)
+
+func TestFigmaParser_InstanceContainers(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	type containers struct {
+		parentInstance, containingComponent string
+	}
+	found := make(map[string]containers)
+	for _, instance := range parsedData.Instances {
+		found[instance.NodeID] = containers{instance.ParentInstanceNodeID, instance.ContainingComponentNodeID}
+	}
+
+	tests := []struct {
+		name     string
+		nodeID   string
+		expected containers
+	}{
+		{"top level instance", "1:2", containers{}},
+		{"instance in a component definition", "3:2", containers{"", "3:1"}},
+		{"instance nested in an instance", "I1:4;3:2", containers{"1:4", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := found[tt.nodeID]; !ok || got != tt.expected {
+				t.Errorf("Expected %s in %+v, got %+v", tt.nodeID, tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package figma_manager
+
+import "parser-service/models"
+
+// addExternalComponentStubs appends an external component stub for every component referenced by an instance
+// but found neither in the components map nor in the document, named after its first instance
+func (p *FigmaParser) addExternalComponentStubs(document Node, components []models.Component) []models.Component {
+	known := make(map[string]bool, len(components))
+	for _, component := range components {
+		known[component.NodeID] = true
+	}
+
+	var walk func(node Node)
+	walk = func(node Node) {
+		if node.Type == "INSTANCE" && node.ComponentID != "" && !known[node.ComponentID] {
+			known[node.ComponentID] = true
+			components = append(components, models.Component{
+				NodeID:     node.ComponentID,
+				Name:       node.Name,
+				Type:       "COMPONENT",
+				IsExternal: true,
+				Active:     true,
+			})
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(document)
+
+	return components
+}
@@ -0,0 +1,67 @@
+package figma_manager_test
+
+import (
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaParser_ExternalComponents(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+
+	t.Run("remote components of the components map", func(t *testing.T) {
+		parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+		if err != nil {
+			t.Fatalf("Failed to parse sample file: %v", err)
+		}
+
+		components := make(map[string]models.Component)
+		for _, component := range parsedData.Components {
+			components[component.NodeID] = component
+		}
+		if avatar := components["9:9"]; !avatar.IsExternal || avatar.Key != "avatar-remote-key" {
+			t.Errorf("Expected Avatar to be external with its key, got %+v", avatar)
+		}
+		if card := components["3:1"]; card.IsExternal || card.Key != "card-key" {
+			t.Errorf("Expected Card to be local with its key, got %+v", card)
+		}
+
+		for _, instance := range parsedData.Instances {
+			if instance.NodeID == "1:6" {
+				if component := parsedData.Components[instance.ComponentID-1]; component.NodeID != "9:9" {
+					t.Errorf("Expected the avatar instance linked to 9:9, got %s", component.NodeID)
+				}
+				return
+			}
+		}
+		t.Error("Expected the avatar instance to be kept")
+	})
+
+	t.Run("components missing from the file get a stub", func(t *testing.T) {
+		response := &figma_manager.FigmaAPIResponse{
+			Name: "Library consumer",
+			Document: figma_manager.Node{ID: "0:0", Type: "DOCUMENT", Children: []figma_manager.Node{
+				{ID: "0:1", Type: "CANVAS", Name: "Page", Children: []figma_manager.Node{
+					{ID: "1:1", Type: "INSTANCE", Name: "Badge", ComponentID: "7:7"},
+					{ID: "1:2", Type: "INSTANCE", Name: "Badge 2", ComponentID: "7:7"},
+				}},
+			}},
+		}
+		parsedData, err := parser.ParseFile(response, "consumer", "")
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+
+		if len(parsedData.Components) != 1 {
+			t.Fatalf("Expected a single stub, got %+v", parsedData.Components)
+		}
+		stub := parsedData.Components[0]
+		if stub.NodeID != "7:7" || stub.Name != "Badge" || !stub.IsExternal || stub.PageNodeID != "" {
+			t.Errorf("Expected an external Badge stub off any page, got %+v", stub)
+		}
+		if len(parsedData.Instances) != 2 {
+			t.Errorf("Expected both instances to be kept, got %d", len(parsedData.Instances))
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaParser_ShapeGeometry(t *testing.T) {
+	response := &figma_manager.FigmaAPIResponse{
+		Name: "Icons",
+		Document: figma_manager.Node{ID: "0:0", Type: "DOCUMENT", Children: []figma_manager.Node{
+			{ID: "0:1", Type: "CANVAS", Name: "Icons", Children: []figma_manager.Node{
+				{ID: "1:1", Type: "FRAME", Name: "Check", RelativeTransform: [][]float64{{1, 0, 100}, {0, 1, 50}},
+					AbsoluteBoundingBox: &figma_manager.BoundingBox{X: 100, Y: 50, Width: 24, Height: 24},
+					FillGeometry:        []figma_manager.Path{{Path: "M0 0L24 0L24 24L0 24Z", WindingRule: "NONZERO"}},
+					Children: []figma_manager.Node{
+						// groups have no coordinates of their own, their children are relative to the frame
+						{ID: "1:2", Type: "GROUP", Name: "Group", RelativeTransform: [][]float64{{1, 0, 4}, {0, 1, 6}}, Children: []figma_manager.Node{
+							{ID: "1:3", Type: "VECTOR", Name: "Tick", RelativeTransform: [][]float64{{0, -1, 4}, {1, 0, 18}},
+								FillGeometry:   []figma_manager.Path{{Path: "M0 6L6 12L16 0", WindingRule: "EVENODD"}},
+								StrokeGeometry: []figma_manager.Path{{Path: "M0 0L16 0", WindingRule: "NONZERO"}}},
+						}},
+					}},
+			}},
+		}},
+	}
+	parsedData, err := figma_manager.NewFigmaParser().ParseFile(response, "icons", "")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	properties := make(map[string]models.NodeProperties)
+	for _, node := range parsedData.Nodes {
+		var decoded models.NodeProperties
+		if err := json.Unmarshal(node.Properties, &decoded); err != nil {
+			t.Fatalf("Failed to decode the properties of %s: %v", node.NodeID, err)
+		}
+		properties[node.NodeID] = decoded
+	}
+
+	tick := properties["1:3"]
+	if len(tick.FillGeometry) != 1 || tick.FillGeometry[0].Path != "M0 6L6 12L16 0" || tick.FillGeometry[0].WindingRule != "EVENODD" {
+		t.Errorf("Expected the tick fill geometry, got %+v", tick.FillGeometry)
+	}
+	if len(tick.StrokeGeometry) != 1 || tick.StrokeGeometry[0].Path != "M0 0L16 0" {
+		t.Errorf("Expected the tick stroke geometry, got %+v", tick.StrokeGeometry)
+	}
+	expected := models.Transform{{0, -1, 104}, {1, 0, 68}}
+	if tick.Transform == nil || *tick.Transform != expected {
+		t.Errorf("Expected the tick placed by the frame transform, not the group one, got %v", tick.Transform)
+	}
+
+	if frame := properties["1:1"]; frame.FillGeometry != nil || frame.Transform != nil {
+		t.Errorf("Expected geometry to be kept for shapes only, got %+v", frame)
+	}
+}
@@ -0,0 +1,55 @@
+package figma_manager_test
+
+import (
+	"bytes"
+	"context"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+)
+
+func TestFigmaManager_GetImageFills(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	getImageFills := func(manager *figma_manager.FigmaManager, fileKey string) (map[string][]byte, error) {
+		images := make(map[string][]byte)
+		err := manager.GetImageFills(ctx, fileKey, []string{"card-cover-ref", "unknown-ref"}, func(imageRef string, image []byte) error {
+			images[imageRef] = image
+			return nil
+		})
+		return images, err
+	}
+
+	images, err := getImageFills(newTestManager(server), figmatest.SampleFileKey)
+	if err != nil {
+		t.Fatalf("Failed to get image fills: %v", err)
+	}
+
+	if len(images) != 1 {
+		t.Fatalf("Expected only the image Figma knows about, got %d images", len(images))
+	}
+	if !bytes.HasPrefix(images["card-cover-ref"], []byte("\x89PNG")) {
+		t.Errorf("Expected the card cover PNG, got %q", images["card-cover-ref"])
+	}
+
+	t.Run("images over the size limit are skipped", func(t *testing.T) {
+		client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRetryConfig(fastRetryConfig).WithMaxImageSize(16)
+		images, err := getImageFills(figma_manager.NewFigmaManagerWithClient(client), figmatest.SampleFileKey)
+		if err != nil {
+			t.Fatalf("Failed to get image fills: %v", err)
+		}
+		if len(images) != 0 {
+			t.Errorf("Expected the card cover to exceed the 16 bytes limit, got %d images", len(images))
+		}
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		_, err := getImageFills(newTestManager(server), "UnknownFileKey")
+		if !errors.Is(err, errors.ErrNotFound) {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+	})
+}
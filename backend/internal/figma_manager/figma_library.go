@@ -0,0 +1,170 @@
+package figma_manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"parser-service/internal/errors"
+	"strconv"
+)
+
+// teamLibraryPageSize is how many items are requested per page from the team library endpoints
+const teamLibraryPageSize = 100
+
+// PublishedComponent is a component or component set published to a team library,
+// as returned by GET /v1/teams/:team_id/components and /component_sets
+type PublishedComponent struct {
+	Key             string           `json:"key"`
+	FileKey         string           `json:"file_key"`
+	NodeID          string           `json:"node_id"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	ThumbnailURL    string           `json:"thumbnail_url"`
+	CreatedAt       string           `json:"created_at"`
+	UpdatedAt       string           `json:"updated_at"`
+	ContainingFrame *ContainingFrame `json:"containing_frame,omitempty"`
+}
+
+// ContainingFrame locates a published component in its library file
+type ContainingFrame struct {
+	NodeID               string                `json:"nodeId"`
+	Name                 string                `json:"name"`
+	PageID               string                `json:"pageId"`
+	PageName             string                `json:"pageName"`
+	ContainingStateGroup *ContainingStateGroup `json:"containingStateGroup,omitempty"` // component set of a variant
+}
+
+type ContainingStateGroup struct {
+	NodeID string `json:"nodeId"`
+	Name   string `json:"name"`
+}
+
+// PublishedStyle is a style published to a team library, as returned by GET /v1/teams/:team_id/styles
+type PublishedStyle struct {
+	Key          string `json:"key"`
+	FileKey      string `json:"file_key"`
+	NodeID       string `json:"node_id"`
+	StyleType    string `json:"style_type"` // FILL, TEXT, EFFECT or GRID
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// TeamLibrary is everything a team published: components, component sets and styles
+type TeamLibrary struct {
+	TeamID        string
+	Components    []PublishedComponent
+	ComponentSets []PublishedComponent
+	Styles        []PublishedStyle
+}
+
+// GetTeamComponents retrieves every component published by a team, following the pagination cursor
+func (c *FigmaClient) GetTeamComponents(ctx context.Context, teamID string) ([]PublishedComponent, error) {
+	var components []PublishedComponent
+	err := c.getTeamLibraryPages(ctx, teamID, "components", func(meta json.RawMessage) (int, error) {
+		var page struct {
+			Components []PublishedComponent `json:"components"`
+		}
+		if err := json.Unmarshal(meta, &page); err != nil {
+			return 0, err
+		}
+		components = append(components, page.Components...)
+		return len(page.Components), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// GetTeamComponentSets retrieves every component set published by a team, following the pagination cursor
+func (c *FigmaClient) GetTeamComponentSets(ctx context.Context, teamID string) ([]PublishedComponent, error) {
+	var componentSets []PublishedComponent
+	err := c.getTeamLibraryPages(ctx, teamID, "component_sets", func(meta json.RawMessage) (int, error) {
+		var page struct {
+			ComponentSets []PublishedComponent `json:"component_sets"`
+		}
+		if err := json.Unmarshal(meta, &page); err != nil {
+			return 0, err
+		}
+		componentSets = append(componentSets, page.ComponentSets...)
+		return len(page.ComponentSets), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return componentSets, nil
+}
+
+// GetTeamStyles retrieves every style published by a team, following the pagination cursor
+func (c *FigmaClient) GetTeamStyles(ctx context.Context, teamID string) ([]PublishedStyle, error) {
+	var styles []PublishedStyle
+	err := c.getTeamLibraryPages(ctx, teamID, "styles", func(meta json.RawMessage) (int, error) {
+		var page struct {
+			Styles []PublishedStyle `json:"styles"`
+		}
+		if err := json.Unmarshal(meta, &page); err != nil {
+			return 0, err
+		}
+		styles = append(styles, page.Styles...)
+		return len(page.Styles), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return styles, nil
+}
+
+// getTeamLibraryPages requests /teams/:team_id/:resource page after page, handing the "meta" object of every
+// response to decodePage, which returns how many items the page had. It stops on an empty page or when the
+// "after" cursor is missing or doesn't move forward
+func (c *FigmaClient) getTeamLibraryPages(ctx context.Context, teamID, resource string, decodePage func(meta json.RawMessage) (int, error)) error {
+	if teamID == "" {
+		return errors.InvalidInput("team ID cannot be empty")
+	}
+
+	var after int64
+	for {
+		params := url.Values{}
+		params.Add("page_size", strconv.Itoa(teamLibraryPageSize))
+		if after > 0 {
+			params.Add("after", strconv.FormatInt(after, 10))
+		}
+		endpoint := fmt.Sprintf("%s/teams/%s/%s?%s", c.baseURL, url.PathEscape(teamID), resource, params.Encode())
+
+		response, err := c.makeRequest(ctx, EndpointTeams, "GET", endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get team %s from Figma API: %w", resource, err)
+		}
+
+		var envelope struct {
+			Meta json.RawMessage `json:"meta"`
+		}
+		var cursor struct {
+			Cursor struct {
+				After int64 `json:"after"`
+			} `json:"cursor"`
+		}
+		if err := json.Unmarshal(response, &envelope); err != nil {
+			return errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma team %s response", resource)
+		}
+		if len(envelope.Meta) == 0 {
+			return errors.Upstream("Figma team %s response has no meta", resource)
+		}
+		if err := json.Unmarshal(envelope.Meta, &cursor); err != nil {
+			return errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma team %s cursor", resource)
+		}
+		count, err := decodePage(envelope.Meta)
+		if err != nil {
+			return errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma team %s response", resource)
+		}
+
+		if count == 0 || cursor.Cursor.After <= after {
+			return nil
+		}
+		after = cursor.Cursor.After
+	}
+}
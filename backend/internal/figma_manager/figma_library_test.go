@@ -0,0 +1,61 @@
+package figma_manager_test
+
+import (
+	"context"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+)
+
+// newLibraryTestManager is newTestManager without client side throttling, a team library takes many paged requests
+func newLibraryTestManager(server *figmatest.Server) *figma_manager.FigmaManager {
+	client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRetryConfig(fastRetryConfig).WithRateLimiter(nil)
+	return figma_manager.NewFigmaManagerWithClient(client)
+}
+
+func TestFigmaManager_GetTeamLibrary(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	library, err := newLibraryTestManager(server).GetTeamLibrary(ctx, figmatest.SampleTeamID)
+	if err != nil {
+		t.Fatalf("Failed to get team library: %v", err)
+	}
+
+	if len(library.Components) != 3 || len(library.ComponentSets) != 1 || len(library.Styles) != 2 {
+		t.Fatalf("Expected 3 components, 1 component set and 2 styles, got %d, %d and %d",
+			len(library.Components), len(library.ComponentSets), len(library.Styles))
+	}
+	avatar := library.Components[0]
+	if avatar.Key != "avatar-remote-key" || avatar.FileKey != "LibraryFileKey456" || avatar.ContainingFrame == nil || avatar.ContainingFrame.PageName != "Identity" {
+		t.Errorf("Expected the Avatar component with its file and page, got %+v", avatar)
+	}
+	if star := library.Components[1]; star.ContainingFrame == nil || star.ContainingFrame.ContainingStateGroup == nil || star.ContainingFrame.ContainingStateGroup.NodeID != "9:19" {
+		t.Errorf("Expected the Star variant in the Icon set, got %+v", star)
+	}
+	if library.Styles[0].StyleType != "FILL" || library.Styles[0].Name != "Brand/Blue" {
+		t.Errorf("Expected the Brand/Blue fill style first, got %+v", library.Styles[0])
+	}
+
+	// 3 components served 2 per page: two full pages, then an empty one ending the pagination
+	path := "/v1/teams/" + figmatest.SampleTeamID + "/components"
+	if count := server.RequestCount(path); count != 3 {
+		t.Errorf("Expected 3 requests to %s, got %d", path, count)
+	}
+}
+
+func TestFigmaManager_GetTeamLibrary_Errors(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+	manager := newLibraryTestManager(server)
+
+	if _, err := manager.GetTeamLibrary(ctx, ""); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Expected an invalid input error without team ID, got %v", err)
+	}
+	if _, err := manager.GetTeamLibrary(ctx, "999"); !errors.Is(err, errors.ErrNotFound) {
+		t.Errorf("Expected a not found error for an unknown team, got %v", err)
+	}
+}
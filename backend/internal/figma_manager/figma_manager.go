@@ -2,7 +2,12 @@ package figma_manager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"parser-service/internal/errors"
+	"parser-service/internal/progress"
 	"parser-service/models"
 	"regexp"
 	"strings"
@@ -13,10 +18,14 @@ type IFigmaManager interface {
 	ParseFigmaFileFromURL(ctx context.Context, figmaURL string) (*ParsedFigmaData, error)
 	ParseFigmaFileFromKey(ctx context.Context, fileKey string) (*ParsedFigmaData, error)
 	ParseFigmaFileWithImages(ctx context.Context, fileKey string) (*ParsedFigmaData, map[string]string, error)
+	ParseFigmaFileFromJSON(ctx context.Context, fileJSON io.Reader, figmaURL string) (*ParsedFigmaData, error)
 
 	ExtractComponentsFromFile(ctx context.Context, fileKey string) ([]models.Component, error)
 	ExtractInstancesFromFile(ctx context.Context, fileKey string) ([]models.Instance, error)
 	ValidateFigmaToken(token string) error
+
+	GetTeamLibrary(ctx context.Context, teamID string) (*TeamLibrary, error)
+	GetImageFills(ctx context.Context, fileKey string, imageRefs []string, write func(imageRef string, image []byte) error) error
 }
 
 // FigmaManager implements the IFigmaManager interface
@@ -26,12 +35,14 @@ type FigmaManager struct {
 }
 
 func NewFigmaManager() *FigmaManager {
-	client := NewFigmaClient()
-	parser := NewFigmaParser()
+	return NewFigmaManagerWithClient(NewFigmaClient())
+}
 
+// NewFigmaManagerWithClient creates a manager on top of a preconfigured client, e.g. one pointing to a fake Figma server
+func NewFigmaManagerWithClient(client *FigmaClient) *FigmaManager {
 	return &FigmaManager{
 		client: client,
-		parser: parser,
+		parser: NewFigmaParser(),
 	}
 }
 
@@ -44,13 +55,17 @@ func (m *FigmaManager) ParseFigmaFileFromURL(ctx context.Context, figmaURL strin
 	}
 
 	// Get file data from Figma API
+	progress.Report(ctx, progress.Event{Stage: progress.StageFetchStarted, Message: fileKey})
 	apiResponse, err := m.client.GetFile(ctx, fileKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file from Figma API: %w", err)
 	}
+	if apiResponse.Variables, err = m.getFileVariables(ctx, fileKey); err != nil {
+		return nil, err
+	}
 
 	// Parse the API response into our models, passing the original URL
-	parsedData, err := m.parser.ParseFile(apiResponse, fileKey, figmaURL)
+	parsedData, err := m.parser.ParseFileWithProgress(apiResponse, fileKey, figmaURL, progress.FromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file data: %w", err)
 	}
@@ -61,17 +76,21 @@ func (m *FigmaManager) ParseFigmaFileFromURL(ctx context.Context, figmaURL strin
 // ParseFigmaFileFromKey parses a Figma file from file key and returns structured data
 func (m *FigmaManager) ParseFigmaFileFromKey(ctx context.Context, fileKey string) (*ParsedFigmaData, error) {
 	if fileKey == "" {
-		return nil, fmt.Errorf("file key cannot be empty")
+		return nil, errors.InvalidInput("file key cannot be empty")
 	}
 
 	// Get file data from Figma API
+	progress.Report(ctx, progress.Event{Stage: progress.StageFetchStarted, Message: fileKey})
 	apiResponse, err := m.client.GetFile(ctx, fileKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file from Figma API: %w", err)
 	}
+	if apiResponse.Variables, err = m.getFileVariables(ctx, fileKey); err != nil {
+		return nil, err
+	}
 
 	// original URL not available when parsing from key only
-	parsedData, err := m.parser.ParseFile(apiResponse, fileKey, "")
+	parsedData, err := m.parser.ParseFileWithProgress(apiResponse, fileKey, "", progress.FromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file data: %w", err)
 	}
@@ -79,10 +98,65 @@ func (m *FigmaManager) ParseFigmaFileFromKey(ctx context.Context, fileKey string
 	return parsedData, nil
 }
 
+// ParseFigmaFileFromJSON parses a saved GET /v1/files response body without calling the Figma API.
+// figmaURL is optional and only used to fill in the file key and URL of the parsed file
+func (m *FigmaManager) ParseFigmaFileFromJSON(ctx context.Context, fileJSON io.Reader, figmaURL string) (*ParsedFigmaData, error) {
+	var fileKey string
+	if figmaURL != "" {
+		var err error
+		fileKey, err = m.extractFileKeyFromURL(figmaURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract file key from URL: %w", err)
+		}
+	}
+
+	// decoded as it is read, so a large export isn't held in memory twice
+	var apiResponse FigmaAPIResponse
+	if err := json.NewDecoder(fileJSON).Decode(&apiResponse); err != nil {
+		if err == io.EOF {
+			return nil, errors.InvalidInput("file JSON cannot be empty")
+		}
+		return nil, errors.Wrap(errors.ErrInvalidInput, err, "failed to decode Figma file JSON")
+	}
+
+	parsedData, err := m.parser.ParseFileWithProgress(&apiResponse, fileKey, figmaURL, progress.FromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file data: %w", err)
+	}
+
+	return parsedData, nil
+}
+
+// getFileVariables fetches the local and published variables of a file. The variables endpoints need a Figma
+// Enterprise plan and the file_variables:read scope, and variables are an extra on top of the file, so whenever Figma
+// refuses or fails them (including rate limits and 5xx after retries) the file is parsed without variables. Only a
+// cancelled request is an error
+func (m *FigmaManager) getFileVariables(ctx context.Context, fileKey string) (*FileVariables, error) {
+	local, err := m.client.GetLocalVariables(ctx, fileKey)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Parsing Figma file %s without variables: %v", fileKey, err)
+		return nil, nil
+	}
+
+	published, err := m.client.GetPublishedVariables(ctx, fileKey)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Parsing Figma file %s without published variables: %v", fileKey, err)
+		published = nil
+	}
+
+	return &FileVariables{Local: local, Published: published}, nil
+}
+
 // ExtractComponentsFromFile extracts only components from a Figma file
 func (m *FigmaManager) ExtractComponentsFromFile(ctx context.Context, fileKey string) ([]models.Component, error) {
 	if fileKey == "" {
-		return nil, fmt.Errorf("file key cannot be empty")
+		return nil, errors.InvalidInput("file key cannot be empty")
 	}
 
 	// Get file data from Figma API
@@ -103,7 +177,7 @@ func (m *FigmaManager) ExtractComponentsFromFile(ctx context.Context, fileKey st
 // ExtractInstancesFromFile extracts only instances from a Figma file
 func (m *FigmaManager) ExtractInstancesFromFile(ctx context.Context, fileKey string) ([]models.Instance, error) {
 	if fileKey == "" {
-		return nil, fmt.Errorf("file key cannot be empty")
+		return nil, errors.InvalidInput("file key cannot be empty")
 	}
 
 	// Get file data from Figma API
@@ -130,7 +204,7 @@ func (m *FigmaManager) ExtractInstancesFromFile(ctx context.Context, fileKey str
 // extractFileKeyFromURL extracts the file key from a Figma URL
 func (m *FigmaManager) extractFileKeyFromURL(figmaURL string) (string, error) {
 	if figmaURL == "" {
-		return "", fmt.Errorf("URL cannot be empty")
+		return "", errors.InvalidInput("URL cannot be empty")
 	}
 
 	// Clean the URL
@@ -154,7 +228,7 @@ func (m *FigmaManager) extractFileKeyFromURL(figmaURL string) (string, error) {
 		return figmaURL, nil
 	}
 
-	return "", fmt.Errorf("invalid Figma URL format: %s", figmaURL)
+	return "", errors.InvalidInput("invalid Figma URL format: %s", figmaURL)
 }
 
 func isValidFileKey(key string) bool {
@@ -203,9 +277,66 @@ func (m *FigmaManager) ParseFigmaFileWithImages(ctx context.Context, fileKey str
 	return parsedData, images, nil
 }
 
+// GetImageFills downloads the images of the given image fill references, handing them to write one at a time so only
+// one is held in memory. Images Figma doesn't know about or that fail to download are skipped, an error from write
+// stops the downloads
+func (m *FigmaManager) GetImageFills(ctx context.Context, fileKey string, imageRefs []string, write func(imageRef string, image []byte) error) error {
+	urls, err := m.client.GetImageFills(ctx, fileKey)
+	if err != nil {
+		return err
+	}
+
+	for _, imageRef := range imageRefs {
+		imageURL, exists := urls[imageRef]
+		if !exists || imageURL == "" {
+			log.Printf("Figma has no image for image fill %s of file %s, skipping it", imageRef, fileKey)
+			continue
+		}
+		image, err := m.client.DownloadImage(ctx, imageURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Could not download image fill %s of file %s, skipping it: %v", imageRef, fileKey, err)
+			continue
+		}
+		if err := write(imageRef, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTeamLibrary retrieves the components, component sets and styles a team published to its library
+func (m *FigmaManager) GetTeamLibrary(ctx context.Context, teamID string) (*TeamLibrary, error) {
+	if teamID == "" {
+		return nil, errors.InvalidInput("team ID cannot be empty")
+	}
+
+	components, err := m.client.GetTeamComponents(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	componentSets, err := m.client.GetTeamComponentSets(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	styles, err := m.client.GetTeamStyles(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeamLibrary{
+		TeamID:        teamID,
+		Components:    components,
+		ComponentSets: componentSets,
+		Styles:        styles,
+	}, nil
+}
+
 func (m *FigmaManager) ValidateFigmaToken(token string) error {
 	if token == "" {
-		return fmt.Errorf("Figma token cannot be empty")
+		return errors.InvalidInput("Figma token cannot be empty")
 	}
 
 	// Use the client to validate the token
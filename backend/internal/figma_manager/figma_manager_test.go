@@ -1,23 +1,40 @@
 package figma_manager_test
 
 import (
+	"bytes"
 	"context"
-	"os"
+	"parser-service/internal/errors"
 	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"strings"
 	"testing"
+	"time"
 )
 
-// Test with sample Figma data
+// fastRetryConfig keeps retry tests quick
+var fastRetryConfig = figma_manager.RetryConfig{
+	MaxAttempts:  3,
+	BaseDelay:    time.Millisecond,
+	MaxDelay:     10 * time.Millisecond,
+	MaxRetryTime: time.Second,
+}
+
+// newTestManager builds a manager talking to the fake Figma server
+func newTestManager(server *figmatest.Server) *figma_manager.FigmaManager {
+	client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRetryConfig(fastRetryConfig)
+	return figma_manager.NewFigmaManagerWithClient(client)
+}
+
+// Test with sample Figma data served by the fake Figma API
 func TestFigmaManager_Integration_SampleData(t *testing.T) {
-	apiToken := os.Getenv("FIGMA_API_TOKEN")
-	if apiToken == "" {
-		t.Fatal("FIGMA_API_TOKEN environment variable not set")
-	}
-	ctx := context.WithValue(context.Background(), "figma_token", apiToken)
-	figmaURL := "https://www.figma.com/design/DNCLfE7Tf8A0mudOOLZUYx/Locofy.ai.test?t=94QjDVDsuj5cHJEU-1"
+	server := figmatest.NewServer()
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+	figmaURL := figmatest.SampleFileURL + "?t=94QjDVDsuj5cHJEU-1"
 
 	t.Run("Test URL Extraction", func(t *testing.T) {
-		manager := figma_manager.NewFigmaManager()
+		manager := newTestManager(server)
 
 		// Test parsing through ParseFigmaFileFromURL to verify URL extraction
 		parsedData, err := manager.ParseFigmaFileFromURL(ctx, figmaURL)
@@ -29,7 +46,7 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 			t.Fatal("Expected file data to be non-nil")
 		}
 
-		expectedFileKey := "DNCLfE7Tf8A0mudOOLZUYx"
+		expectedFileKey := figmatest.SampleFileKey
 		if parsedData.File.FileKey != expectedFileKey {
 			t.Errorf("Expected file key %s, got %s", expectedFileKey, parsedData.File.FileKey)
 		}
@@ -38,7 +55,7 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 	})
 
 	t.Run("Test Complete File Parsing", func(t *testing.T) {
-		manager := figma_manager.NewFigmaManager()
+		manager := newTestManager(server)
 
 		parsedData, err := manager.ParseFigmaFileFromURL(ctx, figmaURL)
 		if err != nil {
@@ -89,8 +106,8 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 	})
 
 	t.Run("Test Components Only Extraction", func(t *testing.T) {
-		manager := figma_manager.NewFigmaManager()
-		fileKey := "DNCLfE7Tf8A0mudOOLZUYx"
+		manager := newTestManager(server)
+		fileKey := figmatest.SampleFileKey
 
 		components, err := manager.ExtractComponentsFromFile(ctx, fileKey)
 		if err != nil {
@@ -98,6 +115,9 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 		}
 
 		t.Logf("Extracted %d components only", len(components))
+		if len(components) == 0 {
+			t.Error("Expected components to be extracted")
+		}
 
 		for _, component := range components {
 			if component.NodeID == "" {
@@ -113,8 +133,8 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 	})
 
 	t.Run("Test Instances Only Extraction", func(t *testing.T) {
-		manager := figma_manager.NewFigmaManager()
-		fileKey := "DNCLfE7Tf8A0mudOOLZUYx"
+		manager := newTestManager(server)
+		fileKey := figmatest.SampleFileKey
 
 		// First extract components to see what's available
 		components, err := manager.ExtractComponentsFromFile(ctx, fileKey)
@@ -137,6 +157,9 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 		}
 
 		t.Logf("Extracted %d instances only", len(instances))
+		if len(instances) == 0 {
+			t.Error("Expected instances to be extracted")
+		}
 
 		// Validate instance properties
 		for _, instance := range instances {
@@ -170,8 +193,8 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 	})
 
 	t.Run("Test File Parsing With Images", func(t *testing.T) {
-		manager := figma_manager.NewFigmaManager()
-		fileKey := "DNCLfE7Tf8A0mudOOLZUYx"
+		manager := newTestManager(server)
+		fileKey := figmatest.SampleFileKey
 
 		parsedData, images, err := manager.ParseFigmaFileWithImages(ctx, fileKey)
 		if err != nil {
@@ -182,6 +205,9 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 			t.Error("Expected parsed data to be non-nil")
 		}
 
+		if len(images) == 0 {
+			t.Error("Expected images to be rendered for components and instances")
+		}
 		t.Logf("Parsed file with %d images", len(images))
 
 		// Log image URLs (first few)
@@ -206,6 +232,63 @@ func TestFigmaManager_Integration_SampleData(t *testing.T) {
 	})
 }
 
+func TestFigmaManager_ParseFigmaFileFromJSON(t *testing.T) {
+	// no server and no token, parsing a saved export must not call the Figma API
+	manager := figma_manager.NewFigmaManagerWithClient(figma_manager.NewFigmaClientWithConfig("http://127.0.0.1:0", nil))
+
+	parsedData, err := manager.ParseFigmaFileFromJSON(context.Background(), bytes.NewReader(figmatest.SampleFile()), figmatest.SampleFileURL)
+	if err != nil {
+		t.Fatalf("Failed to parse Figma file JSON: %v", err)
+	}
+	if parsedData.File.FileKey != figmatest.SampleFileKey {
+		t.Errorf("Expected file key %s, got %s", figmatest.SampleFileKey, parsedData.File.FileKey)
+	}
+	if len(parsedData.Components) == 0 || len(parsedData.Instances) == 0 {
+		t.Errorf("Expected components and instances, got %d and %d", len(parsedData.Components), len(parsedData.Instances))
+	}
+
+	if _, err := manager.ParseFigmaFileFromJSON(context.Background(), strings.NewReader("{not json"), ""); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Expected invalid JSON to be rejected as invalid input, got: %v", err)
+	}
+}
+
+func TestFigmaManager_APIErrors(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	manager := newTestManager(server)
+
+	t.Run("Unknown file", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+		if _, err := manager.ParseFigmaFileFromKey(ctx, "UnknownFileKey123"); !errors.Is(err, errors.ErrNotFound) {
+			t.Errorf("Expected not found error for unknown file, got: %v", err)
+		}
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+		if _, err := manager.ParseFigmaFileFromURL(ctx, "https://example.com/not-figma"); !errors.Is(err, errors.ErrInvalidInput) {
+			t.Errorf("Expected invalid input error, got: %v", err)
+		}
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		if err := manager.ValidateFigmaToken("figd_not-a-real-token"); !errors.Is(err, errors.ErrUnauthorized) {
+			t.Errorf("Expected invalid token to be rejected as unauthorized, got: %v", err)
+		}
+		if err := manager.ValidateFigmaToken(figmatest.ValidToken); err != nil {
+			t.Errorf("Expected valid token to be accepted, got: %v", err)
+		}
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		server.FailNext("/v1/files", 500, fastRetryConfig.MaxAttempts, nil)
+		ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+		if _, err := manager.ParseFigmaFileFromKey(ctx, figmatest.SampleFileKey); !errors.Is(err, errors.ErrUpstream) {
+			t.Errorf("Expected upstream error when Figma answers 500, got: %v", err)
+		}
+	})
+}
+
 // Helper function for min
 func min(a, b int) int {
 	if a < b {
@@ -1,14 +1,26 @@
 package figma_manager
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"parser-service/models"
 )
 
 // ParsedFigmaData represents the complete parsed data from a Figma file
 type ParsedFigmaData struct {
-	File       *models.FigmaFile  `json:"file"`
-	Components []models.Component `json:"components"`
-	Instances  []models.Instance  `json:"instances"`
+	File        *models.FigmaFile   `json:"file"`
+	Pages       []models.Page       `json:"pages"`
+	Nodes       []models.Node       `json:"nodes"` // whole document hierarchy in pre-order
+	Components  []models.Component  `json:"components"`
+	Instances   []models.Instance   `json:"instances"`
+	TextNodes   []models.TextNode   `json:"text_nodes"`
+	Styles      []models.Style      `json:"styles"`
+	StyleUsages []models.StyleUsage `json:"style_usages"`
+
+	VariableCollections []models.VariableCollection `json:"variable_collections"`
+	Variables           []models.Variable           `json:"variables"` // with their values by mode
+	VariableBindings    []models.VariableBinding    `json:"variable_bindings"`
 }
 
 // FigmaAPIResponse represents the raw response from Figma API
@@ -18,34 +30,105 @@ type FigmaAPIResponse struct {
 	Document      Node                    `json:"document"`
 	Components    map[string]Component    `json:"components"`
 	ComponentSets map[string]ComponentSet `json:"componentSets"`
+	Styles        map[string]Style        `json:"styles"` // by style ID
+	// Not part of the file response, fetched from the variables endpoints. Nil when the file has no variables available
+	Variables *FileVariables `json:"-"`
 }
 
 // Node represents a node in the Figma document tree
 type Node struct {
-	ID                  string       `json:"id"`
-	Name                string       `json:"name"`
-	Type                string       `json:"type"`
-	Visible             *bool        `json:"visible,omitempty"`
-	ComponentID         string       `json:"componentId,omitempty"`
-	AbsoluteBoundingBox *BoundingBox `json:"absoluteBoundingBox,omitempty"`
-	Children            []Node       `json:"children,omitempty"`
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	Type                 string       `json:"type"`
+	Visible              *bool        `json:"visible,omitempty"`
+	ComponentID          string       `json:"componentId,omitempty"`
+	AbsoluteBoundingBox  *BoundingBox `json:"absoluteBoundingBox,omitempty"`
+	RelativeTransform    [][]float64  `json:"relativeTransform,omitempty"` // 2x3 affine transform relative to the parent
+	Constraints          *Constraints `json:"constraints,omitempty"`
+	BackgroundColor      *Color       `json:"backgroundColor,omitempty"` // set on CANVAS nodes
+	Opacity              *float64     `json:"opacity,omitempty"`
+	BlendMode            string       `json:"blendMode,omitempty"`
+	Fills                []Paint      `json:"fills,omitempty"`
+	Strokes              []Paint      `json:"strokes,omitempty"`
+	StrokeWeight         *float64     `json:"strokeWeight,omitempty"`
+	StrokeAlign          string       `json:"strokeAlign,omitempty"`
+	Effects              []Effect     `json:"effects,omitempty"`
+	CornerRadius         *float64     `json:"cornerRadius,omitempty"`
+	RectangleCornerRadii []float64    `json:"rectangleCornerRadii,omitempty"` // top left, top right, bottom right, bottom left
+	// Auto Layout, container properties
+	LayoutMode            string  `json:"layoutMode,omitempty"`
+	LayoutWrap            string  `json:"layoutWrap,omitempty"`
+	PrimaryAxisAlignItems string  `json:"primaryAxisAlignItems,omitempty"`
+	CounterAxisAlignItems string  `json:"counterAxisAlignItems,omitempty"`
+	ItemSpacing           float64 `json:"itemSpacing,omitempty"`
+	CounterAxisSpacing    float64 `json:"counterAxisSpacing,omitempty"`
+	PaddingLeft           float64 `json:"paddingLeft,omitempty"`
+	PaddingRight          float64 `json:"paddingRight,omitempty"`
+	PaddingTop            float64 `json:"paddingTop,omitempty"`
+	PaddingBottom         float64 `json:"paddingBottom,omitempty"`
+	// Auto Layout, child properties
+	LayoutSizingHorizontal string   `json:"layoutSizingHorizontal,omitempty"`
+	LayoutSizingVertical   string   `json:"layoutSizingVertical,omitempty"`
+	LayoutPositioning      string   `json:"layoutPositioning,omitempty"`
+	LayoutGrow             *float64 `json:"layoutGrow,omitempty"`
+	// Vector paths of shapes in the node coordinates, only sent when the file is requested with geometry=paths.
+	// Strokes are outlined, drawn by filling their path
+	FillGeometry   []Path `json:"fillGeometry,omitempty"`
+	StrokeGeometry []Path `json:"strokeGeometry,omitempty"`
+	// Shared styles applied to the node, style ID by property: fill, stroke, text, effect or grid
+	Styles map[string]string `json:"styles,omitempty"`
+	// Variables bound to the node by property: an alias, a list of aliases (fills, strokes...) or aliases by name
+	BoundVariables map[string]json.RawMessage `json:"boundVariables,omitempty"`
+	// Component properties bound to fields of the node, property name by field: characters, visible or mainComponent
+	ComponentPropertyReferences map[string]string `json:"componentPropertyReferences,omitempty"`
+	// COMPONENT and COMPONENT_SET nodes
+	ComponentPropertyDefinitions map[string]ComponentPropertyDefinition `json:"componentPropertyDefinitions,omitempty"`
+	// INSTANCE nodes
+	ComponentProperties map[string]ComponentProperty `json:"componentProperties,omitempty"`
+	Overrides           []Override                   `json:"overrides,omitempty"`
+	// TEXT nodes
+	Characters              string               `json:"characters,omitempty"`
+	Style                   *TypeStyle           `json:"style,omitempty"`
+	CharacterStyleOverrides []int                `json:"characterStyleOverrides,omitempty"`
+	StyleOverrideTable      map[string]TypeStyle `json:"styleOverrideTable,omitempty"`
+	Children                []Node               `json:"children,omitempty"`
 	// Add other Figma properties as needed
 }
 
 // Component represents a Figma component from the API
 type Component struct {
+	Key            string `json:"key"` // published key, identifies the component across files and libraries
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	ComponentSetID string `json:"componentSetId,omitempty"` // node ID of the set of a variant
+	Remote         bool   `json:"remote"`                   // defined in a library file, not in this one
+	// Add other component properties
+}
+
+// Style represents a shared style of the file styles map
+type Style struct {
+	Key         string `json:"key"`
 	Name        string `json:"name"`
+	StyleType   string `json:"styleType"` // FILL, TEXT, EFFECT or GRID
 	Description string `json:"description"`
-	// Add other component properties
+	Remote      bool   `json:"remote"`
 }
 
 // ComponentSet represents a Figma component set from the API
 type ComponentSet struct {
+	Key         string `json:"key"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Remote      bool   `json:"remote"`
 	// Add other component set properties
 }
 
+// Constraints represents how a node resizes and moves with its parent frame
+type Constraints struct {
+	Vertical   string `json:"vertical"`   // TOP, BOTTOM, CENTER, TOP_BOTTOM or SCALE
+	Horizontal string `json:"horizontal"` // LEFT, RIGHT, CENTER, LEFT_RIGHT or SCALE
+}
+
 // BoundingBox represents position and dimensions
 type BoundingBox struct {
 	X      float64 `json:"x"`
@@ -53,3 +136,109 @@ type BoundingBox struct {
 	Width  float64 `json:"width"`
 	Height float64 `json:"height"`
 }
+
+// Paint represents a fill or stroke of a node
+type Paint struct {
+	Type                    string      `json:"type"`
+	Visible                 *bool       `json:"visible,omitempty"` // defaults to true
+	Opacity                 *float64    `json:"opacity,omitempty"` // defaults to 1
+	BlendMode               string      `json:"blendMode,omitempty"`
+	Color                   *Color      `json:"color,omitempty"`
+	GradientHandlePositions []Vector    `json:"gradientHandlePositions,omitempty"`
+	GradientStops           []ColorStop `json:"gradientStops,omitempty"`
+	ScaleMode               string      `json:"scaleMode,omitempty"`
+	ImageRef                string      `json:"imageRef,omitempty"`
+}
+
+// Path represents an SVG path of the geometry of a node
+type Path struct {
+	Path        string `json:"path"`        // SVG path data
+	WindingRule string `json:"windingRule"` // NONZERO or EVENODD
+}
+
+// ColorStop represents a color stop of a gradient
+type ColorStop struct {
+	Position float64 `json:"position"`
+	Color    Color   `json:"color"`
+}
+
+// Effect represents a shadow or blur applied to a node
+type Effect struct {
+	Type      string  `json:"type"`
+	Visible   bool    `json:"visible"`
+	Radius    float64 `json:"radius"`
+	BlendMode string  `json:"blendMode,omitempty"`
+	Color     *Color  `json:"color,omitempty"`
+	Offset    *Vector `json:"offset,omitempty"`
+	Spread    float64 `json:"spread,omitempty"`
+}
+
+// Vector represents a 2D point or offset
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ComponentPropertyDefinition represents a property exposed by a component or component set
+type ComponentPropertyDefinition struct {
+	Type            string           `json:"type"`
+	DefaultValue    interface{}      `json:"defaultValue"`
+	VariantOptions  []string         `json:"variantOptions,omitempty"`
+	PreferredValues []PreferredValue `json:"preferredValues,omitempty"`
+}
+
+// PreferredValue represents a suggested component for an INSTANCE_SWAP property
+type PreferredValue struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+// ComponentProperty represents the value an instance gives to a property of its component
+type ComponentProperty struct {
+	Type            string           `json:"type"`
+	Value           interface{}      `json:"value"`
+	PreferredValues []PreferredValue `json:"preferredValues,omitempty"`
+}
+
+// Override represents the fields of a node overridden within an instance
+type Override struct {
+	ID               string   `json:"id"`
+	OverriddenFields []string `json:"overriddenFields"`
+}
+
+// TypeStyle represents the typography of a text node, or an entry of its style override table
+type TypeStyle struct {
+	FontFamily                string  `json:"fontFamily,omitempty"`
+	FontPostScriptName        string  `json:"fontPostScriptName,omitempty"`
+	FontWeight                float64 `json:"fontWeight,omitempty"`
+	FontSize                  float64 `json:"fontSize,omitempty"`
+	Italic                    bool    `json:"italic,omitempty"`
+	LineHeightPx              float64 `json:"lineHeightPx,omitempty"`
+	LineHeightPercentFontSize float64 `json:"lineHeightPercentFontSize,omitempty"`
+	LineHeightUnit            string  `json:"lineHeightUnit,omitempty"`
+	LetterSpacing             float64 `json:"letterSpacing,omitempty"`
+	TextCase                  string  `json:"textCase,omitempty"`
+	TextDecoration            string  `json:"textDecoration,omitempty"`
+	TextAlignHorizontal       string  `json:"textAlignHorizontal,omitempty"`
+	TextAlignVertical         string  `json:"textAlignVertical,omitempty"`
+}
+
+// Color represents a Figma RGBA color, channels ranging from 0 to 1
+type Color struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+}
+
+// Hex returns the color as #RRGGBB, or #RRGGBBAA when it isn't fully opaque
+func (c Color) Hex() string {
+	channel := func(value float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, value)) * 255))
+	}
+	hex := fmt.Sprintf("#%02X%02X%02X", channel(c.R), channel(c.G), channel(c.B))
+	if c.A < 1 {
+		hex += fmt.Sprintf("%02X", channel(c.A))
+	}
+	return hex
+}
@@ -1,8 +1,9 @@
 package figma_manager
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
+	"parser-service/internal/progress"
 	"parser-service/models"
 	"time"
 )
@@ -13,13 +14,28 @@ func NewFigmaParser() *FigmaParser {
 	return &FigmaParser{}
 }
 
+// nodesWalkedReportInterval is how often (in nodes) progress is reported while walking the document
+const nodesWalkedReportInterval = 1000
+
 // ParseFile parses a complete Figma API response into our data models
 func (p *FigmaParser) ParseFile(apiResponse *FigmaAPIResponse, fileKey string, originalURL string) (*ParsedFigmaData, error) {
+	return p.ParseFileWithProgress(apiResponse, fileKey, originalURL, nil)
+}
+
+// ParseFileWithProgress is ParseFile reporting nodes walked, components and instances found to reporter (may be nil)
+func (p *FigmaParser) ParseFileWithProgress(apiResponse *FigmaAPIResponse, fileKey string, originalURL string, reporter progress.Reporter) (*ParsedFigmaData, error) {
 	if apiResponse == nil {
 		return nil, fmt.Errorf("API response cannot be nil")
 	}
 
-	// Calculate canvas dimensions
+	// Flatten the whole document hierarchy, reporting progress for big files
+	positions := p.relativePositions(apiResponse.Document)
+	nodes := p.extractNodes(apiResponse.Document, positions, reporter)
+	reporter.Report(progress.Event{Stage: progress.StageNodesWalked, Count: int64(len(nodes))})
+
+	// Pages with their real content bounds, the file canvas is the one of the first page with content
+	pages := p.ExtractPages(apiResponse.Document)
+
 	canvasWidth, canvasHeight, err := p.CalculateCanvasDimensions(apiResponse.Document)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate canvas dimensions: %w", err)
@@ -54,6 +70,16 @@ func (p *FigmaParser) ParseFile(apiResponse *FigmaAPIResponse, fileKey string, o
 	// Deduplicate components by node_id to avoid constraint violations
 	deduplicatedComponents := p.deduplicateComponents(allComponents)
 
+	// Link variants to their set, sets first so they are saved before their variants.
+	// This must happen before instances are extracted as they reference components by position
+	p.sortComponentSetsFirst(deduplicatedComponents)
+	p.assignComponentSets(apiResponse.Document, deduplicatedComponents, apiResponse.Components)
+	reporter.Report(progress.Event{Stage: progress.StageComponentsFound, Count: int64(len(deduplicatedComponents))})
+
+	// Instances of components missing from the file, typically from a library not listed in the components map,
+	// get a stub so they are kept and linked. Stubs go last so earlier component positions don't move
+	deduplicatedComponents = p.addExternalComponentStubs(apiResponse.Document, deduplicatedComponents)
+
 	// Extract instances from document nodes
 	instances, err := p.ExtractInstances([]Node{apiResponse.Document}, deduplicatedComponents)
 	if err != nil {
@@ -63,10 +89,34 @@ func (p *FigmaParser) ParseFile(apiResponse *FigmaAPIResponse, fileKey string, o
 	// Deduplicate instances by node_id to avoid constraint violations
 	deduplicatedInstances := p.deduplicateInstances(instances)
 
+	// Link components and instances to the page they are placed on
+	p.assignPages(apiResponse.Document, deduplicatedComponents, deduplicatedInstances)
+	p.assignPositions(positions, deduplicatedComponents, deduplicatedInstances)
+	p.assignContainers(apiResponse.Document, deduplicatedInstances)
+
+	// Extract copy and typography of text nodes
+	textNodes := p.ExtractTextNodes(apiResponse.Document)
+
+	// Shared styles with their values, and the components and instances using them
+	styles, styleUsages := p.ExtractStyles(apiResponse)
+
+	// Variables with their aliases resolved, and the components and instances bound to them
+	variableCollections, variables, variableBindings := p.ExtractVariables(apiResponse)
+	reporter.Report(progress.Event{Stage: progress.StageInstancesFound, Count: int64(len(deduplicatedInstances))})
+
 	return &ParsedFigmaData{
-		File:       figmaFile,
-		Components: deduplicatedComponents,
-		Instances:  deduplicatedInstances,
+		File:        figmaFile,
+		Pages:       pages,
+		Nodes:       nodes,
+		Components:  deduplicatedComponents,
+		Instances:   deduplicatedInstances,
+		TextNodes:   textNodes,
+		Styles:      styles,
+		StyleUsages: styleUsages,
+
+		VariableCollections: variableCollections,
+		Variables:           variables,
+		VariableBindings:    variableBindings,
 	}, nil
 }
 
@@ -120,28 +170,167 @@ func (p *FigmaParser) ExtractInstances(nodes []Node, components []models.Compone
 	return instances, nil
 }
 
-// CalculateCanvasDimensions calculates the canvas dimensions from the document node
+// ExtractNodes flattens the document tree into nodes in pre-order (parents before children),
+// keeping parent, depth and sibling order so the hierarchy can be rebuilt
+func (p *FigmaParser) ExtractNodes(document Node) []models.Node {
+	return p.extractNodes(document, p.relativePositions(document), nil)
+}
+
+func (p *FigmaParser) extractNodes(document Node, positions map[string]position, reporter progress.Reporter) []models.Node {
+	var nodes []models.Node
+	var walk func(node Node, parentNodeID string, depth, sortOrder int)
+	walk = func(node Node, parentNodeID string, depth, sortOrder int) {
+		nodes = append(nodes, p.nodeToModel(node, positions[node.ID], parentNodeID, depth, sortOrder))
+		if len(nodes)%nodesWalkedReportInterval == 0 {
+			reporter.Report(progress.Event{Stage: progress.StageNodesWalked, Count: int64(len(nodes))})
+		}
+		for i, child := range node.Children {
+			walk(child, node.ID, depth+1, i)
+		}
+	}
+	walk(document, "", 0, 0)
+	return nodes
+}
+
+// nodeToModel converts a Figma node to a Node model, without its children
+func (p *FigmaParser) nodeToModel(node Node, pos position, parentNodeID string, depth, sortOrder int) models.Node {
+	model := models.Node{
+		ParentNodeID:         parentNodeID,
+		NodeID:               node.ID,
+		Name:                 node.Name,
+		Type:                 node.Type,
+		Depth:                depth,
+		SortOrder:            sortOrder,
+		RelativeX:            pos.RelativeX,
+		RelativeY:            pos.RelativeY,
+		Rotation:             pos.Rotation,
+		ConstraintHorizontal: pos.ConstraintHorizontal,
+		ConstraintVertical:   pos.ConstraintVertical,
+		Visible:              node.Visible == nil || *node.Visible,
+		Active:               true,
+	}
+
+	if node.AbsoluteBoundingBox != nil {
+		model.X = node.AbsoluteBoundingBox.X
+		model.Y = node.AbsoluteBoundingBox.Y
+		model.Width = node.AbsoluteBoundingBox.Width
+		model.Height = node.AbsoluteBoundingBox.Height
+	}
+
+	properties := p.nodeProperties(&node)
+	if models.IsShapeType(node.Type) {
+		properties.Transform = pos.Transform
+	}
+	model.Properties = marshalProperties(properties)
+	return model
+}
+
+// ExtractPages returns the pages (CANVAS children of the document) in document order,
+// with bounds computed from their content as Figma doesn't send a bounding box for pages
+func (p *FigmaParser) ExtractPages(document Node) []models.Page {
+	var pages []models.Page
+
+	for _, child := range document.Children {
+		if child.Type != "CANVAS" {
+			continue
+		}
+
+		page := models.Page{
+			NodeID:    child.ID,
+			Name:      child.Name,
+			SortOrder: len(pages),
+			Active:    true,
+		}
+		if child.BackgroundColor != nil {
+			page.BackgroundColor = child.BackgroundColor.Hex()
+		}
+		if bounds := p.contentBounds(child.Children); bounds != nil {
+			page.X = bounds.X
+			page.Y = bounds.Y
+			page.Width = bounds.Width
+			page.Height = bounds.Height
+		}
+
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// CalculateCanvasDimensions calculates the canvas dimensions from the content bounds of the first page
+// having content, falling back to the bounds of the whole document
 func (p *FigmaParser) CalculateCanvasDimensions(document Node) (width, height float64, err error) {
 	if document.Type != "DOCUMENT" {
 		return 0, 0, fmt.Errorf("root node must be of type DOCUMENT")
 	}
 
+	for _, page := range p.ExtractPages(document) {
+		if page.Width > 0 && page.Height > 0 {
+			return page.Width, page.Height, nil
+		}
+	}
+
+	// If no page has content, calculate from all children
+	if bounds := p.contentBounds(document.Children); bounds != nil && bounds.Width > 0 && bounds.Height > 0 {
+		return bounds.Width, bounds.Height, nil
+	}
+
+	// Default dimensions if nothing found
+	return 1920, 1080, nil
+}
+
+// assignPages sets the page node ID of every component and instance found under a page
+func (p *FigmaParser) assignPages(document Node, components []models.Component, instances []models.Instance) {
+	pageByNodeID := make(map[string]string)
+	var walk func(node Node, pageNodeID string)
+	walk = func(node Node, pageNodeID string) {
+		pageByNodeID[node.ID] = pageNodeID
+		for _, child := range node.Children {
+			walk(child, pageNodeID)
+		}
+	}
 	for _, child := range document.Children {
-		if child.Type == "CANVAS" && child.AbsoluteBoundingBox != nil {
-			return child.AbsoluteBoundingBox.Width, child.AbsoluteBoundingBox.Height, nil
+		if child.Type == "CANVAS" {
+			walk(child, child.ID)
 		}
 	}
 
-	// If no canvas found, calculate from all children
-	var maxX, maxY float64
-	p.calculateBounds(document.Children, &maxX, &maxY)
+	for i := range components {
+		components[i].PageNodeID = pageByNodeID[components[i].NodeID]
+	}
+	for i := range instances {
+		instances[i].PageNodeID = pageByNodeID[instances[i].NodeID]
+	}
+}
 
-	if maxX == 0 && maxY == 0 {
-		// Default dimensions if nothing found
-		return 1920, 1080, nil
+// assignContainers links every instance to the instance it is nested in and to the component whose definition
+// holds it, e.g. a Button instance inside the Card component, or the Button inside a Card instance
+func (p *FigmaParser) assignContainers(document Node, instances []models.Instance) {
+	type containers struct {
+		instanceNodeID  string
+		componentNodeID string
 	}
+	containersByNodeID := make(map[string]containers)
+
+	var walk func(node Node, parents containers)
+	walk = func(node Node, parents containers) {
+		if node.Type == "INSTANCE" {
+			containersByNodeID[node.ID] = parents
+			parents.instanceNodeID = node.ID
+		} else if node.Type == "COMPONENT" {
+			parents.componentNodeID = node.ID
+		}
+		for _, child := range node.Children {
+			walk(child, parents)
+		}
+	}
+	walk(document, containers{})
 
-	return maxX, maxY, nil
+	for i := range instances {
+		parents := containersByNodeID[instances[i].NodeID]
+		instances[i].ParentInstanceNodeID = parents.instanceNodeID
+		instances[i].ContainingComponentNodeID = parents.componentNodeID
+	}
 }
 
 func (p *FigmaParser) extractComponentsFromAPI(apiResponse *FigmaAPIResponse, fileID int64) ([]models.Component, error) {
@@ -155,6 +344,8 @@ func (p *FigmaParser) extractComponentsFromAPI(apiResponse *FigmaAPIResponse, fi
 			Name:        component.Name,
 			Type:        "COMPONENT",
 			Description: component.Description,
+			Key:         component.Key,
+			IsExternal:  component.Remote,
 			Active:      true,
 		}
 
@@ -173,6 +364,8 @@ func (p *FigmaParser) extractComponentsFromAPI(apiResponse *FigmaAPIResponse, fi
 			Name:        componentSet.Name,
 			Type:        "COMPONENT_SET",
 			Description: componentSet.Description,
+			Key:         componentSet.Key,
+			IsExternal:  componentSet.Remote,
 			Active:      true,
 		}
 
@@ -223,16 +416,12 @@ func (p *FigmaParser) nodeToInstance(node Node, componentIndex int64) models.Ins
 	}
 
 	// Store the original Figma componentId in properties for later resolution
-	additionalProps := make(map[string]interface{})
-	additionalProps["figmaComponentId"] = node.ComponentID
-	if node.Visible != nil {
-		additionalProps["visible"] = *node.Visible
-	}
+	properties := p.nodeProperties(&node)
+	properties.FigmaComponentID = node.ComponentID
+	instance.Properties = marshalProperties(properties)
 
-	if len(additionalProps) > 0 {
-		propsJSON, _ := json.Marshal(additionalProps)
-		instance.Properties = propsJSON
-	}
+	// Keep the property values and overrides that make this instance differ from its component
+	p.populateInstanceOverrides(&node, &instance)
 
 	return instance
 }
@@ -247,21 +436,9 @@ func (p *FigmaParser) populateNodeProperties(node *Node, component *models.Compo
 		component.Height = node.AbsoluteBoundingBox.Height
 	}
 
-	// Store any additional properties as JSON
-	additionalProps := make(map[string]interface{})
-
-	if node.Visible != nil {
-		additionalProps["visible"] = *node.Visible
-	}
-
-	if node.Type != "" {
-		additionalProps["nodeType"] = node.Type
-	}
-
-	if len(additionalProps) > 0 {
-		propsJSON, _ := json.Marshal(additionalProps)
-		component.Properties = propsJSON
-	}
+	// Store visibility, paints, effects and corners as a versioned JSON document
+	component.Properties = marshalProperties(p.nodeProperties(node))
+	component.PropertyDefinitions = convertPropertyDefinitions(node.ComponentPropertyDefinitions)
 }
 
 // findNodeByID recursively searches for a node with the given ID
@@ -289,26 +466,36 @@ func (p *FigmaParser) findComponentIDByNodeID(nodeID string, components []models
 	return 0
 }
 
-// calculateBounds recursively calculates the maximum bounds from nodes
-func (p *FigmaParser) calculateBounds(nodes []Node, maxX, maxY *float64) {
-	for _, node := range nodes {
-		if node.AbsoluteBoundingBox != nil {
-			nodeMaxX := node.AbsoluteBoundingBox.X + node.AbsoluteBoundingBox.Width
-			nodeMaxY := node.AbsoluteBoundingBox.Y + node.AbsoluteBoundingBox.Height
+// contentBounds recursively calculates the box enclosing all nodes with a bounding box, nil when none has one
+func (p *FigmaParser) contentBounds(nodes []Node) *BoundingBox {
+	var bounds *BoundingBox
+	var maxX, maxY float64
 
-			if nodeMaxX > *maxX {
-				*maxX = nodeMaxX
+	var walk func(nodes []Node)
+	walk = func(nodes []Node) {
+		for _, node := range nodes {
+			if box := node.AbsoluteBoundingBox; box != nil {
+				if bounds == nil {
+					bounds = &BoundingBox{X: box.X, Y: box.Y}
+					maxX, maxY = box.X+box.Width, box.Y+box.Height
+				}
+				bounds.X = math.Min(bounds.X, box.X)
+				bounds.Y = math.Min(bounds.Y, box.Y)
+				maxX = math.Max(maxX, box.X+box.Width)
+				maxY = math.Max(maxY, box.Y+box.Height)
 			}
-			if nodeMaxY > *maxY {
-				*maxY = nodeMaxY
-			}
-		}
 
-		// Recursively process children
-		if len(node.Children) > 0 {
-			p.calculateBounds(node.Children, maxX, maxY)
+			// Recursively process children
+			walk(node.Children)
 		}
 	}
+	walk(nodes)
+
+	if bounds != nil {
+		bounds.Width = maxX - bounds.X
+		bounds.Height = maxY - bounds.Y
+	}
+	return bounds
 }
 
 // deduplicateComponents removes duplicate components based on node_id
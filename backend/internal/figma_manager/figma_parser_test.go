@@ -0,0 +1,195 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/internal/progress"
+	"testing"
+)
+
+func loadSampleResponse(t *testing.T) *figma_manager.FigmaAPIResponse {
+	t.Helper()
+	var apiResponse figma_manager.FigmaAPIResponse
+	if err := json.Unmarshal(figmatest.SampleFile(), &apiResponse); err != nil {
+		t.Fatalf("Failed to decode sample fixture: %v", err)
+	}
+	return &apiResponse
+}
+
+func TestFigmaParser_ParseFile(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	apiResponse := loadSampleResponse(t)
+
+	parsedData, err := parser.ParseFile(apiResponse, figmatest.SampleFileKey, figmatest.SampleFileURL)
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	if parsedData.File.Name != "Fixture Design System" {
+		t.Errorf("Expected file name from fixture, got %s", parsedData.File.Name)
+	}
+	if parsedData.File.URL != figmatest.SampleFileURL {
+		t.Errorf("Expected original URL to be kept, got %s", parsedData.File.URL)
+	}
+
+	// components are deduplicated between the components map and the document tree
+	componentsByNodeID := make(map[string]int)
+	for _, component := range parsedData.Components {
+		componentsByNodeID[component.NodeID]++
+	}
+	for _, nodeID := range []string{"2:1", "2:2", "2:3", "3:1"} {
+		if componentsByNodeID[nodeID] != 1 {
+			t.Errorf("Expected component %s exactly once, got %d", nodeID, componentsByNodeID[nodeID])
+		}
+	}
+
+	// components and instances are linked to their page, remote components to none
+	expectedPages := map[string]string{"2:2": "0:2", "3:1": "0:2", "9:9": "", "1:2": "0:1", "I1:4;3:2": "0:1"}
+	for _, component := range parsedData.Components {
+		if expected, ok := expectedPages[component.NodeID]; ok && component.PageNodeID != expected {
+			t.Errorf("Expected component %s on page %q, got %q", component.NodeID, expected, component.PageNodeID)
+		}
+	}
+	for _, instance := range parsedData.Instances {
+		if expected, ok := expectedPages[instance.NodeID]; ok && instance.PageNodeID != expected {
+			t.Errorf("Expected instance %s on page %q, got %q", instance.NodeID, expected, instance.PageNodeID)
+		}
+	}
+
+	// every instance points to a component by its 1-based temporary index
+	for _, instance := range parsedData.Instances {
+		if instance.ComponentID < 1 || int(instance.ComponentID) > len(parsedData.Components) {
+			t.Errorf("Instance %s has invalid temporary component ID %d", instance.NodeID, instance.ComponentID)
+		}
+	}
+}
+
+func TestFigmaParser_ParseFileWithProgress(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	stages := make(map[progress.Stage]int64)
+	reporter := progress.Reporter(func(event progress.Event) {
+		stages[event.Stage] = event.Count
+	})
+
+	parsedData, err := parser.ParseFileWithProgress(loadSampleResponse(t), figmatest.SampleFileKey, "", reporter)
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	if stages[progress.StageNodesWalked] != int64(len(parsedData.Nodes)) {
+		t.Errorf("Expected %d nodes walked, got %d", len(parsedData.Nodes), stages[progress.StageNodesWalked])
+	}
+	if stages[progress.StageComponentsFound] != int64(len(parsedData.Components)) {
+		t.Errorf("Expected %d components found, got %d", len(parsedData.Components), stages[progress.StageComponentsFound])
+	}
+	if stages[progress.StageInstancesFound] != int64(len(parsedData.Instances)) {
+		t.Errorf("Expected %d instances found, got %d", len(parsedData.Instances), stages[progress.StageInstancesFound])
+	}
+}
+
+func TestFigmaParser_ExtractNodes(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	nodes := parser.ExtractNodes(loadSampleResponse(t).Document)
+
+	if len(nodes) != 18 {
+		t.Fatalf("Expected every node of the fixture to be extracted, got %d", len(nodes))
+	}
+	if nodes[0].Type != "DOCUMENT" || nodes[0].ParentNodeID != "" || nodes[0].Depth != 0 {
+		t.Errorf("Expected the document to come first as the root, got %+v", nodes[0])
+	}
+
+	// pre-order: every parent is seen before its children, one level up
+	seen := map[string]int{nodes[0].NodeID: 0}
+	for _, node := range nodes[1:] {
+		parentDepth, ok := seen[node.ParentNodeID]
+		if !ok {
+			t.Errorf("Node %s extracted before its parent %s", node.NodeID, node.ParentNodeID)
+			continue
+		}
+		if node.Depth != parentDepth+1 {
+			t.Errorf("Expected node %s at depth %d, got %d", node.NodeID, parentDepth+1, node.Depth)
+		}
+		seen[node.NodeID] = node.Depth
+	}
+
+	// sibling order follows the document
+	byNodeID := make(map[string]int)
+	for i, node := range nodes {
+		byNodeID[node.NodeID] = i
+	}
+	home, components := nodes[byNodeID["0:1"]], nodes[byNodeID["0:2"]]
+	if home.SortOrder != 0 || components.SortOrder != 1 {
+		t.Errorf("Expected pages in document order, got %d and %d", home.SortOrder, components.SortOrder)
+	}
+	if landing := nodes[byNodeID["1:1"]]; landing.Width == 0 || !landing.Visible {
+		t.Errorf("Expected frame bounds and visibility to be kept, got %+v", landing)
+	}
+}
+
+func TestFigmaParser_ParseFile_NilResponse(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	if _, err := parser.ParseFile(nil, "", ""); err == nil {
+		t.Error("Expected error for nil API response")
+	}
+}
+
+func TestFigmaParser_CalculateCanvasDimensions(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+
+	if _, _, err := parser.CalculateCanvasDimensions(figma_manager.Node{Type: "FRAME"}); err == nil {
+		t.Error("Expected error when root is not a DOCUMENT")
+	}
+
+	width, height, err := parser.CalculateCanvasDimensions(loadSampleResponse(t).Document)
+	if err != nil {
+		t.Fatalf("Failed to calculate canvas dimensions: %v", err)
+	}
+	// the Home page has no bounding box of its own, its content is the 1440x900 Landing frame
+	if width != 1440 || height != 900 {
+		t.Errorf("Expected canvas dimensions from the first page content, got %.1fx%.1f", width, height)
+	}
+
+	if width, height, _ := parser.CalculateCanvasDimensions(figma_manager.Node{Type: "DOCUMENT"}); width != 1920 || height != 1080 {
+		t.Errorf("Expected default dimensions for an empty document, got %.1fx%.1f", width, height)
+	}
+}
+
+func TestFigmaParser_ExtractPages(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	pages := parser.ExtractPages(loadSampleResponse(t).Document)
+
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(pages))
+	}
+
+	home, components := pages[0], pages[1]
+	if home.Name != "Home" || home.SortOrder != 0 || components.Name != "Components" || components.SortOrder != 1 {
+		t.Errorf("Expected pages in document order, got %s (%d) and %s (%d)", home.Name, home.SortOrder, components.Name, components.SortOrder)
+	}
+	if home.BackgroundColor != "#F5F5F5" {
+		t.Errorf("Expected Home background color #F5F5F5, got %s", home.BackgroundColor)
+	}
+
+	// content bounds enclose the component set at x=2000 and the card ending at x=2820
+	if components.X != 2000 || components.Y != 0 || components.Width != 820 || components.Height != 200 {
+		t.Errorf("Expected Components page bounds 2000,0 820x200, got %.0f,%.0f %.0fx%.0f", components.X, components.Y, components.Width, components.Height)
+	}
+}
+
+func TestColor_Hex(t *testing.T) {
+	tests := []struct {
+		color    figma_manager.Color
+		expected string
+	}{
+		{figma_manager.Color{R: 1, G: 1, B: 1, A: 1}, "#FFFFFF"},
+		{figma_manager.Color{R: 0, G: 0.5, B: 1, A: 1}, "#0080FF"},
+		{figma_manager.Color{R: 0, G: 0, B: 0, A: 0.5}, "#00000080"},
+	}
+
+	for _, tt := range tests {
+		if hex := tt.color.Hex(); hex != tt.expected {
+			t.Errorf("Expected %+v as %s, got %s", tt.color, tt.expected, hex)
+		}
+	}
+}
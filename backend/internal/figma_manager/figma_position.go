@@ -0,0 +1,109 @@
+package figma_manager
+
+import (
+	"math"
+	"parser-service/models"
+)
+
+// position is where a node sits within its parent frame, alongside its absolute canvas coordinates
+type position struct {
+	RelativeX            float64 // origin of the node in its parent frame, the relativeTransform translation
+	RelativeY            float64 // (the bounding box offset without one, which differs for rotated nodes)
+	Rotation             float64
+	Transform            *models.Transform // from the node coordinates to the canvas, nil without relativeTransform
+	ConstraintHorizontal string
+	ConstraintVertical   string
+}
+
+// isFrameLike reports whether children of a node of this type are positioned and constrained relative to it.
+// Groups aren't: their children are constrained by the closest frame above the group
+func isFrameLike(nodeType string) bool {
+	switch nodeType {
+	case "FRAME", "COMPONENT", "COMPONENT_SET", "INSTANCE", "SECTION":
+		return true
+	}
+	return false
+}
+
+// relativePositions computes the position of every node relative to its parent frame, by Figma node ID.
+// Nodes placed directly on a page are relative to the page origin, i.e. their absolute coordinates
+func (p *FigmaParser) relativePositions(document Node) map[string]position {
+	positions := make(map[string]position)
+
+	// relative transforms are relative to the parent frame as well, groups having no coordinates of their own
+	var walk func(node Node, frame *BoundingBox, frameTransform *models.Transform)
+	walk = func(node Node, frame *BoundingBox, frameTransform *models.Transform) {
+		var pos position
+		relative, hasTransform := transformFromMatrix(node.RelativeTransform)
+		if hasTransform {
+			pos.RelativeX, pos.RelativeY = relative[0][2], relative[1][2]
+		} else if box := node.AbsoluteBoundingBox; box != nil {
+			pos.RelativeX, pos.RelativeY = box.X, box.Y
+			if frame != nil {
+				pos.RelativeX -= frame.X
+				pos.RelativeY -= frame.Y
+			}
+		}
+		pos.Rotation = rotationFromTransform(node.RelativeTransform)
+		if hasTransform && frameTransform != nil {
+			absolute := frameTransform.Multiply(relative)
+			pos.Transform = &absolute
+		}
+		if node.Constraints != nil {
+			pos.ConstraintHorizontal = node.Constraints.Horizontal
+			pos.ConstraintVertical = node.Constraints.Vertical
+		}
+		positions[node.ID] = pos
+
+		if node.Type == "CANVAS" {
+			frame = nil
+			frameTransform = &models.IdentityTransform
+		} else if isFrameLike(node.Type) {
+			if node.AbsoluteBoundingBox != nil {
+				frame = node.AbsoluteBoundingBox
+			}
+			frameTransform = pos.Transform
+		}
+		for _, child := range node.Children {
+			walk(child, frame, frameTransform)
+		}
+	}
+	walk(document, nil, nil)
+
+	return positions
+}
+
+// rotationFromTransform returns the rotation in degrees of a [[cos, sin, x], [-sin, cos, y]] transform, 0 without one
+func rotationFromTransform(transform [][]float64) float64 {
+	if len(transform) < 2 || len(transform[0]) < 1 || len(transform[1]) < 1 {
+		return 0
+	}
+	rotation := math.Atan2(-transform[1][0], transform[0][0]) * 180 / math.Pi
+	// avoid -0 and floating point noise around whole degrees
+	return math.Round(rotation*1e6)/1e6 + 0
+}
+
+// transformFromMatrix reads a [[a, c, x], [b, d, y]] transform
+func transformFromMatrix(matrix [][]float64) (models.Transform, bool) {
+	if len(matrix) < 2 || len(matrix[0]) < 3 || len(matrix[1]) < 3 {
+		return models.Transform{}, false
+	}
+	return models.Transform{
+		{matrix[0][0], matrix[0][1], matrix[0][2]},
+		{matrix[1][0], matrix[1][1], matrix[1][2]},
+	}, true
+}
+
+// assignPositions sets the relative position, rotation and constraints of components and instances
+func (p *FigmaParser) assignPositions(positions map[string]position, components []models.Component, instances []models.Instance) {
+	for i := range components {
+		pos := positions[components[i].NodeID]
+		components[i].RelativeX, components[i].RelativeY, components[i].Rotation = pos.RelativeX, pos.RelativeY, pos.Rotation
+		components[i].ConstraintHorizontal, components[i].ConstraintVertical = pos.ConstraintHorizontal, pos.ConstraintVertical
+	}
+	for i := range instances {
+		pos := positions[instances[i].NodeID]
+		instances[i].RelativeX, instances[i].RelativeY, instances[i].Rotation = pos.RelativeX, pos.RelativeY, pos.Rotation
+		instances[i].ConstraintHorizontal, instances[i].ConstraintVertical = pos.ConstraintHorizontal, pos.ConstraintVertical
+	}
+}
@@ -0,0 +1,72 @@
+package figma_manager_test
+
+import (
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+)
+
+func TestFigmaParser_RelativePositions(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	type placement struct {
+		x, y, relativeX, relativeY float64
+	}
+	placements := make(map[string]placement)
+	for _, component := range parsedData.Components {
+		placements[component.NodeID] = placement{component.X, component.Y, component.RelativeX, component.RelativeY}
+	}
+	for _, instance := range parsedData.Instances {
+		placements[instance.NodeID] = placement{instance.X, instance.Y, instance.RelativeX, instance.RelativeY}
+	}
+
+	tests := []struct {
+		name     string
+		nodeID   string
+		expected placement
+	}{
+		{"top level component is relative to the page", "3:1", placement{2500, 0, 2500, 0}},
+		{"variant is relative to its component set", "2:2", placement{2020, 20, 20, 20}},
+		{"instance is relative to its frame", "1:2", placement{40, 40, 40, 40}},
+		{"instance in a component is relative to the component", "3:2", placement{2520, 140, 20, 140}},
+		{"nested instance is relative to the parent instance", "I1:4;3:2", placement{60, 260, 20, 140}},
+		// rotated 90 degrees, its origin is the bottom left corner of its bounding box
+		{"rotated instance is placed by its relative transform", "1:6", placement{400, 40, 400, 88}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placements[tt.nodeID]; got != tt.expected {
+				t.Errorf("Expected %s at %+v, got %+v", tt.nodeID, tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("constraints and rotation", func(t *testing.T) {
+		for _, instance := range parsedData.Instances {
+			switch instance.NodeID {
+			case "1:6":
+				if instance.Rotation != 90 || instance.ConstraintHorizontal != "RIGHT" || instance.ConstraintVertical != "TOP" {
+					t.Errorf("Expected avatar rotated 90 degrees, constrained RIGHT/TOP, got %v %s/%s",
+						instance.Rotation, instance.ConstraintHorizontal, instance.ConstraintVertical)
+				}
+			case "3:2":
+				if instance.Rotation != 0 || instance.ConstraintHorizontal != "LEFT_RIGHT" || instance.ConstraintVertical != "BOTTOM" {
+					t.Errorf("Expected button constrained LEFT_RIGHT/BOTTOM, got %v %s/%s",
+						instance.Rotation, instance.ConstraintHorizontal, instance.ConstraintVertical)
+				}
+			}
+		}
+	})
+
+	t.Run("tree nodes", func(t *testing.T) {
+		for _, node := range parsedData.Nodes {
+			if node.NodeID == "I1:4;3:3" && (node.RelativeX != 20 || node.RelativeY != 20) {
+				t.Errorf("Expected the card title at 20,20 in the card instance, got %v,%v", node.RelativeX, node.RelativeY)
+			}
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package figma_manager
+
+import (
+	"encoding/json"
+	"parser-service/models"
+)
+
+// nodeProperties builds the versioned properties document of a node: visibility, paints, effects and corners
+func (p *FigmaParser) nodeProperties(node *Node) models.NodeProperties {
+	properties := models.NodeProperties{
+		SchemaVersion: models.NodePropertiesSchemaVersion,
+		NodeType:      node.Type,
+		Visible:       node.Visible,
+		Opacity:       node.Opacity,
+		BlendMode:     node.BlendMode,
+		Fills:         convertPaints(node.Fills),
+		Strokes:       convertPaints(node.Strokes),
+		Effects:       convertEffects(node.Effects),
+		CornerRadii:   convertCornerRadii(node),
+		Layout:        convertLayout(node),
+
+		PropertyReferences: node.ComponentPropertyReferences,
+	}
+
+	if models.IsShapeType(node.Type) {
+		properties.FillGeometry = convertPaths(node.FillGeometry)
+		properties.StrokeGeometry = convertPaths(node.StrokeGeometry)
+	}
+
+	// Figma sends a stroke weight and alignment even without strokes, they only matter with strokes
+	if len(node.Strokes) > 0 {
+		properties.StrokeWeight = node.StrokeWeight
+		properties.StrokeAlign = node.StrokeAlign
+	}
+
+	return properties
+}
+
+func convertPaths(paths []Path) []models.Path {
+	if len(paths) == 0 {
+		return nil
+	}
+	converted := make([]models.Path, len(paths))
+	for i, path := range paths {
+		converted[i] = models.Path{Path: path.Path, WindingRule: path.WindingRule}
+	}
+	return converted
+}
+
+// marshalProperties encodes a properties document for a JSONB column
+func marshalProperties(properties models.NodeProperties) json.RawMessage {
+	propsJSON, _ := json.Marshal(properties)
+	return propsJSON
+}
+
+func convertPaints(paints []Paint) []models.Paint {
+	if len(paints) == 0 {
+		return nil
+	}
+
+	converted := make([]models.Paint, 0, len(paints))
+	for _, paint := range paints {
+		model := models.Paint{
+			Type:      paint.Type,
+			Visible:   paint.Visible == nil || *paint.Visible,
+			Opacity:   1,
+			BlendMode: paint.BlendMode,
+			ImageRef:  paint.ImageRef,
+			ScaleMode: paint.ScaleMode,
+		}
+		if paint.Opacity != nil {
+			model.Opacity = *paint.Opacity
+		}
+		if paint.Color != nil {
+			model.Color = paint.Color.Hex()
+		}
+		for _, position := range paint.GradientHandlePositions {
+			model.GradientHandlePositions = append(model.GradientHandlePositions, models.Vector{X: position.X, Y: position.Y})
+		}
+		for _, stop := range paint.GradientStops {
+			model.GradientStops = append(model.GradientStops, models.GradientStop{Position: stop.Position, Color: stop.Color.Hex()})
+		}
+		converted = append(converted, model)
+	}
+	return converted
+}
+
+func convertEffects(effects []Effect) []models.Effect {
+	if len(effects) == 0 {
+		return nil
+	}
+
+	converted := make([]models.Effect, 0, len(effects))
+	for _, effect := range effects {
+		model := models.Effect{
+			Type:      effect.Type,
+			Visible:   effect.Visible,
+			Radius:    effect.Radius,
+			BlendMode: effect.BlendMode,
+			Spread:    effect.Spread,
+		}
+		if effect.Color != nil {
+			model.Color = effect.Color.Hex()
+		}
+		if effect.Offset != nil {
+			model.Offset = &models.Vector{X: effect.Offset.X, Y: effect.Offset.Y}
+		}
+		converted = append(converted, model)
+	}
+	return converted
+}
+
+// convertCornerRadii prefers per corner radii over the single corner radius, nil when the node has square corners
+func convertCornerRadii(node *Node) *models.CornerRadii {
+	if len(node.RectangleCornerRadii) == 4 {
+		radii := node.RectangleCornerRadii
+		return &models.CornerRadii{TopLeft: radii[0], TopRight: radii[1], BottomRight: radii[2], BottomLeft: radii[3]}
+	}
+	if node.CornerRadius != nil && *node.CornerRadius > 0 {
+		radius := *node.CornerRadius
+		return &models.CornerRadii{TopLeft: radius, TopRight: radius, BottomRight: radius, BottomLeft: radius}
+	}
+	return nil
+}
+
+// convertLayout normalizes the Auto Layout properties of a node, filling in the defaults Figma omits.
+// nil when the node is neither an auto layout container nor sized or positioned by one
+func convertLayout(node *Node) *models.Layout {
+	layout := &models.Layout{
+		SizingHorizontal: node.LayoutSizingHorizontal,
+		SizingVertical:   node.LayoutSizingVertical,
+		Positioning:      node.LayoutPositioning,
+	}
+	if node.LayoutGrow != nil {
+		layout.Grow = *node.LayoutGrow
+	}
+
+	if node.LayoutMode == "HORIZONTAL" || node.LayoutMode == "VERTICAL" {
+		layout.Mode = node.LayoutMode
+		layout.Wrap = node.LayoutWrap == "WRAP"
+		layout.PrimaryAxisAlign = node.PrimaryAxisAlignItems
+		if layout.PrimaryAxisAlign == "" {
+			layout.PrimaryAxisAlign = "MIN"
+		}
+		layout.CounterAxisAlign = node.CounterAxisAlignItems
+		if layout.CounterAxisAlign == "" {
+			layout.CounterAxisAlign = "MIN"
+		}
+		layout.ItemSpacing = node.ItemSpacing
+		if layout.Wrap {
+			layout.CounterAxisSpacing = node.CounterAxisSpacing
+		}
+		layout.Padding = &models.Padding{
+			Top:    node.PaddingTop,
+			Right:  node.PaddingRight,
+			Bottom: node.PaddingBottom,
+			Left:   node.PaddingLeft,
+		}
+	}
+
+	if *layout == (models.Layout{}) {
+		return nil
+	}
+	return layout
+}
@@ -0,0 +1,153 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"testing"
+)
+
+func decodeProperties(t *testing.T, raw json.RawMessage) models.NodeProperties {
+	t.Helper()
+	var properties models.NodeProperties
+	if err := json.Unmarshal(raw, &properties); err != nil {
+		t.Fatalf("Failed to decode properties %s: %v", raw, err)
+	}
+	if properties.SchemaVersion != models.NodePropertiesSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", models.NodePropertiesSchemaVersion, properties.SchemaVersion)
+	}
+	return properties
+}
+
+func TestFigmaParser_NodeProperties(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	components := make(map[string]models.Component)
+	for _, component := range parsedData.Components {
+		components[component.NodeID] = component
+	}
+
+	t.Run("solid fill, stroke and uniform radius", func(t *testing.T) {
+		properties := decodeProperties(t, components["2:2"].Properties)
+
+		if len(properties.Fills) != 1 || properties.Fills[0].Type != "SOLID" || properties.Fills[0].Color != "#3366FF" {
+			t.Errorf("Expected a single #3366FF solid fill, got %+v", properties.Fills)
+		}
+		if len(properties.Strokes) != 1 || properties.Strokes[0].Opacity != 0.5 || !properties.Strokes[0].Visible {
+			t.Errorf("Expected a visible half opaque stroke, got %+v", properties.Strokes)
+		}
+		if properties.StrokeWeight == nil || *properties.StrokeWeight != 1 || properties.StrokeAlign != "INSIDE" {
+			t.Errorf("Expected a 1px inside stroke, got weight %v align %q", properties.StrokeWeight, properties.StrokeAlign)
+		}
+		if properties.CornerRadii == nil || properties.CornerRadii.TopLeft != 8 || properties.CornerRadii.BottomLeft != 8 {
+			t.Errorf("Expected all corners at 8, got %+v", properties.CornerRadii)
+		}
+	})
+
+	t.Run("gradient and image fills, effects, per corner radii", func(t *testing.T) {
+		properties := decodeProperties(t, components["3:1"].Properties)
+
+		if properties.Opacity == nil || *properties.Opacity != 0.9 || properties.BlendMode != "PASS_THROUGH" {
+			t.Errorf("Expected opacity 0.9 and PASS_THROUGH blend mode, got %v %q", properties.Opacity, properties.BlendMode)
+		}
+		if len(properties.Fills) != 2 {
+			t.Fatalf("Expected 2 fills, got %d", len(properties.Fills))
+		}
+		gradient, image := properties.Fills[0], properties.Fills[1]
+		if gradient.Type != "GRADIENT_LINEAR" || len(gradient.GradientStops) != 2 || gradient.GradientStops[1].Color != "#E6E6E6" || len(gradient.GradientHandlePositions) != 3 {
+			t.Errorf("Expected a two stop linear gradient, got %+v", gradient)
+		}
+		if image.Type != "IMAGE" || image.Visible || image.ImageRef != "card-cover-ref" || image.ScaleMode != "FILL" {
+			t.Errorf("Expected a hidden image fill, got %+v", image)
+		}
+
+		if len(properties.Effects) != 3 {
+			t.Fatalf("Expected 3 effects, got %d", len(properties.Effects))
+		}
+		shadow := properties.Effects[0]
+		if shadow.Type != "DROP_SHADOW" || shadow.Radius != 12 || shadow.Color != "#00000040" || shadow.Offset == nil || shadow.Offset.Y != 4 {
+			t.Errorf("Expected a drop shadow 0 4 12 #00000040, got %+v", shadow)
+		}
+		if properties.Effects[1].Type != "INNER_SHADOW" || properties.Effects[2].Type != "LAYER_BLUR" || properties.Effects[2].Visible {
+			t.Errorf("Expected an inner shadow and a hidden layer blur, got %+v", properties.Effects[1:])
+		}
+
+		radii := properties.CornerRadii
+		if radii == nil || radii.TopLeft != 12 || radii.TopRight != 12 || radii.BottomRight != 0 || radii.BottomLeft != 0 {
+			t.Errorf("Expected only the top corners rounded, got %+v", radii)
+		}
+		if properties.StrokeWeight != nil {
+			t.Errorf("Expected no stroke weight without strokes, got %v", *properties.StrokeWeight)
+		}
+	})
+
+	t.Run("auto layout container", func(t *testing.T) {
+		layout := models.LayoutFromProperties(components["2:2"].Properties)
+		if layout == nil {
+			t.Fatal("Expected the button component to have a layout")
+		}
+		expected := models.Layout{
+			Mode:             "HORIZONTAL",
+			PrimaryAxisAlign: "CENTER",
+			CounterAxisAlign: "CENTER",
+			ItemSpacing:      8,
+			Padding:          &models.Padding{Top: 12, Right: 24, Bottom: 12, Left: 24},
+			SizingHorizontal: "HUG",
+			SizingVertical:   "HUG",
+		}
+		if layout.Padding == nil || *layout.Padding != *expected.Padding {
+			t.Errorf("Expected padding %+v, got %+v", expected.Padding, layout.Padding)
+		}
+		layout.Padding, expected.Padding = nil, nil
+		if *layout != expected {
+			t.Errorf("Expected layout %+v, got %+v", expected, *layout)
+		}
+
+		// omitted alignments default to MIN
+		card := models.LayoutFromProperties(components["3:1"].Properties)
+		if card == nil || card.Mode != "VERTICAL" || card.PrimaryAxisAlign != "MIN" || card.CounterAxisAlign != "MIN" || card.Wrap {
+			t.Errorf("Expected a vertical card layout with default alignments, got %+v", card)
+		}
+	})
+
+	t.Run("auto layout children", func(t *testing.T) {
+		instances := make(map[string]models.Instance)
+		for _, instance := range parsedData.Instances {
+			instances[instance.NodeID] = instance
+		}
+
+		button := models.LayoutFromProperties(instances["3:2"].Properties)
+		if button == nil || button.Mode != "" || button.SizingHorizontal != "FILL" || button.Grow != 1 || button.Padding != nil {
+			t.Errorf("Expected a filling, growing child without container layout, got %+v", button)
+		}
+		avatar := models.LayoutFromProperties(instances["1:6"].Properties)
+		if avatar == nil || avatar.Positioning != "ABSOLUTE" {
+			t.Errorf("Expected an absolutely positioned child, got %+v", avatar)
+		}
+		if layout := models.LayoutFromProperties(instances["1:2"].Properties); layout != nil {
+			t.Errorf("Expected no layout outside auto layout, got %+v", layout)
+		}
+	})
+
+	t.Run("instance overrides keep the component reference", func(t *testing.T) {
+		for _, instance := range parsedData.Instances {
+			if instance.NodeID != "1:4" {
+				continue
+			}
+			properties := decodeProperties(t, instance.Properties)
+			if properties.FigmaComponentID != "3:1" {
+				t.Errorf("Expected figmaComponentId 3:1, got %q", properties.FigmaComponentID)
+			}
+			if len(properties.Fills) != 1 || len(properties.Effects) != 1 || properties.Effects[0].Type != "BACKGROUND_BLUR" {
+				t.Errorf("Expected the instance fill and background blur, got %+v %+v", properties.Fills, properties.Effects)
+			}
+			return
+		}
+		t.Error("Expected instance 1:4 to be extracted")
+	})
+}
@@ -0,0 +1,158 @@
+package figma_manager
+
+import (
+	"context"
+	"fmt"
+	"parser-service/internal/errors"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups Figma API endpoints sharing the same rate limit
+type EndpointClass string
+
+const (
+	EndpointFiles     EndpointClass = "files"
+	EndpointImages    EndpointClass = "images"
+	EndpointMe        EndpointClass = "me"
+	EndpointTeams     EndpointClass = "teams"     // team library components, component sets and styles
+	EndpointVariables EndpointClass = "variables" // local and published variables of a file
+)
+
+// idleBucketTTL is how long an unused, fully refilled bucket is kept before being dropped
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimit is a token bucket: RequestsPerMinute sustained rate with bursts of up to Burst requests
+type RateLimit struct {
+	RequestsPerMinute float64
+	Burst             int
+}
+
+// RateLimiterConfig configures the client side limiter. Endpoint classes without a limit are not throttled
+type RateLimiterConfig struct {
+	Limits  map[EndpointClass]RateLimit
+	MaxWait time.Duration // how long a request may queue for a token before failing with a RateLimitError
+}
+
+// DefaultRateLimiterConfig stays below Figma's published per-token limits
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		Limits: map[EndpointClass]RateLimit{
+			EndpointFiles:     {RequestsPerMinute: 60, Burst: 10},
+			EndpointImages:    {RequestsPerMinute: 30, Burst: 5},
+			EndpointMe:        {RequestsPerMinute: 120, Burst: 20},
+			EndpointTeams:     {RequestsPerMinute: 30, Burst: 5},
+			EndpointVariables: {RequestsPerMinute: 30, Burst: 5},
+		},
+		MaxWait: 30 * time.Second,
+	}
+}
+
+// RateLimiter throttles outgoing Figma requests per Figma token and endpoint class.
+// It is shared by all concurrent parses going through the same FigmaClient.
+type RateLimiter struct {
+	mu      sync.Mutex
+	config  RateLimiterConfig
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64 // may go negative: every queued request reserves a future token
+	lastRefill time.Time
+}
+
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	return &RateLimiter{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Wait blocks until a request for the given token and endpoint class may be sent.
+// If that would take longer than MaxWait (or the context deadline) it fails right away with a RateLimitError.
+func (l *RateLimiter) Wait(ctx context.Context, figmaToken string, class EndpointClass) error {
+	limit, ok := l.config.Limits[class]
+	if !ok || limit.RequestsPerMinute <= 0 {
+		return nil
+	}
+
+	delay, err := l.reserve(ctx, figmaToken, class, limit)
+	if err != nil || delay == 0 {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancelReservation(figmaToken, class)
+		return fmt.Errorf("request cancelled while waiting for rate limiter: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket, returning how long the caller has to wait for it
+func (l *RateLimiter) reserve(ctx context.Context, figmaToken string, class EndpointClass, limit RateLimit) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneIdleBuckets(now)
+
+	key := bucketKey(figmaToken, class)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(max(limit.Burst, 1)), lastRefill: now}
+		l.buckets[key] = bucket
+	}
+
+	ratePerSecond := limit.RequestsPerMinute / 60
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens = min(bucket.tokens+elapsed*ratePerSecond, float64(max(limit.Burst, 1)))
+	bucket.lastRefill = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, nil
+	}
+
+	delay := time.Duration((1 - bucket.tokens) / ratePerSecond * float64(time.Second))
+	maxWait := l.config.MaxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = min(maxWait, deadline.Sub(now))
+	}
+	if delay > maxWait {
+		return 0, &errors.RateLimitError{
+			Message:    fmt.Sprintf("too many queued requests to Figma %s endpoints", class),
+			RetryAfter: delay,
+		}
+	}
+
+	bucket.tokens--
+	return delay, nil
+}
+
+// cancelReservation gives back a token reserved by a request that stopped waiting
+func (l *RateLimiter) cancelReservation(figmaToken string, class EndpointClass) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[bucketKey(figmaToken, class)]; ok {
+		bucket.tokens++
+	}
+}
+
+// pruneIdleBuckets drops buckets of tokens not seen for a while, they would be full again anyway
+func (l *RateLimiter) pruneIdleBuckets(now time.Time) {
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastRefill) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func bucketKey(figmaToken string, class EndpointClass) string {
+	return string(class) + ":" + figmaToken
+}
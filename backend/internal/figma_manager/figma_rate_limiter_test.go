@@ -0,0 +1,79 @@
+package figma_manager_test
+
+import (
+	"context"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fails with RateLimitError once the queue wait exceeds MaxWait", func(t *testing.T) {
+		limiter := figma_manager.NewRateLimiter(figma_manager.RateLimiterConfig{
+			Limits:  map[figma_manager.EndpointClass]figma_manager.RateLimit{figma_manager.EndpointFiles: {RequestsPerMinute: 1, Burst: 1}},
+			MaxWait: 10 * time.Millisecond,
+		})
+
+		if err := limiter.Wait(ctx, "token-a", figma_manager.EndpointFiles); err != nil {
+			t.Fatalf("Expected first request to pass, got: %v", err)
+		}
+		err := limiter.Wait(ctx, "token-a", figma_manager.EndpointFiles)
+		if !errors.IsRateLimited(err) {
+			t.Fatalf("Expected RateLimitError, got: %v", err)
+		}
+		if errors.RetryAfter(err) <= 0 {
+			t.Error("Expected RateLimitError to suggest a retry delay")
+		}
+
+		// buckets are per token and per endpoint class
+		if err := limiter.Wait(ctx, "token-b", figma_manager.EndpointFiles); err != nil {
+			t.Errorf("Expected other token to have its own bucket, got: %v", err)
+		}
+		if err := limiter.Wait(ctx, "token-a", figma_manager.EndpointImages); err != nil {
+			t.Errorf("Expected unlimited endpoint class to pass, got: %v", err)
+		}
+	})
+
+	t.Run("Queues requests until a token is available", func(t *testing.T) {
+		limiter := figma_manager.NewRateLimiter(figma_manager.RateLimiterConfig{
+			Limits:  map[figma_manager.EndpointClass]figma_manager.RateLimit{figma_manager.EndpointFiles: {RequestsPerMinute: 1200, Burst: 1}},
+			MaxWait: time.Second,
+		})
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if err := limiter.Wait(ctx, "token-a", figma_manager.EndpointFiles); err != nil {
+				t.Fatalf("Expected queued request %d to pass, got: %v", i, err)
+			}
+		}
+		// 20 requests per second, the 2 queued requests need about 100ms
+		if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+			t.Errorf("Expected queued requests to be spaced out, took only %s", elapsed)
+		}
+	})
+
+	t.Run("Client surfaces limiter errors as RateLimitError", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		limiter := figma_manager.NewRateLimiter(figma_manager.RateLimiterConfig{
+			Limits:  map[figma_manager.EndpointClass]figma_manager.RateLimit{figma_manager.EndpointFiles: {RequestsPerMinute: 1, Burst: 1}},
+			MaxWait: 10 * time.Millisecond,
+		})
+		client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRateLimiter(limiter)
+		ctx := context.WithValue(ctx, "figma_token", figmatest.ValidToken)
+
+		if _, err := client.GetFile(ctx, figmatest.SampleFileKey); err != nil {
+			t.Fatalf("Expected first request to pass, got: %v", err)
+		}
+		if _, err := client.GetFile(ctx, figmatest.SampleFileKey); !errors.IsRateLimited(err) {
+			t.Fatalf("Expected RateLimitError, got: %v", err)
+		}
+		if count := server.RequestCount("/v1/files"); count != 1 {
+			t.Errorf("Expected throttled request not to reach Figma, got %d requests", count)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package figma_manager
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig controls how FigmaClient retries rate limited (429) and server error (5xx) responses
+type RetryConfig struct {
+	MaxAttempts  int           // total attempts per request, including the first one. 1 disables retries
+	BaseDelay    time.Duration // backoff before the first retry, doubled on every following retry
+	MaxDelay     time.Duration // upper bound for a single backoff wait. Waits asked for by Retry-After are never shortened
+	MaxRetryTime time.Duration // per-request retry budget: total time we are willing to wait between attempts
+}
+
+// DefaultRetryConfig is tuned for parsing large team libraries that regularly hit Figma rate limits
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  5,
+		BaseDelay:    500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		MaxRetryTime: 2 * time.Minute,
+	}
+}
+
+// isRetryableStatus reports whether a Figma API status code is worth retrying
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// nextDelay returns how long to wait before the given retry (1-based).
+// Retry-After from Figma wins over our own backoff and is waited in full, retrying earlier would only be rejected
+// again; otherwise exponential backoff with jitter is used.
+func (r RetryConfig) nextDelay(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	backoff := r.BaseDelay << (retry - 1)
+	if backoff <= 0 || backoff > r.MaxDelay {
+		// also covers overflow of the shift for large retry counts
+		backoff = r.MaxDelay
+	}
+	// equal jitter: keep half of the backoff and randomize the other half, so concurrent parses spread out
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + rand.N(half)
+}
+
+// parseRetryAfter reads the Retry-After header, given either in seconds or as an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package figma_manager
+
+import (
+	"encoding/json"
+	"parser-service/models"
+	"sort"
+	"strings"
+)
+
+// ExtractStyles returns the shared styles of the file, with the value each resolves to, and where components
+// and instances use them. A style usage belongs to the closest component or instance around the node using it
+func (p *FigmaParser) ExtractStyles(apiResponse *FigmaAPIResponse) ([]models.Style, []models.StyleUsage) {
+	values := make(map[string]*models.StyleValue)
+	var usages []models.StyleUsage
+
+	var walk func(node Node, owner *Node)
+	walk = func(node Node, owner *Node) {
+		switch node.Type {
+		case "COMPONENT", "COMPONENT_SET", "INSTANCE":
+			owner = &node
+		}
+
+		properties := make([]string, 0, len(node.Styles))
+		for property := range node.Styles {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+
+		for _, property := range properties {
+			styleID := node.Styles[property]
+			style, ok := apiResponse.Styles[styleID]
+			if !ok {
+				continue
+			}
+			// the API sends both "fill" and "fills" depending on the node
+			property = strings.TrimSuffix(property, "s")
+
+			if values[styleID] == nil {
+				values[styleID] = styleValue(style.StyleType, property, &node)
+			}
+			if owner != nil {
+				usage := models.StyleUsage{StyleNodeID: styleID, NodeID: node.ID, Property: property, Active: true}
+				if owner.Type == "INSTANCE" {
+					usage.InstanceNodeID = owner.ID
+				} else {
+					usage.ComponentNodeID = owner.ID
+				}
+				usages = append(usages, usage)
+			}
+		}
+
+		for _, child := range node.Children {
+			walk(child, owner)
+		}
+	}
+	walk(apiResponse.Document, nil)
+
+	styleIDs := make([]string, 0, len(apiResponse.Styles))
+	for styleID := range apiResponse.Styles {
+		styleIDs = append(styleIDs, styleID)
+	}
+	sort.Strings(styleIDs)
+
+	styles := make([]models.Style, 0, len(styleIDs))
+	for _, styleID := range styleIDs {
+		style := apiResponse.Styles[styleID]
+		model := models.Style{
+			NodeID:      styleID,
+			Key:         style.Key,
+			Name:        style.Name,
+			StyleType:   style.StyleType,
+			Description: style.Description,
+			IsExternal:  style.Remote,
+			Active:      true,
+		}
+		if value := values[styleID]; value != nil {
+			model.Value, _ = json.Marshal(value)
+		}
+		styles = append(styles, model)
+	}
+
+	return styles, usages
+}
+
+// styleValue resolves what a style stands for from a node using it as property, nil when the node doesn't tell
+func styleValue(styleType, property string, node *Node) *models.StyleValue {
+	var value models.StyleValue
+	switch styleType {
+	case "FILL":
+		if property == "stroke" {
+			value.Paints = convertPaints(node.Strokes)
+		} else {
+			value.Paints = convertPaints(node.Fills)
+		}
+		if value.Paints == nil {
+			return nil
+		}
+	case "TEXT":
+		if node.Style == nil {
+			return nil
+		}
+		text := convertTypeStyle(*node.Style)
+		value.Text = &text
+	case "EFFECT":
+		if value.Effects = convertEffects(node.Effects); value.Effects == nil {
+			return nil
+		}
+	default:
+		// layout grids aren't decoded
+		return nil
+	}
+	return &value
+}
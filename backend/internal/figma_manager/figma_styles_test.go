@@ -0,0 +1,74 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaParser_Styles(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	styles := make(map[string]models.Style)
+	for _, style := range parsedData.Styles {
+		styles[style.Name] = style
+	}
+	if len(styles) != 4 {
+		t.Fatalf("Expected the 4 styles of the styles map, got %d", len(parsedData.Styles))
+	}
+
+	decodeValue := func(t *testing.T, style models.Style) models.StyleValue {
+		t.Helper()
+		var value models.StyleValue
+		if err := json.Unmarshal(style.Value, &value); err != nil {
+			t.Fatalf("Failed to decode the value of %s: %v", style.Name, err)
+		}
+		return value
+	}
+
+	t.Run("values are resolved from the nodes using them", func(t *testing.T) {
+		blue := styles["Brand/Blue"]
+		if value := decodeValue(t, blue); blue.StyleType != "FILL" || len(value.Paints) != 1 || value.Paints[0].Color != "#FFF2E6" {
+			t.Errorf("Expected Brand/Blue to resolve to the first fill using it, got %+v", value)
+		}
+		heading := styles["Heading/Large"]
+		if value := decodeValue(t, heading); !heading.IsExternal || value.Text == nil || value.Text.FontFamily != "Inter" {
+			t.Errorf("Expected an external Inter text style, got %+v", value)
+		}
+		if value := decodeValue(t, styles["Elevation/Card"]); len(value.Effects) != 3 {
+			t.Errorf("Expected the 3 card effects, got %+v", value.Effects)
+		}
+		if grid := styles["Layout/12 columns"]; grid.Value != nil {
+			t.Errorf("Expected no value for an unused grid style, got %s", grid.Value)
+		}
+	})
+
+	t.Run("usages belong to the closest component or instance", func(t *testing.T) {
+		type owner struct{ styleNodeID, component, instance, property string }
+		found := make(map[string]owner)
+		for _, usage := range parsedData.StyleUsages {
+			found[usage.NodeID] = owner{usage.StyleNodeID, usage.ComponentNodeID, usage.InstanceNodeID, usage.Property}
+		}
+		expected := map[string]owner{
+			"2:2":      {"5:1", "2:2", "", "fill"},
+			"2:4":      {"5:2", "2:2", "", "text"},
+			"3:1":      {"5:3", "3:1", "", "effect"},
+			"1:4":      {"5:1", "", "1:4", "fill"},
+			"I1:2;2:4": {"5:2", "", "1:2", "text"},
+		}
+		if len(found) != len(expected) {
+			t.Errorf("Expected %d style usages, got %d", len(expected), len(found))
+		}
+		for nodeID, want := range expected {
+			if got := found[nodeID]; got != want {
+				t.Errorf("Expected %s to use %+v, got %+v", nodeID, want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package figma_manager
+
+import (
+	"encoding/json"
+	"parser-service/models"
+)
+
+// ExtractTextNodes returns every TEXT node of the document with its copy and typography,
+// linked to its page and to the closest component or instance it belongs to
+func (p *FigmaParser) ExtractTextNodes(document Node) []models.TextNode {
+	var textNodes []models.TextNode
+
+	var walk func(node Node, pageNodeID string, owner *Node)
+	walk = func(node Node, pageNodeID string, owner *Node) {
+		switch node.Type {
+		case "CANVAS":
+			pageNodeID = node.ID
+		case "COMPONENT", "INSTANCE":
+			owner = &node
+		case "TEXT":
+			textNodes = append(textNodes, p.nodeToTextNode(node, pageNodeID, owner))
+		}
+
+		for _, child := range node.Children {
+			walk(child, pageNodeID, owner)
+		}
+	}
+	walk(document, "", nil)
+
+	return textNodes
+}
+
+// nodeToTextNode converts a TEXT node to a TextNode model
+func (p *FigmaParser) nodeToTextNode(node Node, pageNodeID string, owner *Node) models.TextNode {
+	textNode := models.TextNode{
+		PageNodeID: pageNodeID,
+		NodeID:     node.ID,
+		Name:       node.Name,
+		Characters: node.Characters,
+		Active:     true,
+	}
+
+	if owner != nil && owner.Type == "COMPONENT" {
+		textNode.ComponentNodeID = owner.ID
+	} else if owner != nil {
+		textNode.InstanceNodeID = owner.ID
+	}
+
+	if node.Style != nil {
+		style := convertTypeStyle(*node.Style)
+		textNode.FontFamily = style.FontFamily
+		textNode.FontWeight = style.FontWeight
+		textNode.FontSize = style.FontSize
+		textNode.Style, _ = json.Marshal(style)
+	}
+
+	if len(node.CharacterStyleOverrides) > 0 && len(node.StyleOverrideTable) > 0 {
+		overrides := models.TextStyleOverrides{
+			CharacterStyleOverrides: node.CharacterStyleOverrides,
+			StyleOverrideTable:      make(map[string]models.TextStyle, len(node.StyleOverrideTable)),
+		}
+		for key, style := range node.StyleOverrideTable {
+			overrides.StyleOverrideTable[key] = convertTypeStyle(style)
+		}
+		textNode.StyleOverrides, _ = json.Marshal(overrides)
+	}
+
+	return textNode
+}
+
+func convertTypeStyle(style TypeStyle) models.TextStyle {
+	return models.TextStyle{
+		FontFamily:          style.FontFamily,
+		FontPostScriptName:  style.FontPostScriptName,
+		FontWeight:          style.FontWeight,
+		FontSize:            style.FontSize,
+		Italic:              style.Italic,
+		LineHeightPx:        style.LineHeightPx,
+		LineHeightPercent:   style.LineHeightPercentFontSize,
+		LineHeightUnit:      style.LineHeightUnit,
+		LetterSpacing:       style.LetterSpacing,
+		TextCase:            style.TextCase,
+		TextDecoration:      style.TextDecoration,
+		TextAlignHorizontal: style.TextAlignHorizontal,
+		TextAlignVertical:   style.TextAlignVertical,
+	}
+}
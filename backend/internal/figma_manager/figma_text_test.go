@@ -0,0 +1,73 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaParser_ExtractTextNodes(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	textNodes := parser.ExtractTextNodes(loadSampleResponse(t).Document)
+
+	byNodeID := make(map[string]models.TextNode)
+	for _, textNode := range textNodes {
+		byNodeID[textNode.NodeID] = textNode
+	}
+	if len(byNodeID) != 4 {
+		t.Fatalf("Expected the 4 text nodes of the fixture, got %d", len(byNodeID))
+	}
+
+	t.Run("owners and pages", func(t *testing.T) {
+		tests := []struct {
+			nodeID, page, component, instance string
+		}{
+			{"I1:2;2:4", "0:1", "", "1:2"},
+			{"I1:4;3:3", "0:1", "", "1:4"},
+			{"2:4", "0:2", "2:2", ""},
+			{"3:3", "0:2", "3:1", ""},
+		}
+		for _, tt := range tests {
+			textNode := byNodeID[tt.nodeID]
+			if textNode.PageNodeID != tt.page || textNode.ComponentNodeID != tt.component || textNode.InstanceNodeID != tt.instance {
+				t.Errorf("Expected %s on page %q owned by component %q / instance %q, got %q %q %q", tt.nodeID,
+					tt.page, tt.component, tt.instance, textNode.PageNodeID, textNode.ComponentNodeID, textNode.InstanceNodeID)
+			}
+		}
+	})
+
+	t.Run("typography", func(t *testing.T) {
+		label := byNodeID["2:4"]
+		if label.Characters != "Button" || label.FontFamily != "Inter" || label.FontWeight != 500 || label.FontSize != 16 {
+			t.Errorf("Expected Inter 500 16px \"Button\", got %q %s %.0f %.0f", label.Characters, label.FontFamily, label.FontWeight, label.FontSize)
+		}
+
+		var style models.TextStyle
+		if err := json.Unmarshal(label.Style, &style); err != nil {
+			t.Fatalf("Failed to decode style: %v", err)
+		}
+		if style.LineHeightPx != 20 || style.LineHeightPercent != 125 || style.LetterSpacing != 0.5 ||
+			style.TextCase != "UPPER" || style.TextAlignHorizontal != "CENTER" {
+			t.Errorf("Expected the full label style, got %+v", style)
+		}
+
+		var overrides models.TextStyleOverrides
+		if err := json.Unmarshal(label.StyleOverrides, &overrides); err != nil {
+			t.Fatalf("Failed to decode style overrides: %v", err)
+		}
+		if len(overrides.CharacterStyleOverrides) != 6 || overrides.CharacterStyleOverrides[0] != 1 {
+			t.Errorf("Expected the first character to use override 1, got %v", overrides.CharacterStyleOverrides)
+		}
+		if override := overrides.StyleOverrideTable["1"]; override.FontWeight != 700 || override.TextDecoration != "UNDERLINE" || override.FontFamily != "" {
+			t.Errorf("Expected a bold underlined override inheriting the font family, got %+v", override)
+		}
+	})
+
+	t.Run("text without style", func(t *testing.T) {
+		textNode := byNodeID["I1:2;2:4"]
+		if textNode.Characters != "Get started" || textNode.Style != nil || textNode.StyleOverrides != nil {
+			t.Errorf("Expected copy without style, got %+v", textNode)
+		}
+	})
+}
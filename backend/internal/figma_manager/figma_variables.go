@@ -0,0 +1,411 @@
+package figma_manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"parser-service/internal/errors"
+	"parser-service/models"
+	"sort"
+	"strings"
+)
+
+// Variable is a variable of GET /v1/files/:key/variables/local, with its raw value by mode ID.
+// A value is a literal (color, number, string or boolean) or a VariableAlias
+type Variable struct {
+	ID                   string                     `json:"id"`
+	Name                 string                     `json:"name"`
+	Key                  string                     `json:"key"`
+	VariableCollectionID string                     `json:"variableCollectionId"`
+	ResolvedType         string                     `json:"resolvedType"` // COLOR, FLOAT, STRING or BOOLEAN
+	Description          string                     `json:"description"`
+	Remote               bool                       `json:"remote"`
+	HiddenFromPublishing bool                       `json:"hiddenFromPublishing"`
+	Scopes               []string                   `json:"scopes,omitempty"`
+	ValuesByMode         map[string]json.RawMessage `json:"valuesByMode"`
+}
+
+// VariableCollection is a variable collection of GET /v1/files/:key/variables/local
+type VariableCollection struct {
+	ID                   string                   `json:"id"`
+	Name                 string                   `json:"name"`
+	Key                  string                   `json:"key"`
+	Modes                []VariableCollectionMode `json:"modes"`
+	DefaultModeID        string                   `json:"defaultModeId"`
+	Remote               bool                     `json:"remote"`
+	HiddenFromPublishing bool                     `json:"hiddenFromPublishing"`
+	VariableIDs          []string                 `json:"variableIds"`
+}
+
+type VariableCollectionMode struct {
+	ModeID string `json:"modeId"`
+	Name   string `json:"name"`
+}
+
+// VariableAlias is a reference to another variable, as a variable value or in the boundVariables of a node
+type VariableAlias struct {
+	Type string `json:"type"` // VARIABLE_ALIAS
+	ID   string `json:"id"`
+}
+
+// LocalVariables is the meta of GET /v1/files/:key/variables/local: the variables defined in the file and the
+// library variables it uses, by ID
+type LocalVariables struct {
+	Variables           map[string]Variable           `json:"variables"`
+	VariableCollections map[string]VariableCollection `json:"variableCollections"`
+}
+
+// PublishedVariable is a variable or collection of GET /v1/files/:key/variables/published
+type PublishedVariable struct {
+	ID           string `json:"id"`
+	SubscribedID string `json:"subscribed_id"` // ID of the variable in the files using the library
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// PublishedVariables is the meta of GET /v1/files/:key/variables/published, by ID
+type PublishedVariables struct {
+	Variables           map[string]PublishedVariable `json:"variables"`
+	VariableCollections map[string]PublishedVariable `json:"variableCollections"`
+}
+
+// FileVariables is what the variables endpoints returned for a file. Published is nil when unavailable
+type FileVariables struct {
+	Local     *LocalVariables
+	Published *PublishedVariables
+}
+
+// GetLocalVariables retrieves the variables of a file with their values in every mode
+func (c *FigmaClient) GetLocalVariables(ctx context.Context, fileKey string) (*LocalVariables, error) {
+	var variables LocalVariables
+	if err := c.getFileVariables(ctx, fileKey, "local", &variables); err != nil {
+		return nil, err
+	}
+	return &variables, nil
+}
+
+// GetPublishedVariables retrieves the variables a file published to its team library
+func (c *FigmaClient) GetPublishedVariables(ctx context.Context, fileKey string) (*PublishedVariables, error) {
+	var variables PublishedVariables
+	if err := c.getFileVariables(ctx, fileKey, "published", &variables); err != nil {
+		return nil, err
+	}
+	return &variables, nil
+}
+
+// getFileVariables requests /files/:key/variables/:scope and decodes the "meta" object of the response into meta
+func (c *FigmaClient) getFileVariables(ctx context.Context, fileKey, scope string, meta interface{}) error {
+	if fileKey == "" {
+		return errors.InvalidInput("file key cannot be empty")
+	}
+
+	fileKey = c.extractFileKeyFromURL(fileKey)
+	endpoint := fmt.Sprintf("%s/files/%s/variables/%s", c.baseURL, fileKey, scope)
+
+	response, err := c.makeRequest(ctx, EndpointVariables, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get %s variables from Figma API: %w", scope, err)
+	}
+
+	var envelope struct {
+		Meta json.RawMessage `json:"meta"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		return errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma %s variables response", scope)
+	}
+	if len(envelope.Meta) == 0 {
+		return errors.Upstream("Figma %s variables response has no meta", scope)
+	}
+	if err := json.Unmarshal(envelope.Meta, meta); err != nil {
+		return errors.Wrap(errors.ErrUpstream, err, "failed to parse Figma %s variables response", scope)
+	}
+	return nil
+}
+
+// ExtractVariables returns the variable collections and variables of the file, with every alias resolved down to
+// a literal value, and where components and instances are bound to them. A binding belongs to the closest component
+// or instance around the bound node. Nothing is returned when the file was fetched without its variables
+func (p *FigmaParser) ExtractVariables(apiResponse *FigmaAPIResponse) ([]models.VariableCollection, []models.Variable, []models.VariableBinding) {
+	if apiResponse.Variables == nil || apiResponse.Variables.Local == nil {
+		return nil, nil, nil
+	}
+	local := apiResponse.Variables.Local
+	published := apiResponse.Variables.Published
+	if published == nil {
+		published = &PublishedVariables{}
+	}
+	resolver := &variableResolver{variables: local.Variables, collections: local.VariableCollections}
+
+	collectionIDs := make([]string, 0, len(local.VariableCollections))
+	for collectionID := range local.VariableCollections {
+		collectionIDs = append(collectionIDs, collectionID)
+	}
+	sort.Strings(collectionIDs)
+
+	collections := make([]models.VariableCollection, 0, len(collectionIDs))
+	for _, collectionID := range collectionIDs {
+		collection := local.VariableCollections[collectionID]
+		modes := make([]models.VariableMode, 0, len(collection.Modes))
+		for _, mode := range collection.Modes {
+			modes = append(modes, models.VariableMode{ModeID: mode.ModeID, Name: mode.Name})
+		}
+		_, isPublished := published.VariableCollections[collectionID]
+		model := models.VariableCollection{
+			FigmaCollectionID: collectionID,
+			Key:               collection.Key,
+			Name:              collection.Name,
+			DefaultModeID:     collection.DefaultModeID,
+			IsExternal:        collection.Remote,
+			IsPublished:       isPublished,
+			Active:            true,
+		}
+		model.Modes, _ = json.Marshal(modes)
+		collections = append(collections, model)
+	}
+
+	// Variables are listed by collection, then by name
+	variableIDs := make([]string, 0, len(local.Variables))
+	for variableID := range local.Variables {
+		variableIDs = append(variableIDs, variableID)
+	}
+	sort.Slice(variableIDs, func(i, j int) bool {
+		a, b := local.Variables[variableIDs[i]], local.Variables[variableIDs[j]]
+		if a.VariableCollectionID != b.VariableCollectionID {
+			return a.VariableCollectionID < b.VariableCollectionID
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	variables := make([]models.Variable, 0, len(variableIDs))
+	for _, variableID := range variableIDs {
+		variable := local.Variables[variableID]
+		_, isPublished := published.Variables[variableID]
+		model := models.Variable{
+			FigmaCollectionID: variable.VariableCollectionID,
+			FigmaVariableID:   variableID,
+			Key:               variable.Key,
+			Name:              variable.Name,
+			ResolvedType:      variable.ResolvedType,
+			Description:       variable.Description,
+			IsExternal:        variable.Remote,
+			IsPublished:       isPublished,
+			Values:            resolver.values(variableID),
+			Active:            true,
+		}
+		if len(variable.Scopes) > 0 {
+			model.Scopes, _ = json.Marshal(variable.Scopes)
+		}
+		variables = append(variables, model)
+	}
+
+	return collections, variables, p.extractVariableBindings(apiResponse.Document, local.Variables)
+}
+
+// extractVariableBindings lists the variables bound to nodes inside components and instances
+func (p *FigmaParser) extractVariableBindings(document Node, variables map[string]Variable) []models.VariableBinding {
+	var bindings []models.VariableBinding
+
+	var walk func(node Node, owner *Node)
+	walk = func(node Node, owner *Node) {
+		switch node.Type {
+		case "COMPONENT", "COMPONENT_SET", "INSTANCE":
+			owner = &node
+		}
+
+		if owner != nil {
+			properties := make([]string, 0, len(node.BoundVariables))
+			for property := range node.BoundVariables {
+				properties = append(properties, property)
+			}
+			sort.Strings(properties)
+
+			for _, property := range properties {
+				for _, bound := range boundVariableAliases(property, node.BoundVariables[property]) {
+					if _, ok := variables[bound.variableID]; !ok {
+						continue
+					}
+					binding := models.VariableBinding{FigmaVariableID: bound.variableID, NodeID: node.ID, Property: bound.property, Active: true}
+					if owner.Type == "INSTANCE" {
+						binding.InstanceNodeID = owner.ID
+					} else {
+						binding.ComponentNodeID = owner.ID
+					}
+					bindings = append(bindings, binding)
+				}
+			}
+		}
+
+		for _, child := range node.Children {
+			walk(child, owner)
+		}
+	}
+	walk(document, nil)
+
+	return bindings
+}
+
+type boundVariable struct {
+	property   string
+	variableID string
+}
+
+// boundVariableAliases decodes an entry of the boundVariables of a node: a single alias (itemSpacing, paddingLeft...),
+// a list of aliases (fills, strokes, effects...) or aliases by property name (componentProperties).
+// Each variable is listed once per property
+func boundVariableAliases(property string, raw json.RawMessage) []boundVariable {
+	if alias, ok := decodeVariableAlias(raw); ok {
+		return []boundVariable{{property: property, variableID: alias.ID}}
+	}
+
+	var bound []boundVariable
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		seen := make(map[string]bool, len(list))
+		for _, item := range list {
+			if alias, ok := decodeVariableAlias(item); ok && !seen[alias.ID] {
+				seen[alias.ID] = true
+				bound = append(bound, boundVariable{property: property, variableID: alias.ID})
+			}
+		}
+		return bound
+	}
+
+	var byName map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byName); err == nil {
+		names := make([]string, 0, len(byName))
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			bound = append(bound, boundVariableAliases(property+"."+name, byName[name])...)
+		}
+	}
+	return bound
+}
+
+// decodeVariableAlias decodes raw if it is a VARIABLE_ALIAS
+func decodeVariableAlias(raw json.RawMessage) (VariableAlias, bool) {
+	var alias VariableAlias
+	if err := json.Unmarshal(raw, &alias); err != nil {
+		return alias, false
+	}
+	return alias, alias.Type == "VARIABLE_ALIAS" && alias.ID != ""
+}
+
+// variableResolver follows variable aliases down to literal values
+type variableResolver struct {
+	variables   map[string]Variable
+	collections map[string]VariableCollection
+}
+
+// aliasStep is a variable visited while resolving an alias chain, in a given mode
+type aliasStep struct {
+	variableID string
+	modeID     string
+	name       string
+}
+
+// values returns the value of a variable in every mode of its collection, in the collection's mode order
+func (r *variableResolver) values(variableID string) []models.VariableValue {
+	variable := r.variables[variableID]
+
+	var modes []VariableCollectionMode
+	if collection, ok := r.collections[variable.VariableCollectionID]; ok {
+		modes = collection.Modes
+	} else {
+		for modeID := range variable.ValuesByMode {
+			modes = append(modes, VariableCollectionMode{ModeID: modeID})
+		}
+		sort.Slice(modes, func(i, j int) bool { return modes[i].ModeID < modes[j].ModeID })
+	}
+
+	values := make([]models.VariableValue, 0, len(modes))
+	for _, mode := range modes {
+		raw, ok := variable.ValuesByMode[mode.ModeID]
+		if !ok {
+			continue
+		}
+		value := models.VariableValue{ModeID: mode.ModeID, ModeName: mode.Name, Active: true}
+		if alias, ok := decodeVariableAlias(raw); ok {
+			value.AliasOf = alias.ID
+			value.AliasName = r.variables[alias.ID].Name
+		} else if literal, err := variableLiteral(variable.ResolvedType, raw); err == nil {
+			value.Value = literal
+		}
+
+		resolved, err := r.resolve(variableID, mode.ModeID, nil)
+		if err != nil {
+			value.ResolutionError = err.Error()
+		} else {
+			value.ResolvedValue = resolved
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// resolve follows the value of a variable in a mode down to a literal. An alias to a variable of another
+// collection takes the mode with the same ID when that collection has it, its default mode otherwise.
+// Aliases looping back to a variable already visited in the same mode are reported as a cycle
+func (r *variableResolver) resolve(variableID, modeID string, path []aliasStep) (json.RawMessage, error) {
+	variable, ok := r.variables[variableID]
+	if !ok {
+		return nil, fmt.Errorf("alias to unknown variable %s", variableID)
+	}
+	if collection, ok := r.collections[variable.VariableCollectionID]; ok && !collection.hasMode(modeID) {
+		modeID = collection.DefaultModeID
+	}
+
+	for i, step := range path {
+		if step.variableID == variableID && step.modeID == modeID {
+			names := make([]string, 0, len(path)-i+1)
+			for _, visited := range path[i:] {
+				names = append(names, visited.name)
+			}
+			names = append(names, variable.Name)
+			return nil, fmt.Errorf("alias cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+	path = append(path, aliasStep{variableID: variableID, modeID: modeID, name: variable.Name})
+
+	raw, ok := variable.ValuesByMode[modeID]
+	if !ok {
+		return nil, fmt.Errorf("%s has no value in mode %s", variable.Name, modeID)
+	}
+	if alias, ok := decodeVariableAlias(raw); ok {
+		return r.resolve(alias.ID, modeID, path)
+	}
+	return variableLiteral(variable.ResolvedType, raw)
+}
+
+func (c VariableCollection) hasMode(modeID string) bool {
+	for _, mode := range c.Modes {
+		if mode.ModeID == modeID {
+			return true
+		}
+	}
+	return false
+}
+
+// variableLiteral converts a literal variable value for storage: colors become hex strings, numbers, strings and
+// booleans are kept as they are
+func variableLiteral(resolvedType string, raw json.RawMessage) (json.RawMessage, error) {
+	if resolvedType == "COLOR" {
+		var color Color
+		if err := json.Unmarshal(raw, &color); err != nil {
+			return nil, fmt.Errorf("invalid color value: %w", err)
+		}
+		return json.Marshal(color.Hex())
+	}
+
+	var literal interface{}
+	if err := json.Unmarshal(raw, &literal); err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", strings.ToLower(resolvedType), err)
+	}
+	return json.Marshal(literal)
+}
@@ -0,0 +1,130 @@
+package figma_manager_test
+
+import (
+	"context"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaManager_Variables(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	parsedData, err := newTestManager(server).ParseFigmaFileFromKey(ctx, figmatest.SampleFileKey)
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	t.Run("collections are flagged when published", func(t *testing.T) {
+		if len(parsedData.VariableCollections) != 2 {
+			t.Fatalf("Expected 2 variable collections, got %d", len(parsedData.VariableCollections))
+		}
+		for _, collection := range parsedData.VariableCollections {
+			if published := collection.Name == "Primitives"; collection.IsPublished != published {
+				t.Errorf("Expected %s to have is_published %v", collection.Name, published)
+			}
+		}
+	})
+
+	variables := make(map[string]models.Variable)
+	for _, variable := range parsedData.Variables {
+		variables[variable.Name] = variable
+	}
+	if len(variables) != 8 {
+		t.Fatalf("Expected 8 variables, got %d", len(parsedData.Variables))
+	}
+	valueIn := func(t *testing.T, name, modeName string) models.VariableValue {
+		t.Helper()
+		for _, value := range variables[name].Values {
+			if value.ModeName == modeName {
+				return value
+			}
+		}
+		t.Fatalf("Expected %s to have a value in mode %s", name, modeName)
+		return models.VariableValue{}
+	}
+
+	t.Run("aliases are resolved transitively in every mode", func(t *testing.T) {
+		light := valueIn(t, "color/action", "Light")
+		if light.AliasName != "color/primary" || light.Value != nil || string(light.ResolvedValue) != `"#3366FF"` {
+			t.Errorf("Expected color/action to alias color/primary and resolve to blue in Light, got %+v", light)
+		}
+		if dark := valueIn(t, "color/action", "Dark"); string(dark.ResolvedValue) != `"#1A1A1A"` {
+			t.Errorf("Expected color/action to resolve to gray in Dark, got %s", dark.ResolvedValue)
+		}
+		// Primitives has no Light mode, its default mode is used
+		if space := valueIn(t, "space/md", "Light"); string(space.ResolvedValue) != "16" {
+			t.Errorf("Expected space/md to resolve to 16 in Light, got %s", space.ResolvedValue)
+		}
+		if space := valueIn(t, "space/md", "Dark"); string(space.Value) != "20" || string(space.ResolvedValue) != "20" {
+			t.Errorf("Expected the literal 20 in Dark, got %+v", space)
+		}
+	})
+
+	t.Run("alias cycles are reported", func(t *testing.T) {
+		loop := valueIn(t, "loop/a", "Light")
+		if loop.ResolvedValue != nil || loop.ResolutionError != "alias cycle: loop/a -> loop/b -> loop/a" {
+			t.Errorf("Expected an alias cycle error, got %+v", loop)
+		}
+		// the cycle only exists in Light, Dark ends on a literal
+		if dark := valueIn(t, "loop/b", "Dark"); dark.ResolutionError != "" || string(dark.ResolvedValue) != `"#00000080"` {
+			t.Errorf("Expected loop/b to resolve in Dark, got %+v", dark)
+		}
+	})
+
+	t.Run("bindings belong to the closest component or instance", func(t *testing.T) {
+		type binding struct{ variableID, component, instance string }
+		found := make(map[string]binding)
+		for _, b := range parsedData.VariableBindings {
+			found[b.NodeID+" "+b.Property] = binding{b.FigmaVariableID, b.ComponentNodeID, b.InstanceNodeID}
+		}
+		expected := map[string]binding{
+			"1:4 fills":        {"VariableID:11:2", "", "1:4"},
+			"2:2 fills":        {"VariableID:11:4", "2:2", ""},
+			"2:2 itemSpacing":  {"VariableID:11:5", "2:2", ""},
+			"3:1 paddingLeft":  {"VariableID:11:5", "3:1", ""},
+			"3:1 paddingRight": {"VariableID:11:5", "3:1", ""},
+		}
+		if len(found) != len(expected) {
+			t.Errorf("Expected %d variable bindings, got %d", len(expected), len(found))
+		}
+		for key, want := range expected {
+			if got := found[key]; got != want {
+				t.Errorf("Expected %s bound to %+v, got %+v", key, want, got)
+			}
+		}
+	})
+
+	t.Run("variables failures don't fail the parse", func(t *testing.T) {
+		server := figmatest.NewServer()
+		defer server.Close()
+		server.FailNext("/v1/files/"+figmatest.SampleFileKey+"/variables", 500, 10, nil)
+
+		parsedData, err := newTestManager(server).ParseFigmaFileFromKey(ctx, figmatest.SampleFileKey)
+		if err != nil {
+			t.Fatalf("Expected parse to succeed when Figma fails variables, got: %v", err)
+		}
+		if len(parsedData.Variables) != 0 || len(parsedData.Components) == 0 {
+			t.Errorf("Expected components without variables, got %d components and %d variables", len(parsedData.Components), len(parsedData.Variables))
+		}
+		if count := server.RequestCount("/v1/files/" + figmatest.SampleFileKey + "/variables"); count != fastRetryConfig.MaxAttempts {
+			t.Errorf("Expected the variables request to be retried %d times, got %d", fastRetryConfig.MaxAttempts, count)
+		}
+	})
+
+	t.Run("files without variables access are parsed without them", func(t *testing.T) {
+		server.AddVariables(figmatest.SampleFileKey, nil, nil)
+		parsedData, err := newTestManager(server).ParseFigmaFileFromKey(ctx, figmatest.SampleFileKey)
+		if err != nil {
+			t.Fatalf("Expected parse to succeed when Figma refuses variables, got: %v", err)
+		}
+		if len(parsedData.Variables) != 0 || len(parsedData.VariableBindings) != 0 {
+			t.Errorf("Expected no variables, got %d variables and %d bindings", len(parsedData.Variables), len(parsedData.VariableBindings))
+		}
+		if len(parsedData.Components) == 0 {
+			t.Error("Expected components to be parsed")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package figma_manager
+
+import (
+	"encoding/json"
+	"parser-service/models"
+	"sort"
+	"strings"
+)
+
+// assignComponentSets links every variant to its component set and parses its variant key/value pairs
+// from its name. The set comes from the document tree, or from the components map for sets not in the tree
+func (p *FigmaParser) assignComponentSets(document Node, components []models.Component, apiComponents map[string]Component) {
+	setByVariant := make(map[string]string)
+	var walk func(node Node)
+	walk = func(node Node) {
+		for _, child := range node.Children {
+			if node.Type == "COMPONENT_SET" && child.Type == "COMPONENT" {
+				setByVariant[child.ID] = node.ID
+			}
+			walk(child)
+		}
+	}
+	walk(document)
+
+	for i := range components {
+		if components[i].Type != "COMPONENT" {
+			continue
+		}
+		setNodeID, ok := setByVariant[components[i].NodeID]
+		if !ok {
+			setNodeID = apiComponents[components[i].NodeID].ComponentSetID
+		}
+		if setNodeID == "" {
+			continue
+		}
+
+		components[i].ComponentSetNodeID = setNodeID
+		if variantProperties := parseVariantProperties(components[i].Name); variantProperties != nil {
+			components[i].VariantProperties, _ = json.Marshal(variantProperties)
+		}
+	}
+}
+
+// parseVariantProperties parses a variant name like "Size=Large, State=Default", nil when it has no key/value pairs
+func parseVariantProperties(name string) map[string]string {
+	properties := make(map[string]string)
+	for _, pair := range strings.Split(name, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		properties[key] = strings.TrimSpace(value)
+	}
+
+	if len(properties) == 0 {
+		return nil
+	}
+	return properties
+}
+
+// convertPropertyDefinitions encodes the component property definitions of a component or set, nil without any
+func convertPropertyDefinitions(definitions map[string]ComponentPropertyDefinition) json.RawMessage {
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	converted := make(map[string]models.ComponentPropertyDefinition, len(definitions))
+	for name, definition := range definitions {
+		model := models.ComponentPropertyDefinition{
+			Type:           definition.Type,
+			DefaultValue:   definition.DefaultValue,
+			VariantOptions: definition.VariantOptions,
+		}
+		for _, preferred := range definition.PreferredValues {
+			model.PreferredValues = append(model.PreferredValues, models.PreferredValue{Type: preferred.Type, Key: preferred.Key})
+		}
+		converted[name] = model
+	}
+
+	definitionsJSON, _ := json.Marshal(converted)
+	return definitionsJSON
+}
+
+// populateInstanceOverrides stores the component property values of an instance and the fields it overrides
+func (p *FigmaParser) populateInstanceOverrides(node *Node, instance *models.Instance) {
+	if len(node.ComponentProperties) > 0 {
+		properties := make(map[string]models.InstanceComponentProperty, len(node.ComponentProperties))
+		for name, property := range node.ComponentProperties {
+			properties[name] = models.InstanceComponentProperty{Type: property.Type, Value: property.Value}
+		}
+		instance.ComponentProperties, _ = json.Marshal(properties)
+	}
+
+	if len(node.Overrides) > 0 {
+		overrides := make([]models.InstanceOverride, 0, len(node.Overrides))
+		for _, override := range node.Overrides {
+			overrides = append(overrides, models.InstanceOverride{NodeID: override.ID, OverriddenFields: override.OverriddenFields})
+			instance.OverrideCount += len(override.OverriddenFields)
+		}
+		instance.Overrides, _ = json.Marshal(overrides)
+	}
+}
+
+// sortComponentSetsFirst moves component sets before other components, keeping the order otherwise,
+// so sets get saved (and get a database ID) before the variants referencing them
+func (p *FigmaParser) sortComponentSetsFirst(components []models.Component) {
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].Type == "COMPONENT_SET" && components[j].Type != "COMPONENT_SET"
+	})
+}
@@ -0,0 +1,113 @@
+package figma_manager_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/models"
+	"testing"
+)
+
+func TestFigmaParser_ComponentSets(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	components := make(map[string]models.Component)
+	for i, component := range parsedData.Components {
+		components[component.NodeID] = component
+		if i > 0 && component.Type == "COMPONENT_SET" && parsedData.Components[i-1].Type != "COMPONENT_SET" {
+			t.Errorf("Expected component sets before other components, found %s after %s", component.NodeID, parsedData.Components[i-1].NodeID)
+		}
+	}
+
+	t.Run("variants are linked to their set", func(t *testing.T) {
+		for _, nodeID := range []string{"2:2", "2:3"} {
+			if components[nodeID].ComponentSetNodeID != "2:1" {
+				t.Errorf("Expected variant %s in set 2:1, got %q", nodeID, components[nodeID].ComponentSetNodeID)
+			}
+		}
+		if components["3:1"].ComponentSetNodeID != "" || components["3:1"].VariantProperties != nil {
+			t.Errorf("Expected Card not to be a variant, got %+v", components["3:1"])
+		}
+
+		var variant map[string]string
+		if err := json.Unmarshal(components["2:3"].VariantProperties, &variant); err != nil {
+			t.Fatalf("Failed to decode variant properties: %v", err)
+		}
+		if len(variant) != 2 || variant["Size"] != "Small" || variant["State"] != "Default" {
+			t.Errorf("Expected Size=Small, State=Default, got %v", variant)
+		}
+	})
+
+	t.Run("property definitions", func(t *testing.T) {
+		var definitions map[string]models.ComponentPropertyDefinition
+		if err := json.Unmarshal(components["2:1"].PropertyDefinitions, &definitions); err != nil {
+			t.Fatalf("Failed to decode property definitions: %v", err)
+		}
+		if len(definitions) != 5 {
+			t.Fatalf("Expected 5 property definitions on the Button set, got %d", len(definitions))
+		}
+		if size := definitions["Size"]; size.Type != "VARIANT" || size.DefaultValue != "Large" || len(size.VariantOptions) != 2 {
+			t.Errorf("Expected the Size variant with 2 options, got %+v", size)
+		}
+		if showIcon := definitions["Show icon#2:5"]; showIcon.Type != "BOOLEAN" || showIcon.DefaultValue != true {
+			t.Errorf("Expected a BOOLEAN defaulting to true, got %+v", showIcon)
+		}
+		icon := definitions["Icon#2:6"]
+		if icon.Type != "INSTANCE_SWAP" || icon.DefaultValue != "9:9" || len(icon.PreferredValues) != 1 || icon.PreferredValues[0].Key != "avatar-remote-key" {
+			t.Errorf("Expected an INSTANCE_SWAP with a preferred value, got %+v", icon)
+		}
+		if components["2:2"].PropertyDefinitions != nil {
+			t.Errorf("Expected variants to leave definitions to their set, got %s", components["2:2"].PropertyDefinitions)
+		}
+	})
+}
+
+func TestFigmaParser_InstanceOverrides(t *testing.T) {
+	parser := figma_manager.NewFigmaParser()
+	parsedData, err := parser.ParseFile(loadSampleResponse(t), figmatest.SampleFileKey, "")
+	if err != nil {
+		t.Fatalf("Failed to parse sample file: %v", err)
+	}
+
+	instances := make(map[string]models.Instance)
+	for _, instance := range parsedData.Instances {
+		instances[instance.NodeID] = instance
+	}
+
+	t.Run("component property values", func(t *testing.T) {
+		var properties map[string]models.InstanceComponentProperty
+		if err := json.Unmarshal(instances["1:2"].ComponentProperties, &properties); err != nil {
+			t.Fatalf("Failed to decode component properties: %v", err)
+		}
+		if label := properties["Label#2:0"]; label.Type != "TEXT" || label.Value != "Get started" {
+			t.Errorf("Expected the Get started label, got %+v", label)
+		}
+		if icon := properties["Show icon#2:5"]; icon.Type != "BOOLEAN" || icon.Value != false {
+			t.Errorf("Expected the icon to be hidden, got %+v", icon)
+		}
+		if instances["1:6"].ComponentProperties != nil {
+			t.Errorf("Expected no component properties on the avatar, got %s", instances["1:6"].ComponentProperties)
+		}
+	})
+
+	t.Run("overridden fields are counted", func(t *testing.T) {
+		var overrides []models.InstanceOverride
+		if err := json.Unmarshal(instances["1:4"].Overrides, &overrides); err != nil {
+			t.Fatalf("Failed to decode overrides: %v", err)
+		}
+		if len(overrides) != 1 || overrides[0].NodeID != "1:4" || len(overrides[0].OverriddenFields) != 2 {
+			t.Errorf("Expected fills and effects overridden on the card, got %+v", overrides)
+		}
+
+		expected := map[string]int{"1:2": 1, "1:4": 2, "1:6": 0}
+		for nodeID, count := range expected {
+			if instances[nodeID].OverrideCount != count {
+				t.Errorf("Expected %d overridden fields on %s, got %d", count, nodeID, instances[nodeID].OverrideCount)
+			}
+		}
+	})
+}
@@ -0,0 +1,337 @@
+// Package figmatest provides an in-process fake of the Figma REST API, serving fixture files, their variables,
+// rendered images, /me and scripted error responses so tests can run without network access.
+package figmatest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ValidToken is the only token the fake server accepts
+	ValidToken = "figd_fake-test-token"
+	// SampleFileKey is the key the sample fixture is served under
+	SampleFileKey = "FixtureFileKey123"
+	// SampleFileURL is a Figma design URL pointing to the sample fixture
+	SampleFileURL = "https://www.figma.com/design/" + SampleFileKey + "/Fixture-Design-System"
+	// SampleTeamID is the team the team library fixture is published by
+	SampleTeamID = "1234567890"
+	// TeamLibraryPageLimit caps the items of a team library page, whatever page_size asks, so clients must paginate
+	TeamLibraryPageLimit = 2
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns the raw content of a file in the fixtures directory, e.g. "sample_file.json"
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("figmatest: missing fixture %s: %v", name, err))
+	}
+	return data
+}
+
+// SampleFile returns the sample file fixture, a GET /v1/files response body
+func SampleFile() []byte {
+	return Fixture("sample_file.json")
+}
+
+// TeamLibrary returns the team library fixture: the "components", "component_sets" and "styles"
+// lists served by the GET /v1/teams/{id}/... endpoints
+func TeamLibrary() []byte {
+	return Fixture("team_library.json")
+}
+
+// Variables returns the local and published variables fixtures of the sample file, the
+// GET /v1/files/{key}/variables/local and /published response bodies
+func Variables() (local, published []byte) {
+	return Fixture("variables_local.json"), Fixture("variables_published.json")
+}
+
+// failure is a scripted error response
+type failure struct {
+	pathPrefix string
+	status     int
+	headers    map[string]string
+	remaining  int
+}
+
+// Server is a fake Figma API. Use BaseURL() as the client base URL.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	files     map[string][]byte
+	variables map[string]map[string][]byte            // file key -> "local" or "published" -> response body
+	teams     map[string]map[string][]json.RawMessage // team ID -> resource -> items
+	failures  []*failure
+	requests  map[string]int
+}
+
+// NewServer starts a fake Figma API serving the sample fixture under SampleFileKey. Callers must Close it.
+func NewServer() *Server {
+	s := &Server{
+		files:     map[string][]byte{SampleFileKey: SampleFile()},
+		variables: map[string]map[string][]byte{},
+		teams:     map[string]map[string][]json.RawMessage{},
+		requests:  map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/me", s.handleMe)
+	mux.HandleFunc("GET /v1/files/{key}", s.handleFile)
+	mux.HandleFunc("GET /v1/files/{key}/variables/{scope}", s.handleVariables)
+	mux.HandleFunc("GET /v1/files/{key}/images", s.handleImageFills)
+	mux.HandleFunc("GET /v1/images/{key}", s.handleImages)
+	mux.HandleFunc("GET /renders/{name}", s.handleRender)
+	mux.HandleFunc("GET /v1/teams/{id}/{resource}", s.handleTeamLibrary)
+
+	local, published := Variables()
+	s.AddVariables(SampleFileKey, local, published)
+	s.AddTeamLibrary(SampleTeamID, TeamLibrary())
+	s.Server = httptest.NewServer(s.withMiddleware(mux))
+	return s
+}
+
+// BaseURL returns the equivalent of https://api.figma.com/v1 for this server
+func (s *Server) BaseURL() string {
+	return s.URL + "/v1"
+}
+
+// AddFile serves body as the GET /v1/files/{fileKey} response
+func (s *Server) AddFile(fileKey string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[fileKey] = body
+}
+
+// AddVariables serves local and published as the variables of fileKey. A nil body answers 403, like Figma does
+// for files whose plan doesn't include variables
+func (s *Server) AddVariables(fileKey string, local, published []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.variables[fileKey] = map[string][]byte{"local": local, "published": published}
+}
+
+// AddTeamLibrary serves the "components", "component_sets" and "styles" lists of body under teamID
+func (s *Server) AddTeamLibrary(teamID string, body []byte) {
+	var library map[string][]json.RawMessage
+	if err := json.Unmarshal(body, &library); err != nil {
+		panic(fmt.Sprintf("figmatest: invalid team library: %v", err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[teamID] = library
+}
+
+// FailNext makes the next n requests whose path starts with pathPrefix (e.g. "/v1/files")
+// answer with status and the given extra headers, such as Retry-After
+func (s *Server) FailNext(pathPrefix string, status int, n int, headers map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{pathPrefix: pathPrefix, status: status, headers: headers, remaining: n})
+}
+
+// RequestCount returns how many requests were received with a path starting with pathPrefix
+func (s *Server) RequestCount(pathPrefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for path, n := range s.requests {
+		if strings.HasPrefix(path, pathPrefix) {
+			count += n
+		}
+	}
+	return count
+}
+
+// withMiddleware records requests, plays scripted failures and enforces the token like the real API does
+func (s *Server) withMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		var scripted *failure
+		for _, f := range s.failures {
+			if f.remaining > 0 && strings.HasPrefix(r.URL.Path, f.pathPrefix) {
+				f.remaining--
+				scripted = f
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		if scripted != nil {
+			for key, value := range scripted.headers {
+				w.Header().Set(key, value)
+			}
+			writeError(w, scripted.status, http.StatusText(scripted.status))
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/v1/") && r.Header.Get("X-Figma-Token") != ValidToken {
+			writeError(w, http.StatusForbidden, "Invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":     "1",
+		"email":  "designer@example.com",
+		"handle": "Fixture Designer",
+	})
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	body, ok := s.files[r.PathValue("key")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (s *Server) handleVariables(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.files[r.PathValue("key")]
+	variables := s.variables[r.PathValue("key")]
+	s.mu.Unlock()
+	scope := r.PathValue("scope")
+	if !ok || (scope != "local" && scope != "published") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	body := variables[scope]
+	if body == nil {
+		writeError(w, http.StatusForbidden, "Limited by Figma plan")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (s *Server) handleImages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.files[r.PathValue("key")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	ids := r.URL.Query().Get("ids")
+	if ids == "" {
+		writeError(w, http.StatusBadRequest, "No ids specified")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "png"
+	}
+
+	images := map[string]string{}
+	for _, id := range strings.Split(ids, ",") {
+		images[id] = fmt.Sprintf("%s/renders/%s.%s", s.URL, strings.ReplaceAll(id, ":", "-"), format)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"err": nil, "images": images})
+}
+
+// imageRefPattern finds the image fill references of a file body
+var imageRefPattern = regexp.MustCompile(`"imageRef"\s*:\s*"([^"]+)"`)
+
+// handleImageFills answers a download URL, served by handleRender, for every image fill the file references
+func (s *Server) handleImageFills(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	body, ok := s.files[r.PathValue("key")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	images := map[string]string{}
+	for _, match := range imageRefPattern.FindAllSubmatch(body, -1) {
+		images[string(match[1])] = fmt.Sprintf("%s/renders/fill-%s.png", s.URL, match[1])
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"error": false, "status": 200, "meta": map[string]any{"images": images}})
+}
+
+// handleTeamLibrary pages through a team library list the way Figma does: items after the "after" cursor,
+// with the cursor of the last item returned in meta.cursor.after
+func (s *Server) handleTeamLibrary(w http.ResponseWriter, r *http.Request) {
+	resource := r.PathValue("resource")
+	s.mu.Lock()
+	library, ok := s.teams[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if resource != "components" && resource != "component_sets" && resource != "styles" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	items := library[resource]
+	after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize <= 0 || pageSize > TeamLibraryPageLimit {
+		pageSize = TeamLibraryPageLimit
+	}
+	start := min(max(after, 0), len(items))
+	end := min(start+pageSize, len(items))
+
+	page := items[start:end]
+	if page == nil {
+		page = []json.RawMessage{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status": http.StatusOK,
+		"error":  false,
+		"meta": map[string]any{
+			resource: page,
+			"cursor": map[string]int{"before": start, "after": end},
+		},
+	})
+}
+
+// handleRender serves a 1x1 transparent PNG for every rendered image URL
+func (s *Server) handleRender(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(transparentPNG)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError answers in the same shape as the Figma API error responses
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"status": status, "err": message})
+}
+
+var transparentPNG = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4,
+	0x89, 0x00, 0x00, 0x00, 0x0d, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
+	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e, 0x44, 0xae,
+	0x42, 0x60, 0x82,
+}
@@ -0,0 +1,68 @@
+package progress
+
+import (
+	"context"
+	"time"
+)
+
+// Stage identifies a step of parsing and persisting a Figma file
+type Stage string
+
+const (
+	StageFetchStarted    Stage = "fetch_started"
+	StageBytesDownloaded Stage = "bytes_downloaded"
+	StageNodesWalked     Stage = "nodes_walked"
+	StageComponentsFound Stage = "components_found"
+	StageInstancesFound  Stage = "instances_found"
+	StageRowsPersisted   Stage = "rows_persisted"
+	StageDone            Stage = "done"
+	StageError           Stage = "error"
+)
+
+// Event is a single progress update. Count is cumulative for the stage (bytes, nodes, rows...)
+type Event struct {
+	Stage       Stage     `json:"stage"`
+	Count       int64     `json:"count,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	FigmaFileID int64     `json:"figma_file_id,omitempty"` // set on done
+	Time        time.Time `json:"time"`
+}
+
+// IsFinal reports whether no more events follow this one
+func (e Event) IsFinal() bool {
+	return e.Stage == StageDone || e.Stage == StageError
+}
+
+// Reporter receives progress events. It must not block
+type Reporter func(Event)
+
+type key string
+
+const reporterKey key = "progress_reporter"
+
+// WithReporter returns a context carrying the reporter, picked up by the parser, client and services
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey, reporter)
+}
+
+// FromContext returns the reporter carried by ctx, nil if there is none
+func FromContext(ctx context.Context) Reporter {
+	reporter, _ := ctx.Value(reporterKey).(Reporter)
+	return reporter
+}
+
+// Report sends an event to the reporter carried by ctx, if any
+func Report(ctx context.Context, event Event) {
+	FromContext(ctx).Report(event)
+}
+
+// Report sends an event, a nil Reporter discards it
+func (r Reporter) Report(event Event) {
+	if r == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	r(event)
+}
@@ -0,0 +1,333 @@
+package token_exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"parser-service/internal/errors"
+	"strconv"
+	"strings"
+)
+
+// Format is an export format of a TokenSet
+type Format string
+
+const (
+	FormatDTCG            Format = "dtcg"             // W3C Design Tokens Community Group JSON
+	FormatStyleDictionary Format = "style-dictionary" // Style Dictionary source JSON
+	FormatCSS             Format = "css"              // custom properties on :root, other modes under [data-mode="<collection>-<mode>"]
+	FormatSCSS            Format = "scss"             // variables, other modes as maps
+	FormatTS              Format = "ts"               // typed constant objects
+)
+
+// Formats lists every supported format
+var Formats = []Format{FormatDTCG, FormatStyleDictionary, FormatCSS, FormatSCSS, FormatTS}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", errors.InvalidInput("format must be one of %s, got %q", strings.Join(names, ", "), name)
+}
+
+// ContentType is the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSS:
+		return "text/css; charset=utf-8"
+	case FormatSCSS:
+		return "text/x-scss; charset=utf-8"
+	case FormatTS:
+		return "application/typescript; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Export writes the token set in format. The output only depends on the tokens, not on the order they were added in
+func Export(set *TokenSet, format Format) ([]byte, error) {
+	switch format {
+	case FormatDTCG:
+		return exportJSON(set, dtcgToken)
+	case FormatStyleDictionary:
+		return exportJSON(set, styleDictionaryToken)
+	case FormatCSS:
+		return exportCSS(set), nil
+	case FormatSCSS:
+		return exportSCSS(set), nil
+	case FormatTS:
+		return exportTS(set)
+	default:
+		return nil, errors.InvalidInput("unsupported token format %q", format)
+	}
+}
+
+// exportJSON nests the tokens in groups following their path, each token written by writeToken
+func exportJSON(set *TokenSet, writeToken func(set *TokenSet, token Token) map[string]interface{}) ([]byte, error) {
+	root := make(map[string]interface{})
+	for _, token := range set.sortedTokens() {
+		group := root
+		for _, segment := range token.Path[:len(token.Path)-1] {
+			child, ok := group[segment].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				group[segment] = child
+			}
+			group = child
+		}
+		group[token.Path[len(token.Path)-1]] = writeToken(set, token)
+	}
+
+	output, err := encodeJSON(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tokens: %w", err)
+	}
+	return append(output, '\n'), nil
+}
+
+// encodeJSON indents v without escaping &, < and >, which are common in token descriptions.
+// Map keys are sorted by encoding/json, which keeps the output deterministic
+func encodeJSON(v interface{}) ([]byte, error) {
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(output.Bytes(), []byte("\n")), nil
+}
+
+// dtcgToken writes a token as {"$type", "$value", "$description"}, aliases as "{group.token}" references.
+// Other modes go in $extensions, as the format has no modes of its own
+func dtcgToken(set *TokenSet, token Token) map[string]interface{} {
+	written := map[string]interface{}{"$type": token.Type, "$value": set.jsonValue(token.Value)}
+	if token.Description != "" {
+		written["$description"] = token.Description
+	}
+	if len(token.Modes) > 0 {
+		written["$extensions"] = map[string]interface{}{"com.figma": map[string]interface{}{"modes": set.jsonModes(token)}}
+	}
+	return written
+}
+
+// styleDictionaryToken writes a token as {"value", "type", "comment"}, aliases as "{group.token}" references
+func styleDictionaryToken(set *TokenSet, token Token) map[string]interface{} {
+	written := map[string]interface{}{"type": token.Type, "value": set.jsonValue(token.Value)}
+	if token.Description != "" {
+		written["comment"] = token.Description
+	}
+	if len(token.Modes) > 0 {
+		written["modes"] = set.jsonModes(token)
+	}
+	return written
+}
+
+func (s *TokenSet) jsonValue(value Value) interface{} {
+	if s.hasToken(value.Alias) {
+		return "{" + strings.Join(value.Alias, ".") + "}"
+	}
+	return value.Literal
+}
+
+// jsonModes keys the other modes of a token by mode name, which is unique within the collection of the token
+func (s *TokenSet) jsonModes(token Token) map[string]interface{} {
+	modes := make(map[string]interface{}, len(token.Modes))
+	for mode, value := range token.Modes {
+		modes[mode.Name] = s.jsonValue(value)
+	}
+	return modes
+}
+
+func (s *TokenSet) hasToken(path []string) bool {
+	return len(path) > 0 && s.paths[strings.Join(path, ".")]
+}
+
+// cssProperty is a custom property or variable generated for a token. Typography tokens expand to one property
+// per font setting
+type cssProperty struct {
+	name  string
+	value string
+}
+
+// cssProperties writes a token value as CSS. references renders aliases as var(--...), SCSS gets literals
+// as variables must be declared before being used
+func (s *TokenSet) cssProperties(path []string, tokenType string, value Value, references bool) []cssProperty {
+	name := strings.Join(path, "-")
+	if references && s.hasToken(value.Alias) {
+		return []cssProperty{{name: name, value: "var(--" + strings.Join(value.Alias, "-") + ")"}}
+	}
+
+	switch literal := value.Literal.(type) {
+	case Typography:
+		properties := []cssProperty{
+			{name: name + "-font-family", value: cssString(literal.FontFamily)},
+			{name: name + "-font-weight", value: formatNumber(literal.FontWeight)},
+			{name: name + "-font-size", value: literal.FontSize},
+		}
+		if literal.LineHeight > 0 {
+			properties = append(properties, cssProperty{name: name + "-line-height", value: formatNumber(literal.LineHeight)})
+		}
+		return append(properties, cssProperty{name: name + "-letter-spacing", value: literal.LetterSpacing})
+	case []Shadow:
+		layers := make([]string, len(literal))
+		for i, shadow := range literal {
+			layers[i] = strings.Join([]string{shadow.OffsetX, shadow.OffsetY, shadow.Blur, shadow.Spread, shadow.Color}, " ")
+			if shadow.Inset {
+				layers[i] = "inset " + layers[i]
+			}
+		}
+		return []cssProperty{{name: name, value: strings.Join(layers, ", ")}}
+	case float64:
+		return []cssProperty{{name: name, value: formatNumber(literal)}}
+	case bool:
+		return []cssProperty{{name: name, value: strconv.FormatBool(literal)}}
+	case string:
+		if tokenType == TypeFontFamily || tokenType == TypeString {
+			return []cssProperty{{name: name, value: cssString(literal)}}
+		}
+		return []cssProperty{{name: name, value: literal}}
+	default:
+		return []cssProperty{{name: name, value: fmt.Sprint(literal)}}
+	}
+}
+
+func cssString(value string) string {
+	return strconv.Quote(value)
+}
+
+// header fills comment with a do not edit notice. The file name is quoted so it can't end the comment
+func (s *TokenSet) header(comment string) string {
+	name := strings.ReplaceAll(strconv.Quote(s.Name), "*/", "*\\/")
+	return fmt.Sprintf(comment, "Design tokens generated from the Figma file "+name+", do not edit")
+}
+
+// exportCSS writes custom properties on :root for the default values, and a [data-mode="..."] block per other mode,
+// named after its collection and itself
+func exportCSS(set *TokenSet) []byte {
+	var output bytes.Buffer
+	output.WriteString(set.header("/* %s */\n"))
+	tokens := set.sortedTokens()
+
+	output.WriteString(":root {\n")
+	for _, token := range tokens {
+		for _, property := range set.cssProperties(token.Path, token.Type, token.Value, true) {
+			fmt.Fprintf(&output, "  --%s: %s;\n", property.name, property.value)
+		}
+	}
+	output.WriteString("}\n")
+
+	for _, mode := range set.Modes {
+		fmt.Fprintf(&output, "\n[data-mode=%q] {\n", mode.slug())
+		for _, token := range tokens {
+			value, ok := token.Modes[mode]
+			if !ok {
+				continue
+			}
+			for _, property := range set.cssProperties(token.Path, token.Type, value, true) {
+				fmt.Fprintf(&output, "  --%s: %s;\n", property.name, property.value)
+			}
+		}
+		output.WriteString("}\n")
+	}
+	return output.Bytes()
+}
+
+// exportSCSS writes a variable per default value, and a map of the values of every other mode
+func exportSCSS(set *TokenSet) []byte {
+	var output bytes.Buffer
+	output.WriteString(set.header("// %s\n"))
+	tokens := set.sortedTokens()
+
+	for _, token := range tokens {
+		for _, property := range set.cssProperties(token.Path, token.Type, token.Value, false) {
+			fmt.Fprintf(&output, "$%s: %s;\n", scssVariable(property.name), property.value)
+		}
+	}
+
+	for _, mode := range set.Modes {
+		fmt.Fprintf(&output, "\n$tokens-%s: (\n", mode.slug())
+		for _, token := range tokens {
+			value, ok := token.Modes[mode]
+			if !ok {
+				continue
+			}
+			for _, property := range set.cssProperties(token.Path, token.Type, value, false) {
+				fmt.Fprintf(&output, "  %q: %s,\n", property.name, property.value)
+			}
+		}
+		output.WriteString(");\n")
+	}
+	return output.Bytes()
+}
+
+// scssVariable makes a property name a valid Sass identifier, which can't start with a digit: "2xl-gap" is token-2xl-gap
+func scssVariable(name string) string {
+	if name[0] >= '0' && name[0] <= '9' {
+		return "token-" + name
+	}
+	return name
+}
+
+// exportTS writes the default values as a nested `tokens` constant, and the values of the other modes as `modes`,
+// grouped by collection
+func exportTS(set *TokenSet) ([]byte, error) {
+	tokens := set.sortedTokens()
+	nest := func(valueOf func(token Token) (Value, bool)) map[string]interface{} {
+		root := make(map[string]interface{})
+		for _, token := range tokens {
+			value, ok := valueOf(token)
+			if !ok {
+				continue
+			}
+			group := root
+			for _, segment := range token.Path[:len(token.Path)-1] {
+				child, ok := group[segment].(map[string]interface{})
+				if !ok {
+					child = make(map[string]interface{})
+					group[segment] = child
+				}
+				group = child
+			}
+			group[token.Path[len(token.Path)-1]] = value.Literal
+		}
+		return root
+	}
+
+	var output bytes.Buffer
+	output.WriteString(set.header("// %s\n\n"))
+
+	defaults, err := encodeJSON(nest(func(token Token) (Value, bool) { return token.Value, true }))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tokens: %w", err)
+	}
+	fmt.Fprintf(&output, "export const tokens = %s as const;\n\nexport type Tokens = typeof tokens;\n", defaults)
+
+	if len(set.Modes) > 0 {
+		modes := make(map[string]interface{})
+		for _, mode := range set.Modes {
+			collection, ok := modes[mode.Collection].(map[string]interface{})
+			if !ok {
+				collection = make(map[string]interface{})
+				modes[mode.Collection] = collection
+			}
+			collection[mode.Name] = nest(func(token Token) (Value, bool) {
+				value, ok := token.Modes[mode]
+				return value, ok
+			})
+		}
+		encoded, err := encodeJSON(modes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode token modes: %w", err)
+		}
+		fmt.Fprintf(&output, "\nexport const modes = %s as const;\n", encoded)
+	}
+	return output.Bytes(), nil
+}
@@ -0,0 +1,440 @@
+// Package token_exporter turns the styles, variables and component properties of a parsed Figma file into
+// design tokens, exported as W3C DTCG or Style Dictionary JSON, CSS custom properties, SCSS variables or TypeScript.
+package token_exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"parser-service/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Token types, as named by the W3C Design Tokens Community Group format
+const (
+	TypeColor      = "color"
+	TypeDimension  = "dimension" // pixel values, e.g. "16px"
+	TypeNumber     = "number"
+	TypeFontFamily = "fontFamily"
+	TypeFontWeight = "fontWeight"
+	TypeTypography = "typography" // Typography
+	TypeShadow     = "shadow"     // []Shadow
+	TypeString     = "string"
+	TypeBoolean    = "boolean"
+)
+
+// Token is a named design token. Path is its group path followed by its name, e.g. ["color", "primary"]
+type Token struct {
+	Path        []string
+	Type        string
+	Description string
+	Value       Value          // value in the default mode
+	Modes       map[Mode]Value // value in the other modes of its collection. Only variables have modes
+}
+
+// Mode is a non default mode of a variable collection. Collections name their modes independently, so two
+// collections can both have a "Dark" mode
+type Mode struct {
+	Collection string // name of the variable collection
+	Name       string
+}
+
+// slug names the mode in generated code, e.g. semantic-dark
+func (m Mode) slug() string {
+	return slug(m.Collection + " " + m.Name)
+}
+
+// Value is the value of a token in one mode. Literal is always set, Alias too when the value refers to another
+// token, so formats supporting references can keep them
+type Value struct {
+	Literal interface{} // string, float64, bool, Typography or []Shadow
+	Alias   []string    // path of the referenced token
+}
+
+// Typography is the value of a typography token
+type Typography struct {
+	FontFamily    string  `json:"fontFamily"`
+	FontWeight    float64 `json:"fontWeight"`
+	FontSize      string  `json:"fontSize"`
+	LineHeight    float64 `json:"lineHeight,omitempty"` // ratio of the font size
+	LetterSpacing string  `json:"letterSpacing"`
+}
+
+// Shadow is one layer of a shadow token
+type Shadow struct {
+	Color   string `json:"color"`
+	OffsetX string `json:"offsetX"`
+	OffsetY string `json:"offsetY"`
+	Blur    string `json:"blur"`
+	Spread  string `json:"spread"`
+	Inset   bool   `json:"inset,omitempty"`
+}
+
+// TokenSet is the tokens of a file. Tokens are added first come, first kept: a token whose path is already taken,
+// or that would turn an existing token into a group (or the other way around), is dropped
+type TokenSet struct {
+	Name   string  // name of the Figma file, written in the header of generated code
+	Tokens []Token // sorted by path when exported
+	Modes  []Mode  // non default modes, in the order they were found
+
+	paths  map[string]bool
+	groups map[string]bool
+}
+
+func NewTokenSet(name string) *TokenSet {
+	return &TokenSet{Name: name, paths: map[string]bool{}, groups: map[string]bool{}}
+}
+
+// Add adds a token, false when its path conflicts with a token already in the set
+func (s *TokenSet) Add(token Token) bool {
+	key := strings.Join(token.Path, ".")
+	if len(token.Path) == 0 || s.paths[key] || s.groups[key] {
+		return false
+	}
+	for i := 1; i < len(token.Path); i++ {
+		if s.paths[strings.Join(token.Path[:i], ".")] {
+			return false
+		}
+	}
+
+	s.paths[key] = true
+	for i := 1; i < len(token.Path); i++ {
+		s.groups[strings.Join(token.Path[:i], ".")] = true
+	}
+	for mode := range token.Modes {
+		s.addMode(mode)
+	}
+	s.Tokens = append(s.Tokens, token)
+	return true
+}
+
+func (s *TokenSet) addMode(mode Mode) {
+	for _, known := range s.Modes {
+		if known == mode {
+			return
+		}
+	}
+	s.Modes = append(s.Modes, mode)
+}
+
+// sortedTokens returns the tokens sorted by path, so exports don't depend on the order tokens were added in
+func (s *TokenSet) sortedTokens() []Token {
+	tokens := append([]Token(nil), s.Tokens...)
+	sort.Slice(tokens, func(i, j int) bool {
+		return strings.Join(tokens[i].Path, ".") < strings.Join(tokens[j].Path, ".")
+	})
+	return tokens
+}
+
+// AddVariables adds a token per variable, named after the variable ("color/primary" is color.primary). Aliases to
+// other variables are kept as references. Values that couldn't be resolved are left out, and so are variables
+// without a value in their default mode
+func (s *TokenSet) AddVariables(collections []models.VariableCollection) {
+	paths := make(map[string][]string)
+	for _, collection := range collections {
+		for _, variable := range collection.Variables {
+			paths[variable.FigmaVariableID] = tokenPath(variable.Name)
+		}
+	}
+
+	for _, collection := range collections {
+		var modes []models.VariableMode
+		_ = json.Unmarshal(collection.Modes, &modes)
+		modeNames := make(map[string]string, len(modes))
+		for _, mode := range modes {
+			modeNames[mode.ModeID] = mode.Name
+		}
+
+		for _, variable := range collection.Variables {
+			tokenType := variableTokenType(variable)
+			token := Token{Path: paths[variable.FigmaVariableID], Type: tokenType, Description: variable.Description}
+			hasDefault := false
+			for _, value := range variable.Values {
+				literal, ok := variableLiteral(tokenType, value.ResolvedValue)
+				if !ok || value.ResolutionError != "" {
+					continue
+				}
+				tokenValue := Value{Literal: literal, Alias: paths[value.AliasOf]}
+				if value.ModeID == collection.DefaultModeID || len(variable.Values) == 1 {
+					token.Value = tokenValue
+					hasDefault = true
+					continue
+				}
+				if token.Modes == nil {
+					token.Modes = make(map[Mode]Value)
+				}
+				modeName := value.ModeName
+				if modeName == "" {
+					modeName = modeNames[value.ModeID]
+				}
+				token.Modes[Mode{Collection: collection.Name, Name: modeName}] = tokenValue
+			}
+			if hasDefault {
+				s.Add(token)
+			}
+		}
+	}
+}
+
+// dimensionScopes are the variable scopes of numbers measured in pixels
+var dimensionScopes = map[string]bool{
+	"CORNER_RADIUS": true, "WIDTH_HEIGHT": true, "GAP": true, "STROKE_FLOAT": true, "EFFECT_FLOAT": true,
+	"FONT_SIZE": true, "LINE_HEIGHT": true, "LETTER_SPACING": true, "PARAGRAPH_SPACING": true, "PARAGRAPH_INDENT": true,
+}
+
+// variableTokenType picks the token type of a variable from its type and the scopes it can be bound to
+func variableTokenType(variable models.Variable) string {
+	var scopes []string
+	_ = json.Unmarshal(variable.Scopes, &scopes)
+	hasScope := func(match func(scope string) bool) bool {
+		for _, scope := range scopes {
+			if match(scope) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch variable.ResolvedType {
+	case "COLOR":
+		return TypeColor
+	case "BOOLEAN":
+		return TypeBoolean
+	case "STRING":
+		if hasScope(func(scope string) bool { return scope == "FONT_FAMILY" }) {
+			return TypeFontFamily
+		}
+		return TypeString
+	default:
+		if hasScope(func(scope string) bool { return scope == "FONT_WEIGHT" }) {
+			return TypeFontWeight
+		}
+		if hasScope(func(scope string) bool { return dimensionScopes[scope] }) {
+			return TypeDimension
+		}
+		return TypeNumber
+	}
+}
+
+// variableLiteral decodes a resolved variable value as a literal of tokenType
+func variableLiteral(tokenType string, resolved json.RawMessage) (interface{}, bool) {
+	if len(resolved) == 0 {
+		return nil, false
+	}
+	var literal interface{}
+	if err := json.Unmarshal(resolved, &literal); err != nil || literal == nil {
+		return nil, false
+	}
+	if number, ok := literal.(float64); ok {
+		if tokenType == TypeDimension {
+			return px(number), true
+		}
+		return round(number), true
+	}
+	return literal, true
+}
+
+// AddStyles adds a token per color (color.*), text (typography.*) and effect style made of shadows (shadow.*),
+// named after the style. Grid styles and styles without a value are left out
+func (s *TokenSet) AddStyles(styles []models.Style) {
+	for _, style := range styles {
+		if len(style.Value) == 0 {
+			continue
+		}
+		var value models.StyleValue
+		if err := json.Unmarshal(style.Value, &value); err != nil {
+			continue
+		}
+
+		var token Token
+		switch style.StyleType {
+		case "FILL":
+			color, ok := solidColor(value.Paints)
+			if !ok {
+				continue
+			}
+			token = Token{Path: append([]string{"color"}, tokenPath(style.Name)...), Type: TypeColor, Value: Value{Literal: color}}
+		case "TEXT":
+			if value.Text == nil {
+				continue
+			}
+			token = Token{Path: append([]string{"typography"}, tokenPath(style.Name)...), Type: TypeTypography, Value: Value{Literal: typography(*value.Text)}}
+		case "EFFECT":
+			shadows := shadows(value.Effects)
+			if len(shadows) == 0 {
+				continue
+			}
+			token = Token{Path: append([]string{"shadow"}, tokenPath(style.Name)...), Type: TypeShadow, Value: Value{Literal: shadows}}
+		default:
+			continue
+		}
+		token.Description = style.Description
+		s.Add(token)
+	}
+}
+
+// AddComponents adds the corner radii (radius.*) and Auto Layout spacings and paddings (spacing.*) used by the
+// components of the file as scales named after their value, e.g. spacing.16, described with the components using them.
+// External components are left out, their properties belong to their library
+func (s *TokenSet) AddComponents(components []models.Component) {
+	radii := make(map[float64]map[string]bool)
+	spacings := make(map[float64]map[string]bool)
+	use := func(scale map[float64]map[string]bool, value float64, component string) {
+		if value <= 0 {
+			return
+		}
+		value = round(value)
+		if scale[value] == nil {
+			scale[value] = make(map[string]bool)
+		}
+		scale[value][component] = true
+	}
+
+	for _, component := range components {
+		if component.IsExternal || len(component.Properties) == 0 {
+			continue
+		}
+		var properties models.NodeProperties
+		if err := json.Unmarshal(component.Properties, &properties); err != nil {
+			continue
+		}
+		if corners := properties.CornerRadii; corners != nil {
+			for _, radius := range []float64{corners.TopLeft, corners.TopRight, corners.BottomRight, corners.BottomLeft} {
+				use(radii, radius, component.Name)
+			}
+		}
+		if layout := properties.Layout; layout != nil && layout.Mode != "" {
+			use(spacings, layout.ItemSpacing, component.Name)
+			use(spacings, layout.CounterAxisSpacing, component.Name)
+			if padding := layout.Padding; padding != nil {
+				for _, value := range []float64{padding.Top, padding.Right, padding.Bottom, padding.Left} {
+					use(spacings, value, component.Name)
+				}
+			}
+		}
+	}
+
+	s.addScale("radius", radii)
+	s.addScale("spacing", spacings)
+}
+
+// addScale adds a dimension token per value of scale, described with the components using it
+func (s *TokenSet) addScale(group string, scale map[float64]map[string]bool) {
+	values := make([]float64, 0, len(scale))
+	for value := range scale {
+		values = append(values, value)
+	}
+	sort.Float64s(values)
+
+	for _, value := range values {
+		components := make([]string, 0, len(scale[value]))
+		for component := range scale[value] {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		s.Add(Token{
+			Path:        []string{group, slug(formatNumber(value))},
+			Type:        TypeDimension,
+			Description: "Used by " + strings.Join(components, ", "),
+			Value:       Value{Literal: px(value)},
+		})
+	}
+}
+
+// solidColor returns the color of the first visible solid paint, with the paint opacity applied
+func solidColor(paints []models.Paint) (string, bool) {
+	for _, paint := range paints {
+		if paint.Type != "SOLID" || !paint.Visible || paint.Color == "" {
+			continue
+		}
+		return withOpacity(paint.Color, paint.Opacity), true
+	}
+	return "", false
+}
+
+// withOpacity multiplies the alpha of a #RRGGBB or #RRGGBBAA color by opacity
+func withOpacity(hex string, opacity float64) string {
+	if opacity >= 1 || opacity < 0 || (len(hex) != 7 && len(hex) != 9) {
+		return hex
+	}
+	alpha := 1.0
+	if len(hex) == 9 {
+		value, err := strconv.ParseUint(hex[7:], 16, 8)
+		if err != nil {
+			return hex
+		}
+		alpha = float64(value) / 255
+	}
+	return fmt.Sprintf("%s%02X", hex[:7], int(math.Round(alpha*opacity*255)))
+}
+
+func typography(text models.TextStyle) Typography {
+	value := Typography{
+		FontFamily:    text.FontFamily,
+		FontWeight:    text.FontWeight,
+		FontSize:      px(text.FontSize),
+		LetterSpacing: px(text.LetterSpacing),
+	}
+	if text.LineHeightPx > 0 && text.FontSize > 0 {
+		value.LineHeight = round(text.LineHeightPx / text.FontSize)
+	}
+	return value
+}
+
+// shadows returns the visible drop and inner shadows of effects, blurs have no token
+func shadows(effects []models.Effect) []Shadow {
+	var shadows []Shadow
+	for _, effect := range effects {
+		if !effect.Visible || (effect.Type != "DROP_SHADOW" && effect.Type != "INNER_SHADOW") {
+			continue
+		}
+		shadow := Shadow{
+			Color:   effect.Color,
+			OffsetX: px(0),
+			OffsetY: px(0),
+			Blur:    px(effect.Radius),
+			Spread:  px(effect.Spread),
+			Inset:   effect.Type == "INNER_SHADOW",
+		}
+		if effect.Offset != nil {
+			shadow.OffsetX = px(effect.Offset.X)
+			shadow.OffsetY = px(effect.Offset.Y)
+		}
+		shadows = append(shadows, shadow)
+	}
+	return shadows
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug lowercases a name and replaces anything but letters and digits with dashes, "Heading Large" is heading-large
+func slug(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// tokenPath splits a Figma style or variable name on slashes, "Brand/Blue 500" is ["brand", "blue-500"]
+func tokenPath(name string) []string {
+	var path []string
+	for _, segment := range strings.Split(name, "/") {
+		if segment = slug(segment); segment != "" {
+			path = append(path, segment)
+		}
+	}
+	return path
+}
+
+// round keeps two decimals, enough for pixels and ratios and hides float noise such as 1.2000000476837158
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(round(value), 'f', -1, 64)
+}
+
+func px(value float64) string {
+	return formatNumber(value) + "px"
+}
@@ -0,0 +1,400 @@
+package token_exporter_test
+
+import (
+	"encoding/json"
+	"parser-service/internal/errors"
+	"parser-service/internal/token_exporter"
+	"parser-service/models"
+	"strings"
+	"testing"
+)
+
+// sampleCollections mirrors the variables of the figmatest fixture: a single mode Primitives collection and a
+// Semantic collection with Light and Dark modes aliasing it
+func sampleCollections() []models.VariableCollection {
+	value := func(modeID, modeName, literal, aliasOf, resolved, resolutionError string) models.VariableValue {
+		v := models.VariableValue{ModeID: modeID, ModeName: modeName, ResolutionError: resolutionError}
+		if literal != "" {
+			v.Value = json.RawMessage(literal)
+		}
+		if aliasOf != "" {
+			v.AliasOf = aliasOf
+		}
+		if resolved != "" {
+			v.ResolvedValue = json.RawMessage(resolved)
+		}
+		return v
+	}
+
+	return []models.VariableCollection{
+		{
+			Name:          "Primitives",
+			Modes:         json.RawMessage(`[{"mode_id":"10:0","name":"Value"}]`),
+			DefaultModeID: "10:0",
+			Variables: []models.Variable{
+				{FigmaVariableID: "VariableID:10:2", Name: "blue/500", ResolvedType: "COLOR", Scopes: json.RawMessage(`["ALL_SCOPES"]`),
+					Values: []models.VariableValue{value("10:0", "Value", `"#3366FF"`, "", `"#3366FF"`, "")}},
+				{FigmaVariableID: "VariableID:10:3", Name: "gray/900", ResolvedType: "COLOR", Scopes: json.RawMessage(`["ALL_SCOPES"]`),
+					Values: []models.VariableValue{value("10:0", "Value", `"#1A1A1A"`, "", `"#1A1A1A"`, "")}},
+				{FigmaVariableID: "VariableID:10:4", Name: "spacing/4", ResolvedType: "FLOAT", Scopes: json.RawMessage(`["GAP","WIDTH_HEIGHT"]`),
+					Values: []models.VariableValue{value("10:0", "Value", "16", "", "16", "")}},
+			},
+		},
+		{
+			Name:          "Semantic",
+			Modes:         json.RawMessage(`[{"mode_id":"11:0","name":"Light"},{"mode_id":"11:1","name":"Dark"}]`),
+			DefaultModeID: "11:0",
+			Variables: []models.Variable{
+				{FigmaVariableID: "VariableID:11:2", Name: "color/primary", ResolvedType: "COLOR", Values: []models.VariableValue{
+					value("11:0", "Light", "", "VariableID:10:2", `"#3366FF"`, ""),
+					value("11:1", "Dark", "", "VariableID:10:3", `"#1A1A1A"`, ""),
+				}},
+				{FigmaVariableID: "VariableID:11:4", Name: "color/action", ResolvedType: "COLOR", Description: "Buttons & links", Values: []models.VariableValue{
+					value("11:0", "Light", "", "VariableID:11:2", `"#3366FF"`, ""),
+					value("11:1", "Dark", "", "VariableID:11:2", `"#1A1A1A"`, ""),
+				}},
+				{FigmaVariableID: "VariableID:11:5", Name: "space/md", ResolvedType: "FLOAT", Scopes: json.RawMessage(`["GAP"]`), Values: []models.VariableValue{
+					value("11:0", "Light", "", "VariableID:10:4", "16", ""),
+					value("11:1", "Dark", "20", "", "20", ""),
+				}},
+				{FigmaVariableID: "VariableID:11:6", Name: "loop/a", ResolvedType: "COLOR", Values: []models.VariableValue{
+					value("11:0", "Light", "", "VariableID:11:7", "", "alias cycle: loop/a -> loop/b -> loop/a"),
+					value("11:1", "Dark", "", "VariableID:11:7", `"#00000080"`, ""),
+				}},
+			},
+		},
+	}
+}
+
+func sampleStyles(t *testing.T) []models.Style {
+	t.Helper()
+	style := func(name, styleType string, value models.StyleValue) models.Style {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("Failed to encode style value: %v", err)
+		}
+		return models.Style{Name: name, StyleType: styleType, Value: encoded}
+	}
+
+	return []models.Style{
+		style("Brand/Blue", "FILL", models.StyleValue{Paints: []models.Paint{
+			{Type: "IMAGE", Visible: true, Opacity: 1},
+			{Type: "SOLID", Visible: true, Opacity: 0.5, Color: "#3366FF"},
+		}}),
+		style("Heading Large", "TEXT", models.StyleValue{Text: &models.TextStyle{
+			FontFamily: "Inter", FontWeight: 700, FontSize: 32, LineHeightPx: 38.400001525878906, LetterSpacing: -0.5,
+		}}),
+		style("Elevation/1", "EFFECT", models.StyleValue{Effects: []models.Effect{
+			{Type: "DROP_SHADOW", Visible: true, Color: "#0000001A", Radius: 4, Offset: &models.Vector{Y: 2}},
+			{Type: "LAYER_BLUR", Visible: true, Radius: 8},
+		}}),
+		style("Columns", "GRID", models.StyleValue{}),
+		// color/primary is already a variable, the variable wins
+		style("Primary", "FILL", models.StyleValue{Paints: []models.Paint{{Type: "SOLID", Visible: true, Opacity: 1, Color: "#0000FF"}}}),
+	}
+}
+
+func sampleComponents(t *testing.T) []models.Component {
+	t.Helper()
+	component := func(name string, external bool, properties models.NodeProperties) models.Component {
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			t.Fatalf("Failed to encode properties: %v", err)
+		}
+		return models.Component{Name: name, IsExternal: external, Properties: encoded}
+	}
+
+	return []models.Component{
+		component("Button", false, models.NodeProperties{
+			CornerRadii: &models.CornerRadii{TopLeft: 8, TopRight: 8, BottomRight: 8, BottomLeft: 8},
+			Layout:      &models.Layout{Mode: "HORIZONTAL", ItemSpacing: 8, Padding: &models.Padding{Top: 12, Right: 16, Bottom: 12, Left: 16}},
+		}),
+		component("Card", false, models.NodeProperties{
+			CornerRadii: &models.CornerRadii{TopLeft: 8, TopRight: 8},
+			Layout:      &models.Layout{Mode: "VERTICAL", ItemSpacing: 16},
+		}),
+		component("Library Button", true, models.NodeProperties{CornerRadii: &models.CornerRadii{TopLeft: 99}}),
+	}
+}
+
+func sampleTokenSet(t *testing.T) *token_exporter.TokenSet {
+	t.Helper()
+	set := token_exporter.NewTokenSet("Design System")
+	set.AddVariables(sampleCollections())
+	set.AddStyles(sampleStyles(t))
+	set.AddComponents(sampleComponents(t))
+	return set
+}
+
+func tokensByPath(set *token_exporter.TokenSet) map[string]token_exporter.Token {
+	tokens := make(map[string]token_exporter.Token)
+	for _, token := range set.Tokens {
+		tokens[strings.Join(token.Path, ".")] = token
+	}
+	return tokens
+}
+
+func TestTokenSet_AddVariables(t *testing.T) {
+	set := token_exporter.NewTokenSet("Design System")
+	set.AddVariables(sampleCollections())
+	tokens := tokensByPath(set)
+
+	t.Run("types come from the variable type and scopes", func(t *testing.T) {
+		expected := map[string]string{"blue.500": "color", "spacing.4": "dimension", "space.md": "dimension", "color.action": "color"}
+		for path, tokenType := range expected {
+			if tokens[path].Type != tokenType {
+				t.Errorf("Expected %s to be a %s token, got %q", path, tokenType, tokens[path].Type)
+			}
+		}
+		if value := tokens["spacing.4"].Value.Literal; value != "16px" {
+			t.Errorf("Expected spacing.4 to be 16px, got %v", value)
+		}
+	})
+
+	dark := token_exporter.Mode{Collection: "Semantic", Name: "Dark"}
+	t.Run("aliases are kept in every mode", func(t *testing.T) {
+		action := tokens["color.action"]
+		if strings.Join(action.Value.Alias, ".") != "color.primary" || action.Value.Literal != "#3366FF" {
+			t.Errorf("Expected color.action to alias color.primary, got %+v", action.Value)
+		}
+		if value := action.Modes[dark]; strings.Join(value.Alias, ".") != "color.primary" || value.Literal != "#1A1A1A" {
+			t.Errorf("Expected color.action to alias color.primary in Dark, got %+v", value)
+		}
+		if value := tokens["space.md"].Modes[dark]; value.Alias != nil || value.Literal != "20px" {
+			t.Errorf("Expected the literal 20px in Dark, got %+v", value)
+		}
+		if len(set.Modes) != 1 || set.Modes[0] != dark {
+			t.Errorf("Expected Semantic Dark to be the only other mode, got %v", set.Modes)
+		}
+	})
+
+	t.Run("modes of different collections are kept apart", func(t *testing.T) {
+		set := token_exporter.NewTokenSet("Design System")
+		set.AddVariables(append(sampleCollections(), models.VariableCollection{
+			Name:          "Surface",
+			Modes:         json.RawMessage(`[{"mode_id":"12:0","name":"Light"},{"mode_id":"12:1","name":"Dark"}]`),
+			DefaultModeID: "12:0",
+			Variables: []models.Variable{
+				{FigmaVariableID: "VariableID:12:2", Name: "surface/base", ResolvedType: "COLOR", Values: []models.VariableValue{
+					{ModeID: "12:0", ModeName: "Light", ResolvedValue: json.RawMessage(`"#FFFFFF"`)},
+					{ModeID: "12:1", ModeName: "Dark", ResolvedValue: json.RawMessage(`"#000000"`)},
+				}},
+			},
+		}))
+
+		surfaceDark := token_exporter.Mode{Collection: "Surface", Name: "Dark"}
+		if len(set.Modes) != 2 || set.Modes[0] != dark || set.Modes[1] != surfaceDark {
+			t.Errorf("Expected the Dark modes of Semantic and Surface, got %v", set.Modes)
+		}
+		output, err := token_exporter.Export(set, token_exporter.FormatCSS)
+		if err != nil {
+			t.Fatalf("Failed to export: %v", err)
+		}
+		for _, expected := range []string{
+			"[data-mode=\"semantic-dark\"] {\n  --color-action: var(--color-primary);\n  --color-primary: var(--gray-900);\n  --space-md: 20px;\n}",
+			"[data-mode=\"surface-dark\"] {\n  --surface-base: #000000;\n}",
+		} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected the CSS to contain %s:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("variables without a resolved default are left out", func(t *testing.T) {
+		if _, ok := tokens["loop.a"]; ok {
+			t.Error("Expected loop.a to be left out, its default value is an alias cycle")
+		}
+	})
+}
+
+func TestTokenSet_AddStyles(t *testing.T) {
+	tokens := tokensByPath(sampleTokenSet(t))
+
+	if color := tokens["color.brand.blue"].Value.Literal; color != "#3366FF80" {
+		t.Errorf("Expected the first solid paint with its opacity, got %v", color)
+	}
+	expectedTypography := token_exporter.Typography{FontFamily: "Inter", FontWeight: 700, FontSize: "32px", LineHeight: 1.2, LetterSpacing: "-0.5px"}
+	if typography := tokens["typography.heading-large"].Value.Literal; typography != expectedTypography {
+		t.Errorf("Expected %+v, got %+v", expectedTypography, typography)
+	}
+	shadows, _ := tokens["shadow.elevation.1"].Value.Literal.([]token_exporter.Shadow)
+	if len(shadows) != 1 || shadows[0] != (token_exporter.Shadow{Color: "#0000001A", OffsetX: "0px", OffsetY: "2px", Blur: "4px", Spread: "0px"}) {
+		t.Errorf("Expected a single drop shadow, got %+v", shadows)
+	}
+	if _, ok := tokens["columns"]; ok {
+		t.Error("Expected grid styles to be left out")
+	}
+	if primary := tokens["color.primary"]; primary.Value.Literal != "#3366FF" || primary.Value.Alias == nil {
+		t.Errorf("Expected the color/primary variable to win over a style, got %+v", primary.Value)
+	}
+}
+
+func TestTokenSet_AddComponents(t *testing.T) {
+	tokens := tokensByPath(sampleTokenSet(t))
+
+	expected := map[string]string{
+		"radius.8":   "Used by Button, Card",
+		"spacing.8":  "Used by Button",
+		"spacing.12": "Used by Button",
+		"spacing.16": "Used by Button, Card",
+	}
+	for path, description := range expected {
+		if tokens[path].Description != description {
+			t.Errorf("Expected %s described as %q, got %q", path, description, tokens[path].Description)
+		}
+	}
+	if _, ok := tokens["radius.99"]; ok {
+		t.Error("Expected external components to be left out")
+	}
+}
+
+func TestTokenSet_Add(t *testing.T) {
+	set := token_exporter.NewTokenSet("Design System")
+	if !set.Add(token_exporter.Token{Path: []string{"color", "primary"}, Type: token_exporter.TypeColor}) {
+		t.Fatal("Expected the first token to be added")
+	}
+	conflicts := [][]string{{"color", "primary"}, {"color"}, {"color", "primary", "hover"}, nil}
+	for _, path := range conflicts {
+		if set.Add(token_exporter.Token{Path: path, Type: token_exporter.TypeColor}) {
+			t.Errorf("Expected %v to conflict with color.primary", path)
+		}
+	}
+}
+
+func TestExport_CSS(t *testing.T) {
+	output, err := token_exporter.Export(sampleTokenSet(t), token_exporter.FormatCSS)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	expected := `/* Design tokens generated from the Figma file "Design System", do not edit */
+:root {
+  --blue-500: #3366FF;
+  --color-action: var(--color-primary);
+  --color-brand-blue: #3366FF80;
+  --color-primary: var(--blue-500);
+  --gray-900: #1A1A1A;
+  --radius-8: 8px;
+  --shadow-elevation-1: 0px 2px 4px 0px #0000001A;
+  --space-md: var(--spacing-4);
+  --spacing-12: 12px;
+  --spacing-16: 16px;
+  --spacing-4: 16px;
+  --spacing-8: 8px;
+  --typography-heading-large-font-family: "Inter";
+  --typography-heading-large-font-weight: 700;
+  --typography-heading-large-font-size: 32px;
+  --typography-heading-large-line-height: 1.2;
+  --typography-heading-large-letter-spacing: -0.5px;
+}
+
+[data-mode="semantic-dark"] {
+  --color-action: var(--color-primary);
+  --color-primary: var(--gray-900);
+  --space-md: 20px;
+}
+`
+	if string(output) != expected {
+		t.Errorf("Unexpected CSS:\n%s", output)
+	}
+}
+
+func TestExport_TS(t *testing.T) {
+	set := token_exporter.NewTokenSet("Design System")
+	set.AddVariables(sampleCollections()[1:])
+
+	output, err := token_exporter.Export(set, token_exporter.FormatTS)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// aliases to tokens outside the set are exported as their value
+	expected := `// Design tokens generated from the Figma file "Design System", do not edit
+
+export const tokens = {
+  "color": {
+    "action": "#3366FF",
+    "primary": "#3366FF"
+  },
+  "space": {
+    "md": "16px"
+  }
+} as const;
+
+export type Tokens = typeof tokens;
+
+export const modes = {
+  "Semantic": {
+    "Dark": {
+      "color": {
+        "action": "#1A1A1A",
+        "primary": "#1A1A1A"
+      },
+      "space": {
+        "md": "20px"
+      }
+    }
+  }
+} as const;
+`
+	if string(output) != expected {
+		t.Errorf("Unexpected TypeScript:\n%s", output)
+	}
+}
+
+func TestExport_SCSS(t *testing.T) {
+	set := token_exporter.NewTokenSet("Design System")
+	set.Add(token_exporter.Token{Path: []string{"2xl", "gap"}, Type: token_exporter.TypeDimension, Value: token_exporter.Value{Literal: "48px"}})
+	set.Add(token_exporter.Token{Path: []string{"spacing", "4"}, Type: token_exporter.TypeDimension, Value: token_exporter.Value{Literal: "16px"}})
+
+	output, err := token_exporter.Export(set, token_exporter.FormatSCSS)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// Sass variables can't start with a digit
+	expected := `// Design tokens generated from the Figma file "Design System", do not edit
+$token-2xl-gap: 48px;
+$spacing-4: 16px;
+`
+	if string(output) != expected {
+		t.Errorf("Unexpected SCSS:\n%s", output)
+	}
+}
+
+func TestExport_DTCG(t *testing.T) {
+	output, err := token_exporter.Export(sampleTokenSet(t), token_exporter.FormatDTCG)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if !strings.Contains(string(output), "Buttons & links") {
+		t.Error("Expected descriptions not to be HTML escaped")
+	}
+
+	var tokens map[string]map[string]map[string]interface{}
+	if err := json.Unmarshal(output, &tokens); err != nil {
+		t.Fatalf("Expected JSON groups of tokens, got: %v", err)
+	}
+	action := tokens["color"]["action"]
+	if action["$type"] != "color" || action["$value"] != "{color.primary}" || action["$description"] != "Buttons & links" {
+		t.Errorf("Unexpected color.action token: %v", action)
+	}
+	modes := action["$extensions"].(map[string]interface{})["com.figma"].(map[string]interface{})["modes"]
+	if dark := modes.(map[string]interface{})["Dark"]; dark != "{color.primary}" {
+		t.Errorf("Expected color.action to alias color.primary in Dark, got %v", dark)
+	}
+
+	again, _ := token_exporter.Export(sampleTokenSet(t), token_exporter.FormatDTCG)
+	if string(again) != string(output) {
+		t.Error("Expected exports to be deterministic")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := token_exporter.ParseFormat("Style-Dictionary"); err != nil || format != token_exporter.FormatStyleDictionary {
+		t.Errorf("Expected style-dictionary, got %q, %v", format, err)
+	}
+	if _, err := token_exporter.ParseFormat("xml"); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Expected an invalid input error, got %v", err)
+	}
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"parser-service/handler"
 	"parser-service/internal/db_manager"
 	"parser-service/internal/figma_manager"
 	"parser-service/middlewares"
 	"parser-service/repositories"
 	"parser-service/services"
+	"strconv"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -34,21 +37,60 @@ func main() {
 
 func setupRoutes(r *gin.Engine) {
 	db := db_manager.InitPgsqlConnection()
-	figmaManager := figma_manager.NewFigmaManager()
+	// FIGMA_API_BASE_URL allows pointing the service to a fake or proxied Figma API, defaults to the public API
+	figmaManager := figma_manager.NewFigmaManagerWithClient(figma_manager.NewFigmaClientWithConfig(os.Getenv("FIGMA_API_BASE_URL"), nil))
 	figmaFilesRepo := repositories.NewFigmaFilesRepository(*db)
+	pagesRepo := repositories.NewPagesRepository(*db)
 	componentsRepo := repositories.NewComponentsRepository(*db)
 	instancesRepo := repositories.NewInstancesRepository(*db)
-	parserHandler := handler.NewParserHandler(*services.NewParserService(figmaManager, figmaFilesRepo, componentsRepo, instancesRepo))
+	nodesRepo := repositories.NewNodesRepository(*db)
+	textNodesRepo := repositories.NewTextNodesRepository(*db)
+	stylesRepo := repositories.NewStylesRepository(*db)
+	variablesRepo := repositories.NewVariablesRepository(*db)
+	parseJobsRepo := repositories.NewParseJobsRepository(*db)
+	libraryRepo := repositories.NewLibraryRepository(*db)
+	parserService := services.NewParserService(db, figmaManager, figmaFilesRepo, pagesRepo, componentsRepo, instancesRepo, nodesRepo, textNodesRepo, stylesRepo, variablesRepo)
+
+	// PARSE_WORKERS sets how many async parse jobs run concurrently. PARSE_WORKER_ID names this replica, it must be
+	// unique among replicas and kept across restarts so a restarted replica fails the jobs its previous run left
+	parseWorkers, _ := strconv.Atoi(os.Getenv("PARSE_WORKERS"))
+	parseJobsService := services.NewParseJobsService(parserService, parseJobsRepo, parseWorkers, os.Getenv("PARSE_WORKER_ID"))
+	if err := parseJobsService.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start parse workers: %v", err)
+	}
+	parserHandler := handler.NewParserHandler(*parserService, parseJobsService)
+	libraryHandler := handler.NewLibraryHandler(services.NewLibraryService(db, figmaManager, libraryRepo, componentsRepo))
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status": "healthy",
+			"status":  "healthy",
 			"service": "figma-parser-backend",
 		})
 	})
 
 	// Apply middleware to routes that need Figma token validation
 	r.POST("/parse-figma-file", middlewares.ValidateFigmaToken(figmaManager), parserHandler.ParseAndSaveFigmaFile)
-	r.GET("/figma-files/:id", parserHandler.GetFigmaFileDetails) // No token needed for reading from DB
+	r.POST("/parse-figma-json", parserHandler.ParseAndSaveFigmaJSON) // Offline parsing of a saved file export, no Figma API calls
+	r.GET("/parse-jobs/:id", parserHandler.GetParseJob)
+	r.GET("/parse-jobs/:id/events", parserHandler.GetParseJobEvents) // Server-Sent Events progress stream
+	r.GET("/figma-files/:id", parserHandler.GetFigmaFileDetails)     // No token needed for reading from DB, ?page_id= filters by page
+	r.GET("/figma-files/:id/pages", parserHandler.GetFigmaFilePages)
+	r.GET("/figma-files/:id/components", parserHandler.GetFigmaFileComponents) // ?page_id= filters by page
+	r.GET("/figma-files/:id/instances", parserHandler.GetFigmaFileInstances)   // ?page_id=, ?min_overrides= filter instances
+	r.GET("/figma-files/:id/text-nodes", parserHandler.GetFigmaFileTextNodes)  // ?page_id= filters by page
+	r.GET("/figma-files/:id/tree", parserHandler.GetFigmaFileTree)             // Node hierarchy, ?depth= and ?root= narrow it down
+	r.GET("/figma-files/:id/component-graph", parserHandler.GetFigmaFileComponentGraph)
+	r.GET("/figma-files/:id/styles", parserHandler.GetFigmaFileStyles) // ?type= filters by style type
+	r.GET("/figma-files/:id/variables", parserHandler.GetFigmaFileVariables)
+	r.GET("/figma-files/:id/tokens", parserHandler.GetFigmaFileTokens) // ?format=dtcg|style-dictionary|css|scss|ts
+	r.GET("/components/:id/codegen", parserHandler.GetComponentCode)   // ?target=react
+
+	// Static exports: the HTML export downloads image fills from Figma, SVG is drawn from the stored geometry
+	r.GET("/figma-files/:id/export/html", middlewares.ValidateFigmaToken(figmaManager), parserHandler.ExportFigmaFileHTML) // ?page=<page id or frame node id>
+	r.GET("/figma-files/:id/export/svg", parserHandler.ExportFigmaNodeSVG)                                                 // ?node=<node id>
+
+	// Team libraries, synced from the Figma team components, component sets and styles endpoints
+	r.POST("/libraries/sync", middlewares.ValidateFigmaToken(figmaManager), libraryHandler.SyncLibrary)
+	r.GET("/libraries/:team_id", libraryHandler.GetLibrary)
 }
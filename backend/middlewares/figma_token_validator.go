@@ -3,6 +3,7 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"parser-service/internal/errors"
 	"parser-service/internal/figma_manager"
 	"strings"
 
@@ -18,10 +19,7 @@ func ValidateFigmaToken(figmaManager figma_manager.IFigmaManager) gin.HandlerFun
 			// if no token in header, check query parameter
 			token = c.Query("figma_token")
 			if token == "" {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"error": "Figma token is required",
-				})
-				c.Abort()
+				abortUnauthorized(c, "Figma token is required")
 				return
 			}
 		}
@@ -31,29 +29,28 @@ func ValidateFigmaToken(figmaManager figma_manager.IFigmaManager) gin.HandlerFun
 		}
 		// if token is empty after removing "Bearer ", return unauthorized
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Figma token is required",
-			})
-			c.Abort()
+			abortUnauthorized(c, "Figma token is required")
 			return
 		}
 
 		// Validate token format
 		if !strings.HasPrefix(token, "figd_") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Figma token format. Token must start with 'figd_'",
-			})
-			c.Abort()
+			abortUnauthorized(c, "Invalid Figma token format. Token must start with 'figd_'")
 			return
 		}
 
 		// Validate token with Figma API
 		if err := figmaManager.ValidateFigmaToken(token); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid Figma token",
-				"details": err.Error(),
-			})
-			c.Abort()
+			// rate limits and Figma outages are not the caller's fault, keep their own status codes
+			if errors.Is(err, errors.ErrRateLimited) || errors.Is(err, errors.ErrUpstream) {
+				if retryAfter := errors.RetryAfterHeader(err); retryAfter != "" {
+					c.Header("Retry-After", retryAfter)
+				}
+				response := errors.NewErrorResponse(err, "Failed to validate Figma token")
+				c.AbortWithStatusJSON(response.Status, response)
+				return
+			}
+			abortUnauthorized(c, "Invalid Figma token: "+err.Error())
 			return
 		}
 
@@ -65,3 +62,13 @@ func ValidateFigmaToken(figmaManager figma_manager.IFigmaManager) gin.HandlerFun
 		c.Next()
 	}
 }
+
+// abortUnauthorized stops the request with a 401 in the common ErrorResponse format
+func abortUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, errors.ErrorResponse{
+		Err:     "Unauthorized",
+		Status:  http.StatusUnauthorized,
+		Code:    "unauthorized",
+		Message: message,
+	})
+}
@@ -0,0 +1,71 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/middlewares"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateFigmaToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := figmatest.NewServer()
+	defer server.Close()
+	client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRetryConfig(figma_manager.RetryConfig{MaxAttempts: 1})
+	manager := figma_manager.NewFigmaManagerWithClient(client)
+
+	router := gin.New()
+	router.GET("/protected", middlewares.ValidateFigmaToken(manager), func(c *gin.Context) {
+		token, _ := c.Request.Context().Value("figma_token").(string)
+		c.String(http.StatusOK, token)
+	})
+
+	tests := []struct {
+		name           string
+		header         string
+		query          string
+		failStatus     int
+		failHeaders    map[string]string
+		expectedStatus int
+		retryAfter     string
+	}{
+		{name: "Missing token", expectedStatus: http.StatusUnauthorized},
+		{name: "Empty bearer token", header: "Bearer ", expectedStatus: http.StatusUnauthorized},
+		{name: "Wrong token format", header: "Bearer abc123", expectedStatus: http.StatusUnauthorized},
+		{name: "Token rejected by Figma", header: "Bearer figd_unknown", expectedStatus: http.StatusUnauthorized},
+		{name: "Valid bearer token", header: "Bearer " + figmatest.ValidToken, expectedStatus: http.StatusOK},
+		{name: "Valid raw token", header: figmatest.ValidToken, expectedStatus: http.StatusOK},
+		{name: "Figma outage", header: "Bearer " + figmatest.ValidToken, failStatus: 503, expectedStatus: http.StatusBadGateway},
+		{name: "Figma rate limit", header: "Bearer " + figmatest.ValidToken, failStatus: 429, failHeaders: map[string]string{"Retry-After": "30"},
+			expectedStatus: http.StatusTooManyRequests, retryAfter: "30"},
+		{name: "Valid query token", query: "?figma_token=" + figmatest.ValidToken, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.failStatus != 0 {
+				server.FailNext("/v1/me", tt.failStatus, 1, tt.failHeaders)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/protected"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != tt.retryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.retryAfter, retryAfter)
+			}
+			if tt.expectedStatus == http.StatusOK && w.Body.String() != figmatest.ValidToken {
+				t.Errorf("Expected validated token in request context, got %q", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+// ComponentPropertyDefinition is a property a component or component set exposes to its instances,
+// stored by property name in Component.PropertyDefinitions. Names of non variant properties carry
+// a Figma suffix (e.g. "Label#2:0") which instances use to reference them
+type ComponentPropertyDefinition struct {
+	Type            string           `json:"type"`         // VARIANT, BOOLEAN, TEXT or INSTANCE_SWAP
+	DefaultValue    interface{}      `json:"defaultValue"` // bool for BOOLEAN, a component node ID for INSTANCE_SWAP, a string otherwise
+	VariantOptions  []string         `json:"variantOptions,omitempty"`
+	PreferredValues []PreferredValue `json:"preferredValues,omitempty"` // INSTANCE_SWAP only
+}
+
+// PreferredValue is a component or component set suggested for an INSTANCE_SWAP property
+type PreferredValue struct {
+	Type string `json:"type"` // COMPONENT or COMPONENT_SET
+	Key  string `json:"key"`
+}
+
+// InstanceComponentProperty is the value an instance gives to a property of its component,
+// stored by property name in Instance.ComponentProperties
+type InstanceComponentProperty struct {
+	Type  string      `json:"type"`  // VARIANT, BOOLEAN, TEXT or INSTANCE_SWAP
+	Value interface{} `json:"value"` // bool for BOOLEAN, a component node ID for INSTANCE_SWAP, a string otherwise
+}
+
+// InstanceOverride lists the fields overridden on a node of an instance (the instance itself or a sub-node),
+// stored in Instance.Overrides
+type InstanceOverride struct {
+	NodeID           string   `json:"id"`
+	OverriddenFields []string `json:"overriddenFields"` // e.g. characters, fills, visible
+}
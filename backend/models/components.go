@@ -8,18 +8,33 @@ import (
 // Component represents an extracted Figma component stored in the database.
 // It corresponds to the 'components' table.
 type Component struct {
-	ID          int64           `json:"id"`
-	FigmaFileID int64           `json:"figma_file_id"`
-	NodeID      string          `json:"node_id"`
-	Name        string          `json:"name"`
-	Type        string          `json:"type"`
-	Description string          `json:"description,omitempty"`
-	X           float64         `json:"x"`
-	Y           float64         `json:"y"`
-	Width       float64         `json:"width"`
-	Height      float64         `json:"height"`
-	Properties  json.RawMessage `json:"properties,omitempty"` // Use json.RawMessage for JSONB
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	Active      bool            `json:"active"`
+	ID                   int64           `json:"id"`
+	FigmaFileID          int64           `json:"figma_file_id"`
+	PageID               *int64          `json:"page_id"` // nil when not placed on a page, e.g. remote library components
+	PageNodeID           string          `json:"-"`       // Figma node ID of the page, used to resolve PageID when saving
+	NodeID               string          `json:"node_id"`
+	Name                 string          `json:"name"`
+	Type                 string          `json:"type"`
+	Description          string          `json:"description,omitempty"`
+	Key                  string          `json:"key,omitempty"`                  // Figma published key, matches the component in its library
+	IsExternal           bool            `json:"is_external"`                    // stub for a component defined in another file, e.g. a team library
+	LibraryComponentID   *int64          `json:"library_component_id"`           // synced team library component with the same key, see POST /libraries/sync
+	ComponentSetID       *int64          `json:"component_set_id"`               // set the variant belongs to, nil for other components
+	ComponentSetNodeID   string          `json:"-"`                              // Figma node ID of the set, used to resolve ComponentSetID when saving
+	VariantProperties    json.RawMessage `json:"variant_properties,omitempty"`   // variant key/value pairs parsed from the name, e.g. {"Size": "Large"}
+	PropertyDefinitions  json.RawMessage `json:"property_definitions,omitempty"` // ComponentPropertyDefinition by property name
+	X                    float64         `json:"x"`
+	Y                    float64         `json:"y"`
+	Width                float64         `json:"width"`
+	Height               float64         `json:"height"`
+	RelativeX            float64         `json:"relative_x"` // origin in the parent frame (relativeTransform), X and Y being the absolute bounding box
+	RelativeY            float64         `json:"relative_y"`
+	Rotation             float64         `json:"rotation"`                        // degrees, counter clockwise
+	ConstraintHorizontal string          `json:"constraint_horizontal,omitempty"` // LEFT, RIGHT, CENTER, LEFT_RIGHT or SCALE
+	ConstraintVertical   string          `json:"constraint_vertical,omitempty"`   // TOP, BOTTOM, CENTER, TOP_BOTTOM or SCALE
+	Properties           json.RawMessage `json:"properties,omitempty"`            // Use json.RawMessage for JSONB
+	Layout               *Layout         `json:"layout,omitempty"`                // decoded from Properties when read, see LayoutFromProperties
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+	Active               bool            `json:"active"`
 }
@@ -8,16 +8,31 @@ import (
 // Instance represents an instance of a component stored in the database.
 // It corresponds to the 'instances' table.
 type Instance struct {
-	ID          int64           `json:"id"`
-	ComponentID int64           `json:"component_id"`
-	NodeID      string          `json:"node_id"`
-	Name        string          `json:"name"`
-	X           float64         `json:"x"`
-	Y           float64         `json:"y"`
-	Width       float64         `json:"width"`
-	Height      float64         `json:"height"`
-	Properties  json.RawMessage `json:"properties,omitempty"` // Use json.RawMessage for JSONB
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	Active      bool            `json:"active"`
+	ID                        int64           `json:"id"`
+	ComponentID               int64           `json:"component_id"`
+	PageID                    *int64          `json:"page_id"`                 // nil when not placed on a page, e.g. remote library components
+	PageNodeID                string          `json:"-"`                       // Figma node ID of the page, used to resolve PageID when saving
+	ParentInstanceID          *int64          `json:"parent_instance_id"`      // instance this one is nested in, nil at the top level
+	ParentInstanceNodeID      string          `json:"-"`                       // Figma node ID of the parent instance, resolved when saving
+	ContainingComponentID     *int64          `json:"containing_component_id"` // component whose definition holds this instance
+	ContainingComponentNodeID string          `json:"-"`                       // Figma node ID of the containing component, resolved when saving
+	NodeID                    string          `json:"node_id"`
+	Name                      string          `json:"name"`
+	X                         float64         `json:"x"`
+	Y                         float64         `json:"y"`
+	Width                     float64         `json:"width"`
+	Height                    float64         `json:"height"`
+	RelativeX                 float64         `json:"relative_x"` // origin in the parent frame (relativeTransform), X and Y being the absolute bounding box
+	RelativeY                 float64         `json:"relative_y"`
+	Rotation                  float64         `json:"rotation"`                        // degrees, counter clockwise
+	ConstraintHorizontal      string          `json:"constraint_horizontal,omitempty"` // LEFT, RIGHT, CENTER, LEFT_RIGHT or SCALE
+	ConstraintVertical        string          `json:"constraint_vertical,omitempty"`   // TOP, BOTTOM, CENTER, TOP_BOTTOM or SCALE
+	ComponentProperties       json.RawMessage `json:"component_properties,omitempty"`  // InstanceComponentProperty by property name
+	Overrides                 json.RawMessage `json:"overrides,omitempty"`             // list of InstanceOverride
+	OverrideCount             int             `json:"override_count"`                  // overridden fields across the instance and its sub-nodes
+	Properties                json.RawMessage `json:"properties,omitempty"`            // Use json.RawMessage for JSONB
+	Layout                    *Layout         `json:"layout,omitempty"`                // decoded from Properties when read, see LayoutFromProperties
+	CreatedAt                 time.Time       `json:"created_at"`
+	UpdatedAt                 time.Time       `json:"updated_at"`
+	Active                    bool            `json:"active"`
 }
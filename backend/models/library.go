@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// LibraryComponent is a component or component set published to a team library.
+// It corresponds to the 'library_components' table.
+type LibraryComponent struct {
+	ID                 int64     `json:"id"`
+	TeamID             string    `json:"team_id"`
+	Key                string    `json:"key"`      // Figma published key, external components of parsed files link to it
+	FileKey            string    `json:"file_key"` // library file defining the component
+	NodeID             string    `json:"node_id"`
+	Name               string    `json:"name"`
+	Type               string    `json:"type"` // COMPONENT or COMPONENT_SET
+	Description        string    `json:"description,omitempty"`
+	ComponentSetNodeID string    `json:"component_set_node_id,omitempty"` // set of a variant, in the library file
+	PageName           string    `json:"page_name,omitempty"`
+	FrameName          string    `json:"frame_name,omitempty"`
+	ThumbnailURL       string    `json:"thumbnail_url,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	Active             bool      `json:"active"`
+}
+
+// LibraryStyle is a style published to a team library.
+// It corresponds to the 'library_styles' table.
+type LibraryStyle struct {
+	ID           int64     `json:"id"`
+	TeamID       string    `json:"team_id"`
+	Key          string    `json:"key"`
+	FileKey      string    `json:"file_key"`
+	NodeID       string    `json:"node_id"`
+	Name         string    `json:"name"`
+	StyleType    string    `json:"style_type"` // FILL, TEXT, EFFECT or GRID
+	Description  string    `json:"description,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Active       bool      `json:"active"`
+}
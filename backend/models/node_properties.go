@@ -0,0 +1,149 @@
+package models
+
+import "encoding/json"
+
+// NodePropertiesSchemaVersion is the version of the NodeProperties layout stored in the properties JSONB columns.
+// Bump it whenever a field changes meaning or is removed, so consumers can tell old rows apart.
+const NodePropertiesSchemaVersion = 1
+
+// NodeProperties is the structured content of Component.Properties, Instance.Properties and Node.Properties.
+// Colors are hex strings (#RRGGBB, or #RRGGBBAA when not opaque)
+type NodeProperties struct {
+	SchemaVersion    int          `json:"schemaVersion"`
+	NodeType         string       `json:"nodeType,omitempty"`
+	Visible          *bool        `json:"visible,omitempty"`
+	FigmaComponentID string       `json:"figmaComponentId,omitempty"` // instances only, Figma node ID of the main component
+	Opacity          *float64     `json:"opacity,omitempty"`          // nil means fully opaque
+	BlendMode        string       `json:"blendMode,omitempty"`
+	Fills            []Paint      `json:"fills,omitempty"`
+	Strokes          []Paint      `json:"strokes,omitempty"`
+	StrokeWeight     *float64     `json:"strokeWeight,omitempty"`
+	StrokeAlign      string       `json:"strokeAlign,omitempty"` // INSIDE, OUTSIDE or CENTER
+	Effects          []Effect     `json:"effects,omitempty"`
+	CornerRadii      *CornerRadii `json:"cornerRadii,omitempty"`
+	Layout           *Layout      `json:"layout,omitempty"` // nil when the node neither is nor sits in an auto layout
+	// Shapes only (see IsShapeType), when the file was fetched with geometry=paths: vector paths in the node coordinates, strokes
+	// outlined, and the transform from the node coordinates to the canvas
+	FillGeometry   []Path     `json:"fillGeometry,omitempty"`
+	StrokeGeometry []Path     `json:"strokeGeometry,omitempty"`
+	Transform      *Transform `json:"transform,omitempty"`
+	// Component properties bound to fields of the node, property name by field: characters (TEXT property),
+	// visible (BOOLEAN property) or mainComponent (INSTANCE_SWAP property)
+	PropertyReferences map[string]string `json:"propertyReferences,omitempty"`
+}
+
+// Paint is a solid, gradient or image fill or stroke
+type Paint struct {
+	Type      string  `json:"type"` // SOLID, GRADIENT_LINEAR, GRADIENT_RADIAL, GRADIENT_ANGULAR, GRADIENT_DIAMOND, IMAGE, ...
+	Visible   bool    `json:"visible"`
+	Opacity   float64 `json:"opacity"`
+	BlendMode string  `json:"blendMode,omitempty"`
+	// SOLID
+	Color string `json:"color,omitempty"`
+	// GRADIENT_*, handle positions are normalized to the node bounds
+	GradientHandlePositions []Vector       `json:"gradientHandlePositions,omitempty"`
+	GradientStops           []GradientStop `json:"gradientStops,omitempty"`
+	// IMAGE
+	ImageRef  string `json:"imageRef,omitempty"`
+	ScaleMode string `json:"scaleMode,omitempty"` // FILL, FIT, TILE or STRETCH
+}
+
+// GradientStop is a color at a position (0 to 1) along a gradient
+type GradientStop struct {
+	Position float64 `json:"position"`
+	Color    string  `json:"color"`
+}
+
+// Effect is a shadow or a blur
+type Effect struct {
+	Type      string  `json:"type"` // DROP_SHADOW, INNER_SHADOW, LAYER_BLUR or BACKGROUND_BLUR
+	Visible   bool    `json:"visible"`
+	Radius    float64 `json:"radius"`
+	BlendMode string  `json:"blendMode,omitempty"`
+	// Shadows only
+	Color  string  `json:"color,omitempty"`
+	Offset *Vector `json:"offset,omitempty"`
+	Spread float64 `json:"spread,omitempty"`
+}
+
+// Vector is a 2D point or offset
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Path is an SVG path of the geometry of a shape
+type Path struct {
+	Path        string `json:"path"`                  // SVG path data
+	WindingRule string `json:"windingRule,omitempty"` // NONZERO or EVENODD
+}
+
+// IsShapeType reports whether nodes of a type are drawn from their vector geometry, their children being part of it.
+// The parser only captures geometry for these, and the SVG export only draws it for them
+func IsShapeType(nodeType string) bool {
+	switch nodeType {
+	case "VECTOR", "BOOLEAN_OPERATION", "ELLIPSE", "RECTANGLE", "LINE", "STAR", "REGULAR_POLYGON":
+		return true
+	}
+	return false
+}
+
+// Transform is a 2D affine transform, [[a, c, x], [b, d, y]] mapping (px, py) to (a*px + c*py + x, b*px + d*py + y)
+type Transform [2][3]float64
+
+// Multiply returns the transform applying other, then t
+func (t Transform) Multiply(other Transform) Transform {
+	return Transform{
+		{t[0][0]*other[0][0] + t[0][1]*other[1][0], t[0][0]*other[0][1] + t[0][1]*other[1][1], t[0][0]*other[0][2] + t[0][1]*other[1][2] + t[0][2]},
+		{t[1][0]*other[0][0] + t[1][1]*other[1][0], t[1][0]*other[0][1] + t[1][1]*other[1][1], t[1][0]*other[0][2] + t[1][1]*other[1][2] + t[1][2]},
+	}
+}
+
+// IdentityTransform leaves points where they are
+var IdentityTransform = Transform{{1, 0, 0}, {0, 1, 0}}
+
+// CornerRadii holds the radius of each corner, equal when the node has a single corner radius
+type CornerRadii struct {
+	TopLeft     float64 `json:"topLeft"`
+	TopRight    float64 `json:"topRight"`
+	BottomRight float64 `json:"bottomRight"`
+	BottomLeft  float64 `json:"bottomLeft"`
+}
+
+// Layout is the normalized Auto Layout of a node: how it lays out its children when it is an auto layout
+// container (Mode set), and how it is sized and positioned within an auto layout parent
+type Layout struct {
+	Mode               string   `json:"mode,omitempty"`             // HORIZONTAL or VERTICAL, empty when not a container
+	Wrap               bool     `json:"wrap,omitempty"`             // children wrap onto new lines
+	PrimaryAxisAlign   string   `json:"primaryAxisAlign,omitempty"` // MIN, CENTER, MAX or SPACE_BETWEEN
+	CounterAxisAlign   string   `json:"counterAxisAlign,omitempty"` // MIN, CENTER, MAX or BASELINE
+	ItemSpacing        float64  `json:"itemSpacing,omitempty"`
+	CounterAxisSpacing float64  `json:"counterAxisSpacing,omitempty"` // spacing between wrapped lines
+	Padding            *Padding `json:"padding,omitempty"`
+	SizingHorizontal   string   `json:"sizingHorizontal,omitempty"` // FIXED, HUG or FILL
+	SizingVertical     string   `json:"sizingVertical,omitempty"`   // FIXED, HUG or FILL
+	Positioning        string   `json:"positioning,omitempty"`      // AUTO or ABSOLUTE (ignores the parent auto layout)
+	Grow               float64  `json:"grow,omitempty"`             // 1 when stretching along the parent primary axis
+}
+
+// Padding is the inner spacing of an auto layout container
+type Padding struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// LayoutFromProperties returns the layout stored in a properties document, nil when there is none
+func LayoutFromProperties(properties json.RawMessage) *Layout {
+	if len(properties) == 0 {
+		return nil
+	}
+	var decoded struct {
+		Layout *Layout `json:"layout"`
+	}
+	if err := json.Unmarshal(properties, &decoded); err != nil {
+		return nil
+	}
+	return decoded.Layout
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Node represents any node of the Figma document tree (document, pages, frames, groups, components, instances, ...).
+// It corresponds to the 'nodes' table.
+type Node struct {
+	ID                   int64           `json:"id"`
+	FigmaFileID          int64           `json:"figma_file_id"`
+	ParentID             *int64          `json:"parent_id"`      // nil for the DOCUMENT root
+	ParentNodeID         string          `json:"parent_node_id"` // Figma node ID of the parent, used to resolve ParentID when saving
+	NodeID               string          `json:"node_id"`
+	Name                 string          `json:"name"`
+	Type                 string          `json:"type"`
+	Depth                int             `json:"depth"`      // 0 for the DOCUMENT root
+	SortOrder            int             `json:"sort_order"` // position among its siblings
+	X                    float64         `json:"x"`
+	Y                    float64         `json:"y"`
+	Width                float64         `json:"width"`
+	Height               float64         `json:"height"`
+	RelativeX            float64         `json:"relative_x"` // origin in the parent frame (relativeTransform), X and Y being the absolute bounding box
+	RelativeY            float64         `json:"relative_y"`
+	Rotation             float64         `json:"rotation"`                        // degrees, counter clockwise
+	ConstraintHorizontal string          `json:"constraint_horizontal,omitempty"` // LEFT, RIGHT, CENTER, LEFT_RIGHT or SCALE
+	ConstraintVertical   string          `json:"constraint_vertical,omitempty"`   // TOP, BOTTOM, CENTER, TOP_BOTTOM or SCALE
+	Visible              bool            `json:"visible"`
+	Properties           json.RawMessage `json:"properties,omitempty"` // Use json.RawMessage for JSONB
+	Layout               *Layout         `json:"layout,omitempty"`     // decoded from Properties when read, see LayoutFromProperties
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+	Active               bool            `json:"active"`
+}
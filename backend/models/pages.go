@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Page represents a page (CANVAS node) of a Figma file.
+// It corresponds to the 'pages' table.
+type Page struct {
+	ID              int64     `json:"id"`
+	FigmaFileID     int64     `json:"figma_file_id"`
+	NodeID          string    `json:"node_id"`
+	Name            string    `json:"name"`
+	BackgroundColor string    `json:"background_color"` // hex, e.g. #F5F5F5, with alpha (#RRGGBBAA) when not opaque
+	SortOrder       int       `json:"sort_order"`       // position among the file pages
+	X               float64   `json:"x"`                // content bounds, computed from the page children
+	Y               float64   `json:"y"`
+	Width           float64   `json:"width"`
+	Height          float64   `json:"height"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Active          bool      `json:"active"`
+}
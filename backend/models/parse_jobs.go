@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+type ParseJobStatus string
+
+const (
+	ParseJobQueued    ParseJobStatus = "queued"
+	ParseJobRunning   ParseJobStatus = "running"
+	ParseJobSucceeded ParseJobStatus = "succeeded"
+	ParseJobFailed    ParseJobStatus = "failed"
+)
+
+// ParseJob represents an asynchronous parse of a Figma file.
+// It corresponds to the 'parse_jobs' table.
+type ParseJob struct {
+	ID          int64          `json:"id"`
+	FigmaURL    string         `json:"figma_url"`
+	Status      ParseJobStatus `json:"status"`
+	FigmaFileID *int64         `json:"figma_file_id,omitempty"` // set once the job succeeded
+	Error       string         `json:"error,omitempty"`
+	WorkerID    string         `json:"-"` // instance holding the job request in memory
+	HeartbeatAt *time.Time     `json:"-"` // last time WorkerID reported being alive
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Active      bool           `json:"active"`
+}
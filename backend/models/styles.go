@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Style is a shared color, text, effect or grid style of a Figma file.
+// It corresponds to the 'styles' table.
+type Style struct {
+	ID             int64           `json:"id"`
+	FigmaFileID    int64           `json:"figma_file_id"`
+	NodeID         string          `json:"node_id"` // style ID, as referenced by the styles of nodes
+	Key            string          `json:"key"`     // Figma published key, matches the style in its library
+	Name           string          `json:"name"`
+	StyleType      string          `json:"style_type"` // FILL, TEXT, EFFECT or GRID
+	Description    string          `json:"description,omitempty"`
+	IsExternal     bool            `json:"is_external"`     // defined in a library file, not in this one
+	Value          json.RawMessage `json:"value,omitempty"` // StyleValue resolved from a node using the style, nil when unused
+	ComponentCount int             `json:"component_count"` // components using the style, computed when read
+	InstanceCount  int             `json:"instance_count"`  // instances using the style, computed when read
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Active         bool            `json:"active"`
+}
+
+// StyleValue is what a style resolves to, depending on its type
+type StyleValue struct {
+	Paints  []Paint    `json:"paints,omitempty"`  // FILL styles, used as fills or strokes
+	Text    *TextStyle `json:"text,omitempty"`    // TEXT styles
+	Effects []Effect   `json:"effects,omitempty"` // EFFECT styles
+}
+
+// StyleUsage links a style to the component or instance using it, on the node itself or on a node inside it.
+// It corresponds to the 'style_usages' table.
+type StyleUsage struct {
+	ID              int64     `json:"id"`
+	StyleID         int64     `json:"style_id"`
+	StyleNodeID     string    `json:"-"` // style ID, used to resolve StyleID when saving
+	ComponentID     *int64    `json:"component_id"`
+	ComponentNodeID string    `json:"-"` // Figma node ID of the owning component, used to resolve ComponentID when saving
+	InstanceID      *int64    `json:"instance_id"`
+	InstanceNodeID  string    `json:"-"`        // Figma node ID of the owning instance, used to resolve InstanceID when saving
+	NodeID          string    `json:"node_id"`  // node the style is applied to
+	Property        string    `json:"property"` // fill, stroke, text, effect or grid
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Active          bool      `json:"active"`
+}
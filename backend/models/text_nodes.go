@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TextNode represents a TEXT node of a Figma file with its copy and typography.
+// It corresponds to the 'text_nodes' table.
+type TextNode struct {
+	ID              int64           `json:"id"`
+	FigmaFileID     int64           `json:"figma_file_id"`
+	PageID          *int64          `json:"page_id"`
+	ComponentID     *int64          `json:"component_id"` // owning component, nil when the text isn't inside one
+	InstanceID      *int64          `json:"instance_id"`  // owning instance, nil when the text isn't inside one
+	PageNodeID      string          `json:"-"`            // Figma node IDs of the page and owners, used to resolve the IDs above when saving
+	ComponentNodeID string          `json:"-"`
+	InstanceNodeID  string          `json:"-"`
+	NodeID          string          `json:"node_id"`
+	Name            string          `json:"name"`
+	Characters      string          `json:"characters"`
+	FontFamily      string          `json:"font_family"`
+	FontWeight      float64         `json:"font_weight"`
+	FontSize        float64         `json:"font_size"`
+	Style           json.RawMessage `json:"style,omitempty"`           // TextStyle as JSONB
+	StyleOverrides  json.RawMessage `json:"style_overrides,omitempty"` // TextStyleOverrides as JSONB, nil without overrides
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Active          bool            `json:"active"`
+}
+
+// TextStyle is the typography of a text node, or of a range of its characters in TextStyleOverrides.
+// Unset fields of an override inherit from the node style
+type TextStyle struct {
+	FontFamily          string  `json:"fontFamily,omitempty"`
+	FontPostScriptName  string  `json:"fontPostScriptName,omitempty"`
+	FontWeight          float64 `json:"fontWeight,omitempty"`
+	FontSize            float64 `json:"fontSize,omitempty"`
+	Italic              bool    `json:"italic,omitempty"`
+	LineHeightPx        float64 `json:"lineHeightPx,omitempty"`
+	LineHeightPercent   float64 `json:"lineHeightPercent,omitempty"` // percentage of the font size
+	LineHeightUnit      string  `json:"lineHeightUnit,omitempty"`    // PIXELS, FONT_SIZE_% or INTRINSIC_%
+	LetterSpacing       float64 `json:"letterSpacing,omitempty"`     // in pixels
+	TextCase            string  `json:"textCase,omitempty"`          // UPPER, LOWER, TITLE, SMALL_CAPS, ...
+	TextDecoration      string  `json:"textDecoration,omitempty"`    // UNDERLINE or STRIKETHROUGH
+	TextAlignHorizontal string  `json:"textAlignHorizontal,omitempty"`
+	TextAlignVertical   string  `json:"textAlignVertical,omitempty"`
+}
+
+// TextStyleOverrides maps characters to override styles: CharacterStyleOverrides[i] is the key in
+// StyleOverrideTable of the style of character i, 0 (or past the end of the list) meaning the node style
+type TextStyleOverrides struct {
+	CharacterStyleOverrides []int                `json:"characterStyleOverrides"`
+	StyleOverrideTable      map[string]TextStyle `json:"styleOverrideTable"`
+}
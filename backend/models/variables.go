@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VariableCollection is a set of Figma variables sharing the same modes, e.g. "Light" and "Dark".
+// It corresponds to the 'variable_collections' table.
+type VariableCollection struct {
+	ID                int64           `json:"id"`
+	FigmaFileID       int64           `json:"figma_file_id"`
+	FigmaCollectionID string          `json:"figma_collection_id"` // e.g. VariableCollectionId:1:2
+	Key               string          `json:"key"`
+	Name              string          `json:"name"`
+	Modes             json.RawMessage `json:"modes"` // []VariableMode, in Figma order
+	DefaultModeID     string          `json:"default_mode_id"`
+	IsExternal        bool            `json:"is_external"`  // defined in a library file, not in this one
+	IsPublished       bool            `json:"is_published"` // published to the team library from this file
+	Variables         []Variable      `json:"variables,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	Active            bool            `json:"active"`
+}
+
+// VariableMode is one of the modes of a variable collection
+type VariableMode struct {
+	ModeID string `json:"mode_id"`
+	Name   string `json:"name"`
+}
+
+// Variable is a Figma variable (design token) with a value per mode of its collection.
+// It corresponds to the 'variables' table.
+type Variable struct {
+	ID                   int64           `json:"id"`
+	FigmaFileID          int64           `json:"figma_file_id"`
+	VariableCollectionID int64           `json:"variable_collection_id"`
+	FigmaCollectionID    string          `json:"-"`                 // Figma ID of the collection, used to resolve VariableCollectionID when saving
+	FigmaVariableID      string          `json:"figma_variable_id"` // e.g. VariableID:1:3, as referenced by bound variables and aliases
+	Key                  string          `json:"key"`
+	Name                 string          `json:"name"`          // slash separated path, e.g. "color/primary"
+	ResolvedType         string          `json:"resolved_type"` // COLOR, FLOAT, STRING or BOOLEAN
+	Description          string          `json:"description,omitempty"`
+	Scopes               json.RawMessage `json:"scopes,omitempty"` // where the variable can be bound in Figma, e.g. ["FRAME_FILL", "GAP"]
+	IsExternal           bool            `json:"is_external"`
+	IsPublished          bool            `json:"is_published"`
+	Values               []VariableValue `json:"values"`
+	ComponentCount       int             `json:"component_count"` // components bound to the variable, computed when read
+	InstanceCount        int             `json:"instance_count"`  // instances bound to the variable, computed when read
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+	Active               bool            `json:"active"`
+}
+
+// VariableValue is the value of a variable in one mode, either a literal or an alias to another variable.
+// Colors are hex strings. ResolvedValue follows aliases down to a literal; it is nil when an alias can't be
+// resolved, ResolutionError then says why (e.g. an alias cycle).
+// It corresponds to the 'variable_values' table.
+type VariableValue struct {
+	ID              int64           `json:"id"`
+	VariableID      int64           `json:"variable_id"`
+	ModeID          string          `json:"mode_id"`
+	ModeName        string          `json:"mode_name"`
+	Value           json.RawMessage `json:"value,omitempty"`    // nil for aliases
+	AliasOf         string          `json:"alias_of,omitempty"` // Figma ID of the aliased variable
+	AliasName       string          `json:"alias_name,omitempty"`
+	ResolvedValue   json.RawMessage `json:"resolved_value,omitempty"`
+	ResolutionError string          `json:"resolution_error,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Active          bool            `json:"active"`
+}
+
+// VariableBinding links a variable to the component or instance bound to it, on the node itself or on a node
+// inside it. It corresponds to the 'variable_bindings' table.
+type VariableBinding struct {
+	ID              int64     `json:"id"`
+	VariableID      int64     `json:"variable_id"`
+	FigmaVariableID string    `json:"-"` // used to resolve VariableID when saving
+	ComponentID     *int64    `json:"component_id"`
+	ComponentNodeID string    `json:"-"` // Figma node ID of the owning component, used to resolve ComponentID when saving
+	InstanceID      *int64    `json:"instance_id"`
+	InstanceNodeID  string    `json:"-"`        // Figma node ID of the owning instance, used to resolve InstanceID when saving
+	NodeID          string    `json:"node_id"`  // node the variable is bound to
+	Property        string    `json:"property"` // bound field, e.g. fills, itemSpacing or componentProperties.Label#2:0
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Active          bool      `json:"active"`
+}
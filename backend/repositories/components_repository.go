@@ -2,8 +2,11 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"parser-service/internal/db_manager"
 	"parser-service/models"
+	"strings"
 )
 
 // Interface to support dependency injection for Components repository
@@ -12,7 +15,18 @@ import (
 type IComponentsRepository interface {
 	GetComponentByID(ctx context.Context, id int64) (*models.Component, error)
 	GetComponentsByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Component, error)
+	FindComponents(ctx context.Context, figmaFileID int64, filter ComponentFilter) ([]models.Component, error)
 	CreateComponent(ctx context.Context, component *models.Component) (*models.Component, error)
+	LinkLibraryComponents(ctx context.Context, figmaFileID int64) (int64, error)
+}
+
+// ComponentFilter narrows down FindComponents, zero values don't filter
+type ComponentFilter struct {
+	PageID         int64
+	ComponentSetID int64
+	Variant        map[string]string // variant key/value pairs the component must have, e.g. {"Size": "Large"}
+	Style          string            // name of a style the component or a node inside it uses, e.g. "Brand/Blue"
+	Variable       string            // name of a variable the component or a node inside it is bound to, e.g. "color/primary"
 }
 
 type ComponentsRepository struct {
@@ -24,20 +38,32 @@ func NewComponentsRepository(db db_manager.DB) *ComponentsRepository {
 }
 
 func (r *ComponentsRepository) GetComponentByID(ctx context.Context, id int64) (*models.Component, error) {
-	query := "SELECT id, figma_file_id, node_id, name, type, description, x, y, width, height, properties, created_at, updated_at, active FROM components WHERE id = $1 AND active = TRUE"
+	query := "SELECT id, figma_file_id, page_id, node_id, name, type, description, component_key, is_external, library_component_id, component_set_id, variant_properties, property_definitions, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, properties, created_at, updated_at, active FROM components WHERE id = $1 AND active = TRUE"
 	var component models.Component
 	// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
 	err := r.DB.GetRecord(ctx, query, id).Scan(
 		&component.ID,
 		&component.FigmaFileID,
+		&component.PageID,
 		&component.NodeID,
 		&component.Name,
 		&component.Type,
 		&component.Description,
+		&component.Key,
+		&component.IsExternal,
+		&component.LibraryComponentID,
+		&component.ComponentSetID,
+		&component.VariantProperties,
+		&component.PropertyDefinitions,
 		&component.X,
 		&component.Y,
 		&component.Width,
 		&component.Height,
+		&component.RelativeX,
+		&component.RelativeY,
+		&component.Rotation,
+		&component.ConstraintHorizontal,
+		&component.ConstraintVertical,
 		&component.Properties,
 		&component.CreatedAt,
 		&component.UpdatedAt,
@@ -49,7 +75,7 @@ func (r *ComponentsRepository) GetComponentByID(ctx context.Context, id int64) (
 }
 
 func (r *ComponentsRepository) GetComponentsByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Component, error) {
-	query := "SELECT id, figma_file_id, node_id, name, type, description, x, y, width, height, properties, created_at, updated_at, active FROM components WHERE figma_file_id = $1 AND active = TRUE ORDER BY created_at ASC"
+	query := "SELECT id, figma_file_id, page_id, node_id, name, type, description, component_key, is_external, library_component_id, component_set_id, variant_properties, property_definitions, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, properties, created_at, updated_at, active FROM components WHERE figma_file_id = $1 AND active = TRUE ORDER BY created_at ASC"
 	rows, err := r.DB.GetRecords(ctx, query, figmaFileID)
 	if err != nil {
 		return nil, err
@@ -62,14 +88,112 @@ func (r *ComponentsRepository) GetComponentsByFigmaFileID(ctx context.Context, f
 		err := rows.Scan(
 			&component.ID,
 			&component.FigmaFileID,
+			&component.PageID,
+			&component.NodeID,
+			&component.Name,
+			&component.Type,
+			&component.Description,
+			&component.Key,
+			&component.IsExternal,
+			&component.LibraryComponentID,
+			&component.ComponentSetID,
+			&component.VariantProperties,
+			&component.PropertyDefinitions,
+			&component.X,
+			&component.Y,
+			&component.Width,
+			&component.Height,
+			&component.RelativeX,
+			&component.RelativeY,
+			&component.Rotation,
+			&component.ConstraintHorizontal,
+			&component.ConstraintVertical,
+			&component.Properties,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+			&component.Active)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// FindComponents returns the components of a file matching every filter set in filter
+func (r *ComponentsRepository) FindComponents(ctx context.Context, figmaFileID int64, filter ComponentFilter) ([]models.Component, error) {
+	query := "SELECT id, figma_file_id, page_id, node_id, name, type, description, component_key, is_external, library_component_id, component_set_id, variant_properties, property_definitions, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, properties, created_at, updated_at, active FROM components WHERE figma_file_id = $1 AND active = TRUE"
+	args := []interface{}{figmaFileID}
+	if filter.PageID != 0 {
+		args = append(args, filter.PageID)
+		query += fmt.Sprintf(" AND page_id = $%d", len(args))
+	}
+	if filter.ComponentSetID != 0 {
+		args = append(args, filter.ComponentSetID)
+		query += fmt.Sprintf(" AND component_set_id = $%d", len(args))
+	}
+	if len(filter.Variant) > 0 {
+		// variant keys and values are matched case insensitively, "size=large" finds {"Size": "Large"}
+		variant := make(map[string]string, len(filter.Variant))
+		for key, value := range filter.Variant {
+			variant[strings.ToLower(key)] = strings.ToLower(value)
+		}
+		variantJSON, err := json.Marshal(variant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(variantJSON))
+		query += fmt.Sprintf(" AND LOWER(variant_properties::text)::jsonb @> $%d::jsonb", len(args))
+	}
+	if filter.Style != "" {
+		args = append(args, filter.Style)
+		query += fmt.Sprintf(` AND id IN (SELECT su.component_id FROM style_usages su INNER JOIN styles s ON su.style_id = s.id 
+				  WHERE s.figma_file_id = components.figma_file_id AND s.name = $%d AND s.active = TRUE AND su.active = TRUE)`, len(args))
+	}
+	if filter.Variable != "" {
+		args = append(args, filter.Variable)
+		query += fmt.Sprintf(` AND id IN (SELECT vb.component_id FROM variable_bindings vb INNER JOIN variables v ON vb.variable_id = v.id
+				  WHERE v.figma_file_id = components.figma_file_id AND v.name = $%d AND v.active = TRUE AND vb.active = TRUE)`, len(args))
+	}
+	query += " ORDER BY id ASC"
+
+	rows, err := r.DB.GetRecords(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []models.Component
+	for rows.Next() {
+		var component models.Component
+		err := rows.Scan(
+			&component.ID,
+			&component.FigmaFileID,
+			&component.PageID,
 			&component.NodeID,
 			&component.Name,
 			&component.Type,
 			&component.Description,
+			&component.Key,
+			&component.IsExternal,
+			&component.LibraryComponentID,
+			&component.ComponentSetID,
+			&component.VariantProperties,
+			&component.PropertyDefinitions,
 			&component.X,
 			&component.Y,
 			&component.Width,
 			&component.Height,
+			&component.RelativeX,
+			&component.RelativeY,
+			&component.Rotation,
+			&component.ConstraintHorizontal,
+			&component.ConstraintVertical,
 			&component.Properties,
 			&component.CreatedAt,
 			&component.UpdatedAt,
@@ -88,17 +212,28 @@ func (r *ComponentsRepository) GetComponentsByFigmaFileID(ctx context.Context, f
 }
 
 func (r *ComponentsRepository) CreateComponent(ctx context.Context, component *models.Component) (*models.Component, error) {
-	query := "INSERT INTO components (figma_file_id, node_id, name, type, description, x, y, width, height, properties, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	query := "INSERT INTO components (figma_file_id, page_id, node_id, name, type, description, component_key, is_external, component_set_id, variant_properties, property_definitions, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, properties, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
 	err := r.DB.CreateRecord(ctx, query,
 		component.FigmaFileID,
+		component.PageID,
 		component.NodeID,
 		component.Name,
 		component.Type,
 		component.Description,
+		component.Key,
+		component.IsExternal,
+		component.ComponentSetID,
+		component.VariantProperties,
+		component.PropertyDefinitions,
 		component.X,
 		component.Y,
 		component.Width,
 		component.Height,
+		component.RelativeX,
+		component.RelativeY,
+		component.Rotation,
+		component.ConstraintHorizontal,
+		component.ConstraintVertical,
 		component.Properties).Scan(&component.ID, &component.CreatedAt, &component.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -106,3 +241,36 @@ func (r *ComponentsRepository) CreateComponent(ctx context.Context, component *m
 	component.Active = true
 	return component, nil
 }
+
+// LinkLibraryComponents links the external components of a file (of every file when figmaFileID is 0)
+// to the synced library component with the same key, returning how many were linked. Links to library
+// components deactivated by a sync are re-pointed to an active one with the same key, or cleared
+func (r *ComponentsRepository) LinkLibraryComponents(ctx context.Context, figmaFileID int64) (int64, error) {
+	query := `UPDATE components c SET library_component_id = lc.id, updated_at = NOW() 
+			  FROM library_components lc 
+			  WHERE c.is_external = TRUE AND c.component_key <> '' AND c.component_key = lc.component_key 
+			  AND c.active = TRUE AND lc.active = TRUE AND c.library_component_id IS DISTINCT FROM lc.id`
+	unlinkQuery := `UPDATE components c SET library_component_id = NULL, updated_at = NOW() 
+			  FROM library_components lc 
+			  WHERE c.library_component_id = lc.id AND lc.active = FALSE`
+	var args []interface{}
+	if figmaFileID != 0 {
+		args = append(args, figmaFileID)
+		query += " AND c.figma_file_id = $1"
+		unlinkQuery += " AND c.figma_file_id = $1"
+	}
+
+	result, err := r.DB.UpdateRecords(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	linked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := r.DB.UpdateRecords(ctx, unlinkQuery, args...); err != nil {
+		return 0, err
+	}
+	return linked, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"parser-service/internal/db_manager"
 	"parser-service/models"
 )
@@ -13,9 +14,18 @@ type IInstancesRepository interface {
 	GetInstanceByID(ctx context.Context, id int64) (*models.Instance, error)
 	GetInstancesByComponentID(ctx context.Context, componentID int64) ([]models.Instance, error)
 	GetInstancesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Instance, error)
+	FindInstances(ctx context.Context, figmaFileID int64, filter InstanceFilter) ([]models.Instance, error)
 	CreateInstance(ctx context.Context, instance *models.Instance) (*models.Instance, error)
 }
 
+// InstanceFilter narrows down FindInstances, zero values don't filter
+type InstanceFilter struct {
+	PageID       int64
+	MinOverrides int    // minimum number of overridden fields, sorts the most overridden instances first
+	Style        string // name of a style the instance or a node inside it uses, e.g. "Brand/Blue"
+	Variable     string // name of a variable the instance or a node inside it is bound to, e.g. "color/primary"
+}
+
 type InstancesRepository struct {
 	DB db_manager.DB
 }
@@ -25,18 +35,29 @@ func NewInstancesRepository(db db_manager.DB) *InstancesRepository {
 }
 
 func (r *InstancesRepository) GetInstanceByID(ctx context.Context, id int64) (*models.Instance, error) {
-	query := "SELECT id, component_id, node_id, name, x, y, width, height, properties, created_at, updated_at, active FROM instances WHERE id = $1 AND active = TRUE"
+	query := "SELECT id, component_id, page_id, parent_instance_id, containing_component_id, node_id, name, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, component_properties, overrides, override_count, properties, created_at, updated_at, active FROM instances WHERE id = $1 AND active = TRUE"
 	var instance models.Instance
 	// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
 	err := r.DB.GetRecord(ctx, query, id).Scan(
 		&instance.ID,
 		&instance.ComponentID,
+		&instance.PageID,
+		&instance.ParentInstanceID,
+		&instance.ContainingComponentID,
 		&instance.NodeID,
 		&instance.Name,
 		&instance.X,
 		&instance.Y,
 		&instance.Width,
 		&instance.Height,
+		&instance.RelativeX,
+		&instance.RelativeY,
+		&instance.Rotation,
+		&instance.ConstraintHorizontal,
+		&instance.ConstraintVertical,
+		&instance.ComponentProperties,
+		&instance.Overrides,
+		&instance.OverrideCount,
 		&instance.Properties,
 		&instance.CreatedAt,
 		&instance.UpdatedAt,
@@ -48,7 +69,7 @@ func (r *InstancesRepository) GetInstanceByID(ctx context.Context, id int64) (*m
 }
 
 func (r *InstancesRepository) GetInstancesByComponentID(ctx context.Context, componentID int64) ([]models.Instance, error) {
-	query := "SELECT id, component_id, node_id, name, x, y, width, height, properties, created_at, updated_at, active FROM instances WHERE component_id = $1 AND active = TRUE ORDER BY created_at ASC"
+	query := "SELECT id, component_id, page_id, parent_instance_id, containing_component_id, node_id, name, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, component_properties, overrides, override_count, properties, created_at, updated_at, active FROM instances WHERE component_id = $1 AND active = TRUE ORDER BY created_at ASC"
 	rows, err := r.DB.GetRecords(ctx, query, componentID)
 	if err != nil {
 		return nil, err
@@ -61,12 +82,23 @@ func (r *InstancesRepository) GetInstancesByComponentID(ctx context.Context, com
 		err := rows.Scan(
 			&instance.ID,
 			&instance.ComponentID,
+			&instance.PageID,
+			&instance.ParentInstanceID,
+			&instance.ContainingComponentID,
 			&instance.NodeID,
 			&instance.Name,
 			&instance.X,
 			&instance.Y,
 			&instance.Width,
 			&instance.Height,
+			&instance.RelativeX,
+			&instance.RelativeY,
+			&instance.Rotation,
+			&instance.ConstraintHorizontal,
+			&instance.ConstraintVertical,
+			&instance.ComponentProperties,
+			&instance.Overrides,
+			&instance.OverrideCount,
 			&instance.Properties,
 			&instance.CreatedAt,
 			&instance.UpdatedAt,
@@ -85,7 +117,7 @@ func (r *InstancesRepository) GetInstancesByComponentID(ctx context.Context, com
 }
 
 func (r *InstancesRepository) GetInstancesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Instance, error) {
-	query := `SELECT i.id, i.component_id, i.node_id, i.name, i.x, i.y, i.width, i.height, i.properties, i.created_at, i.updated_at, i.active 
+	query := `SELECT i.id, i.component_id, i.page_id, i.parent_instance_id, i.containing_component_id, i.node_id, i.name, i.x, i.y, i.width, i.height, i.relative_x, i.relative_y, i.rotation, i.constraint_horizontal, i.constraint_vertical, i.component_properties, i.overrides, i.override_count, i.properties, i.created_at, i.updated_at, i.active 
 			  FROM instances i 
 			  INNER JOIN components c ON i.component_id = c.id 
 			  WHERE c.figma_file_id = $1 AND i.active = TRUE AND c.active = TRUE 
@@ -102,12 +134,99 @@ func (r *InstancesRepository) GetInstancesByFigmaFileID(ctx context.Context, fig
 		err := rows.Scan(
 			&instance.ID,
 			&instance.ComponentID,
+			&instance.PageID,
+			&instance.ParentInstanceID,
+			&instance.ContainingComponentID,
+			&instance.NodeID,
+			&instance.Name,
+			&instance.X,
+			&instance.Y,
+			&instance.Width,
+			&instance.Height,
+			&instance.RelativeX,
+			&instance.RelativeY,
+			&instance.Rotation,
+			&instance.ConstraintHorizontal,
+			&instance.ConstraintVertical,
+			&instance.ComponentProperties,
+			&instance.Overrides,
+			&instance.OverrideCount,
+			&instance.Properties,
+			&instance.CreatedAt,
+			&instance.UpdatedAt,
+			&instance.Active)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// FindInstances returns the instances of a file matching every filter set in filter
+func (r *InstancesRepository) FindInstances(ctx context.Context, figmaFileID int64, filter InstanceFilter) ([]models.Instance, error) {
+	query := `SELECT i.id, i.component_id, i.page_id, i.parent_instance_id, i.containing_component_id, i.node_id, i.name, i.x, i.y, i.width, i.height, i.relative_x, i.relative_y, i.rotation, i.constraint_horizontal, i.constraint_vertical, i.component_properties, i.overrides, i.override_count, i.properties, i.created_at, i.updated_at, i.active 
+			  FROM instances i 
+			  INNER JOIN components c ON i.component_id = c.id 
+			  WHERE c.figma_file_id = $1 AND i.active = TRUE AND c.active = TRUE`
+	args := []interface{}{figmaFileID}
+	if filter.PageID != 0 {
+		args = append(args, filter.PageID)
+		query += fmt.Sprintf(" AND i.page_id = $%d", len(args))
+	}
+	if filter.Style != "" {
+		args = append(args, filter.Style)
+		query += fmt.Sprintf(` AND i.id IN (SELECT su.instance_id FROM style_usages su INNER JOIN styles s ON su.style_id = s.id 
+				  WHERE s.figma_file_id = c.figma_file_id AND s.name = $%d AND s.active = TRUE AND su.active = TRUE)`, len(args))
+	}
+	if filter.Variable != "" {
+		args = append(args, filter.Variable)
+		query += fmt.Sprintf(` AND i.id IN (SELECT vb.instance_id FROM variable_bindings vb INNER JOIN variables v ON vb.variable_id = v.id
+				  WHERE v.figma_file_id = c.figma_file_id AND v.name = $%d AND v.active = TRUE AND vb.active = TRUE)`, len(args))
+	}
+	if filter.MinOverrides > 0 {
+		args = append(args, filter.MinOverrides)
+		query += fmt.Sprintf(" AND i.override_count >= $%d", len(args))
+		// the most customized instances first when looking for override heavy usage
+		query += " ORDER BY i.override_count DESC, i.id ASC"
+	} else {
+		query += " ORDER BY i.id ASC"
+	}
+
+	rows, err := r.DB.GetRecords(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instances []models.Instance
+	for rows.Next() {
+		var instance models.Instance
+		err := rows.Scan(
+			&instance.ID,
+			&instance.ComponentID,
+			&instance.PageID,
+			&instance.ParentInstanceID,
+			&instance.ContainingComponentID,
 			&instance.NodeID,
 			&instance.Name,
 			&instance.X,
 			&instance.Y,
 			&instance.Width,
 			&instance.Height,
+			&instance.RelativeX,
+			&instance.RelativeY,
+			&instance.Rotation,
+			&instance.ConstraintHorizontal,
+			&instance.ConstraintVertical,
+			&instance.ComponentProperties,
+			&instance.Overrides,
+			&instance.OverrideCount,
 			&instance.Properties,
 			&instance.CreatedAt,
 			&instance.UpdatedAt,
@@ -126,15 +245,26 @@ func (r *InstancesRepository) GetInstancesByFigmaFileID(ctx context.Context, fig
 }
 
 func (r *InstancesRepository) CreateInstance(ctx context.Context, instance *models.Instance) (*models.Instance, error) {
-	query := "INSERT INTO instances (component_id, node_id, name, x, y, width, height, properties, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	query := "INSERT INTO instances (component_id, page_id, parent_instance_id, containing_component_id, node_id, name, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, component_properties, overrides, override_count, properties, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
 	err := r.DB.CreateRecord(ctx, query,
 		instance.ComponentID,
+		instance.PageID,
+		instance.ParentInstanceID,
+		instance.ContainingComponentID,
 		instance.NodeID,
 		instance.Name,
 		instance.X,
 		instance.Y,
 		instance.Width,
 		instance.Height,
+		instance.RelativeX,
+		instance.RelativeY,
+		instance.Rotation,
+		instance.ConstraintHorizontal,
+		instance.ConstraintVertical,
+		instance.ComponentProperties,
+		instance.Overrides,
+		instance.OverrideCount,
 		instance.Properties).Scan(&instance.ID, &instance.CreatedAt, &instance.UpdatedAt)
 	if err != nil {
 		return nil, err
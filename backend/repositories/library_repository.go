@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Library repository
+// just in case we want to switch to a different storage solution in the future.
+
+type ILibraryRepository interface {
+	GetLibraryComponentsByTeamID(ctx context.Context, teamID string) ([]models.LibraryComponent, error)
+	GetLibraryStylesByTeamID(ctx context.Context, teamID string) ([]models.LibraryStyle, error)
+	UpsertLibraryComponent(ctx context.Context, component *models.LibraryComponent) (*models.LibraryComponent, error)
+	UpsertLibraryStyle(ctx context.Context, style *models.LibraryStyle) (*models.LibraryStyle, error)
+	DeactivateTeamLibrary(ctx context.Context, teamID string) error
+}
+
+type LibraryRepository struct {
+	DB db_manager.DB
+}
+
+func NewLibraryRepository(db db_manager.DB) *LibraryRepository {
+	return &LibraryRepository{DB: db}
+}
+
+func (r *LibraryRepository) GetLibraryComponentsByTeamID(ctx context.Context, teamID string) ([]models.LibraryComponent, error) {
+	query := "SELECT id, team_id, component_key, file_key, node_id, name, type, description, component_set_node_id, page_name, frame_name, thumbnail_url, created_at, updated_at, active FROM library_components WHERE team_id = $1 AND active = TRUE ORDER BY name ASC, id ASC"
+	rows, err := r.DB.GetRecords(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []models.LibraryComponent
+	for rows.Next() {
+		var component models.LibraryComponent
+		// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+		err := rows.Scan(
+			&component.ID,
+			&component.TeamID,
+			&component.Key,
+			&component.FileKey,
+			&component.NodeID,
+			&component.Name,
+			&component.Type,
+			&component.Description,
+			&component.ComponentSetNodeID,
+			&component.PageName,
+			&component.FrameName,
+			&component.ThumbnailURL,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+			&component.Active)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+func (r *LibraryRepository) GetLibraryStylesByTeamID(ctx context.Context, teamID string) ([]models.LibraryStyle, error) {
+	query := "SELECT id, team_id, style_key, file_key, node_id, name, style_type, description, thumbnail_url, created_at, updated_at, active FROM library_styles WHERE team_id = $1 AND active = TRUE ORDER BY name ASC, id ASC"
+	rows, err := r.DB.GetRecords(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var styles []models.LibraryStyle
+	for rows.Next() {
+		var style models.LibraryStyle
+		err := rows.Scan(
+			&style.ID,
+			&style.TeamID,
+			&style.Key,
+			&style.FileKey,
+			&style.NodeID,
+			&style.Name,
+			&style.StyleType,
+			&style.Description,
+			&style.ThumbnailURL,
+			&style.CreatedAt,
+			&style.UpdatedAt,
+			&style.Active)
+		if err != nil {
+			return nil, err
+		}
+		styles = append(styles, style)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return styles, nil
+}
+
+// UpsertLibraryComponent inserts a published component, or refreshes and reactivates the one with the same key
+func (r *LibraryRepository) UpsertLibraryComponent(ctx context.Context, component *models.LibraryComponent) (*models.LibraryComponent, error) {
+	query := `INSERT INTO library_components (team_id, component_key, file_key, node_id, name, type, description, component_set_node_id, page_name, frame_name, thumbnail_url, created_at, updated_at, active)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW(), TRUE)
+			  ON CONFLICT (component_key) DO UPDATE SET team_id = EXCLUDED.team_id, file_key = EXCLUDED.file_key, node_id = EXCLUDED.node_id,
+			  name = EXCLUDED.name, type = EXCLUDED.type, description = EXCLUDED.description, component_set_node_id = EXCLUDED.component_set_node_id,
+			  page_name = EXCLUDED.page_name, frame_name = EXCLUDED.frame_name, thumbnail_url = EXCLUDED.thumbnail_url, updated_at = NOW(), active = TRUE
+			  RETURNING id, created_at, updated_at`
+	err := r.DB.CreateRecord(ctx, query,
+		component.TeamID,
+		component.Key,
+		component.FileKey,
+		component.NodeID,
+		component.Name,
+		component.Type,
+		component.Description,
+		component.ComponentSetNodeID,
+		component.PageName,
+		component.FrameName,
+		component.ThumbnailURL).Scan(&component.ID, &component.CreatedAt, &component.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	component.Active = true
+	return component, nil
+}
+
+// UpsertLibraryStyle inserts a published style, or refreshes and reactivates the one with the same key
+func (r *LibraryRepository) UpsertLibraryStyle(ctx context.Context, style *models.LibraryStyle) (*models.LibraryStyle, error) {
+	query := `INSERT INTO library_styles (team_id, style_key, file_key, node_id, name, style_type, description, thumbnail_url, created_at, updated_at, active)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE)
+			  ON CONFLICT (style_key) DO UPDATE SET team_id = EXCLUDED.team_id, file_key = EXCLUDED.file_key, node_id = EXCLUDED.node_id,
+			  name = EXCLUDED.name, style_type = EXCLUDED.style_type, description = EXCLUDED.description, thumbnail_url = EXCLUDED.thumbnail_url,
+			  updated_at = NOW(), active = TRUE
+			  RETURNING id, created_at, updated_at`
+	err := r.DB.CreateRecord(ctx, query,
+		style.TeamID,
+		style.Key,
+		style.FileKey,
+		style.NodeID,
+		style.Name,
+		style.StyleType,
+		style.Description,
+		style.ThumbnailURL).Scan(&style.ID, &style.CreatedAt, &style.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	style.Active = true
+	return style, nil
+}
+
+// DeactivateTeamLibrary soft deletes every component and style of a team, a sync then reactivates the published ones
+func (r *LibraryRepository) DeactivateTeamLibrary(ctx context.Context, teamID string) error {
+	if _, err := r.DB.UpdateRecords(ctx, "UPDATE library_components SET active = FALSE, updated_at = NOW() WHERE team_id = $1 AND active = TRUE", teamID); err != nil {
+		return err
+	}
+	_, err := r.DB.UpdateRecords(ctx, "UPDATE library_styles SET active = FALSE, updated_at = NOW() WHERE team_id = $1 AND active = TRUE", teamID)
+	return err
+}
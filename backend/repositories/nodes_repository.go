@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Nodes repository
+// just in case we want to switch to a different storage solution in the future.
+
+type INodesRepository interface {
+	GetNodeTree(ctx context.Context, figmaFileID int64, rootNodeID string, maxDepth int) ([]models.Node, error)
+	CreateNode(ctx context.Context, node *models.Node) (*models.Node, error)
+}
+
+type NodesRepository struct {
+	DB db_manager.DB
+}
+
+func NewNodesRepository(db db_manager.DB) *NodesRepository {
+	return &NodesRepository{DB: db}
+}
+
+// GetNodeTree returns the subtree below rootNodeID (the whole document when empty), limited to maxDepth
+// levels below the root (unlimited when negative). Rows are ordered by depth, then sibling order,
+// so parents always come before their children.
+func (r *NodesRepository) GetNodeTree(ctx context.Context, figmaFileID int64, rootNodeID string, maxDepth int) ([]models.Node, error) {
+	query := `WITH RECURSIVE subtree AS (
+				SELECT id, figma_file_id, parent_id, parent_node_id, node_id, name, type, depth, sort_order, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, visible, properties, created_at, updated_at, active, 0 AS relative_depth
+				FROM nodes
+				WHERE figma_file_id = $1 AND active = TRUE AND ((CAST($2 AS TEXT) = '' AND parent_id IS NULL) OR node_id = $2)
+				UNION ALL
+				SELECT n.id, n.figma_file_id, n.parent_id, n.parent_node_id, n.node_id, n.name, n.type, n.depth, n.sort_order, n.x, n.y, n.width, n.height, n.relative_x, n.relative_y, n.rotation, n.constraint_horizontal, n.constraint_vertical, n.visible, n.properties, n.created_at, n.updated_at, n.active, s.relative_depth + 1
+				FROM nodes n
+				INNER JOIN subtree s ON n.parent_id = s.id
+				WHERE n.active = TRUE AND (CAST($3 AS INTEGER) < 0 OR s.relative_depth < $3)
+			  )
+			  SELECT id, figma_file_id, parent_id, parent_node_id, node_id, name, type, depth, sort_order, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, visible, properties, created_at, updated_at, active
+			  FROM subtree
+			  ORDER BY depth ASC, sort_order ASC`
+	rows, err := r.DB.GetRecords(ctx, query, figmaFileID, rootNodeID, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []models.Node
+	for rows.Next() {
+		var node models.Node
+		err := rows.Scan(
+			&node.ID,
+			&node.FigmaFileID,
+			&node.ParentID,
+			&node.ParentNodeID,
+			&node.NodeID,
+			&node.Name,
+			&node.Type,
+			&node.Depth,
+			&node.SortOrder,
+			&node.X,
+			&node.Y,
+			&node.Width,
+			&node.Height,
+			&node.RelativeX,
+			&node.RelativeY,
+			&node.Rotation,
+			&node.ConstraintHorizontal,
+			&node.ConstraintVertical,
+			&node.Visible,
+			&node.Properties,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+			&node.Active)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+func (r *NodesRepository) CreateNode(ctx context.Context, node *models.Node) (*models.Node, error) {
+	query := "INSERT INTO nodes (figma_file_id, parent_id, parent_node_id, node_id, name, type, depth, sort_order, x, y, width, height, relative_x, relative_y, rotation, constraint_horizontal, constraint_vertical, visible, properties, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		node.FigmaFileID,
+		node.ParentID,
+		node.ParentNodeID,
+		node.NodeID,
+		node.Name,
+		node.Type,
+		node.Depth,
+		node.SortOrder,
+		node.X,
+		node.Y,
+		node.Width,
+		node.Height,
+		node.RelativeX,
+		node.RelativeY,
+		node.Rotation,
+		node.ConstraintHorizontal,
+		node.ConstraintVertical,
+		node.Visible,
+		node.Properties).Scan(&node.ID, &node.CreatedAt, &node.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	node.Active = true
+	return node, nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Pages repository
+// just in case we want to switch to a different storage solution in the future.
+
+type IPagesRepository interface {
+	GetPageByID(ctx context.Context, id int64) (*models.Page, error)
+	GetPagesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Page, error)
+	CreatePage(ctx context.Context, page *models.Page) (*models.Page, error)
+}
+
+type PagesRepository struct {
+	DB db_manager.DB
+}
+
+func NewPagesRepository(db db_manager.DB) *PagesRepository {
+	return &PagesRepository{DB: db}
+}
+
+func (r *PagesRepository) GetPageByID(ctx context.Context, id int64) (*models.Page, error) {
+	query := "SELECT id, figma_file_id, node_id, name, background_color, sort_order, x, y, width, height, created_at, updated_at, active FROM pages WHERE id = $1 AND active = TRUE"
+	var page models.Page
+	// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+	err := r.DB.GetRecord(ctx, query, id).Scan(
+		&page.ID,
+		&page.FigmaFileID,
+		&page.NodeID,
+		&page.Name,
+		&page.BackgroundColor,
+		&page.SortOrder,
+		&page.X,
+		&page.Y,
+		&page.Width,
+		&page.Height,
+		&page.CreatedAt,
+		&page.UpdatedAt,
+		&page.Active)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (r *PagesRepository) GetPagesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Page, error) {
+	query := "SELECT id, figma_file_id, node_id, name, background_color, sort_order, x, y, width, height, created_at, updated_at, active FROM pages WHERE figma_file_id = $1 AND active = TRUE ORDER BY sort_order ASC"
+	rows, err := r.DB.GetRecords(ctx, query, figmaFileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []models.Page
+	for rows.Next() {
+		var page models.Page
+		err := rows.Scan(
+			&page.ID,
+			&page.FigmaFileID,
+			&page.NodeID,
+			&page.Name,
+			&page.BackgroundColor,
+			&page.SortOrder,
+			&page.X,
+			&page.Y,
+			&page.Width,
+			&page.Height,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.Active)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (r *PagesRepository) CreatePage(ctx context.Context, page *models.Page) (*models.Page, error) {
+	query := "INSERT INTO pages (figma_file_id, node_id, name, background_color, sort_order, x, y, width, height, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		page.FigmaFileID,
+		page.NodeID,
+		page.Name,
+		page.BackgroundColor,
+		page.SortOrder,
+		page.X,
+		page.Y,
+		page.Width,
+		page.Height).Scan(&page.ID, &page.CreatedAt, &page.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	page.Active = true
+	return page, nil
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+	"time"
+)
+
+// Interface to support dependency injection for Parse jobs repository
+// just in case we want to switch to a different storage solution in the future.
+
+type IParseJobsRepository interface {
+	GetParseJobByID(ctx context.Context, id int64) (*models.ParseJob, error)
+	CreateParseJob(ctx context.Context, job *models.ParseJob) (*models.ParseJob, error)
+	MarkParseJobRunning(ctx context.Context, id int64) error
+	MarkParseJobSucceeded(ctx context.Context, id int64, figmaFileID int64) error
+	MarkParseJobFailed(ctx context.Context, id int64, errorMessage string) error
+	HeartbeatParseJobs(ctx context.Context, workerID string) error
+	FailInterruptedParseJobs(ctx context.Context, workerID string, heartbeatTimeout time.Duration, errorMessage string) (int64, error)
+}
+
+type ParseJobsRepository struct {
+	DB db_manager.DB
+}
+
+func NewParseJobsRepository(db db_manager.DB) *ParseJobsRepository {
+	return &ParseJobsRepository{DB: db}
+}
+
+const parseJobColumns = "id, figma_url, status, figma_file_id, error, worker_id, heartbeat_at, started_at, finished_at, created_at, updated_at, active"
+
+func scanParseJob(row interface{ Scan(dest ...any) error }) (*models.ParseJob, error) {
+	var job models.ParseJob
+	// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+	err := row.Scan(
+		&job.ID,
+		&job.FigmaURL,
+		&job.Status,
+		&job.FigmaFileID,
+		&job.Error,
+		&job.WorkerID,
+		&job.HeartbeatAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.Active)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ParseJobsRepository) GetParseJobByID(ctx context.Context, id int64) (*models.ParseJob, error) {
+	query := "SELECT " + parseJobColumns + " FROM parse_jobs WHERE id = $1 AND active = TRUE"
+	return scanParseJob(r.DB.GetRecord(ctx, query, id))
+}
+
+// CreateParseJob inserts a queued job owned by job.WorkerID, the instance holding its request in memory
+func (r *ParseJobsRepository) CreateParseJob(ctx context.Context, job *models.ParseJob) (*models.ParseJob, error) {
+	query := "INSERT INTO parse_jobs (figma_url, status, error, worker_id, heartbeat_at, created_at, updated_at, active) VALUES ($1, $2, '', $3, NOW(), NOW(), NOW(), TRUE) RETURNING id, heartbeat_at, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		job.FigmaURL,
+		models.ParseJobQueued,
+		job.WorkerID).Scan(&job.ID, &job.HeartbeatAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = models.ParseJobQueued
+	job.Active = true
+	return job, nil
+}
+
+func (r *ParseJobsRepository) MarkParseJobRunning(ctx context.Context, id int64) error {
+	query := "UPDATE parse_jobs SET status = $2, started_at = NOW(), heartbeat_at = NOW(), updated_at = NOW() WHERE id = $1 RETURNING id"
+	return r.DB.UpdateRecord(ctx, query, id, models.ParseJobRunning).Scan(&id)
+}
+
+func (r *ParseJobsRepository) MarkParseJobSucceeded(ctx context.Context, id int64, figmaFileID int64) error {
+	query := "UPDATE parse_jobs SET status = $2, figma_file_id = $3, finished_at = NOW(), updated_at = NOW() WHERE id = $1 RETURNING id"
+	return r.DB.UpdateRecord(ctx, query, id, models.ParseJobSucceeded, figmaFileID).Scan(&id)
+}
+
+func (r *ParseJobsRepository) MarkParseJobFailed(ctx context.Context, id int64, errorMessage string) error {
+	query := "UPDATE parse_jobs SET status = $2, error = $3, finished_at = NOW(), updated_at = NOW() WHERE id = $1 RETURNING id"
+	return r.DB.UpdateRecord(ctx, query, id, models.ParseJobFailed, errorMessage).Scan(&id)
+}
+
+// HeartbeatParseJobs records that workerID is still alive for the unfinished jobs it owns
+func (r *ParseJobsRepository) HeartbeatParseJobs(ctx context.Context, workerID string) error {
+	query := "UPDATE parse_jobs SET heartbeat_at = NOW() WHERE worker_id = $1 AND status IN ($2, $3) AND active = TRUE"
+	_, err := r.DB.UpdateRecords(ctx, query, workerID, models.ParseJobQueued, models.ParseJobRunning)
+	return err
+}
+
+// FailInterruptedParseJobs marks as failed the queued and running jobs of workerID, and those of any worker whose last
+// heartbeat is older than heartbeatTimeout, returning how many were affected. Staleness is checked against the
+// database clock, which writes the heartbeats
+func (r *ParseJobsRepository) FailInterruptedParseJobs(ctx context.Context, workerID string, heartbeatTimeout time.Duration, errorMessage string) (int64, error) {
+	query := `UPDATE parse_jobs SET status = $1, error = $2, finished_at = NOW(), updated_at = NOW()
+		WHERE status IN ($3, $4) AND active = TRUE
+		AND (worker_id = $5 OR heartbeat_at IS NULL OR heartbeat_at < NOW() - $6 * interval '1 second')`
+	result, err := r.DB.UpdateRecords(ctx, query, models.ParseJobFailed, errorMessage, models.ParseJobQueued, models.ParseJobRunning,
+		workerID, heartbeatTimeout.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
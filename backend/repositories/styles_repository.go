@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Styles repository
+// just in case we want to switch to a different storage solution in the future.
+
+type IStylesRepository interface {
+	GetStylesByFigmaFileID(ctx context.Context, figmaFileID int64, styleType string) ([]models.Style, error)
+	CreateStyle(ctx context.Context, style *models.Style) (*models.Style, error)
+	CreateStyleUsage(ctx context.Context, usage *models.StyleUsage) (*models.StyleUsage, error)
+}
+
+type StylesRepository struct {
+	DB db_manager.DB
+}
+
+func NewStylesRepository(db db_manager.DB) *StylesRepository {
+	return &StylesRepository{DB: db}
+}
+
+// GetStylesByFigmaFileID returns the styles of a file with how many components and instances use them,
+// only those of styleType (FILL, TEXT, EFFECT or GRID) when not empty
+func (r *StylesRepository) GetStylesByFigmaFileID(ctx context.Context, figmaFileID int64, styleType string) ([]models.Style, error) {
+	query := `SELECT s.id, s.figma_file_id, s.node_id, s.style_key, s.name, s.style_type, s.description, s.is_external, s.value,
+			  (SELECT COUNT(DISTINCT su.component_id) FROM style_usages su WHERE su.style_id = s.id AND su.active = TRUE),
+			  (SELECT COUNT(DISTINCT su.instance_id) FROM style_usages su WHERE su.style_id = s.id AND su.active = TRUE),
+			  s.created_at, s.updated_at, s.active
+			  FROM styles s
+			  WHERE s.figma_file_id = $1 AND s.active = TRUE`
+	args := []interface{}{figmaFileID}
+	if styleType != "" {
+		args = append(args, styleType)
+		query += fmt.Sprintf(" AND s.style_type = $%d", len(args))
+	}
+	query += " ORDER BY s.name ASC, s.id ASC"
+
+	rows, err := r.DB.GetRecords(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var styles []models.Style
+	for rows.Next() {
+		var style models.Style
+		// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+		err := rows.Scan(
+			&style.ID,
+			&style.FigmaFileID,
+			&style.NodeID,
+			&style.Key,
+			&style.Name,
+			&style.StyleType,
+			&style.Description,
+			&style.IsExternal,
+			&style.Value,
+			&style.ComponentCount,
+			&style.InstanceCount,
+			&style.CreatedAt,
+			&style.UpdatedAt,
+			&style.Active)
+		if err != nil {
+			return nil, err
+		}
+		styles = append(styles, style)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return styles, nil
+}
+
+func (r *StylesRepository) CreateStyle(ctx context.Context, style *models.Style) (*models.Style, error) {
+	query := "INSERT INTO styles (figma_file_id, node_id, style_key, name, style_type, description, is_external, value, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		style.FigmaFileID,
+		style.NodeID,
+		style.Key,
+		style.Name,
+		style.StyleType,
+		style.Description,
+		style.IsExternal,
+		style.Value).Scan(&style.ID, &style.CreatedAt, &style.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	style.Active = true
+	return style, nil
+}
+
+func (r *StylesRepository) CreateStyleUsage(ctx context.Context, usage *models.StyleUsage) (*models.StyleUsage, error) {
+	query := "INSERT INTO style_usages (style_id, component_id, instance_id, node_id, property, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		usage.StyleID,
+		usage.ComponentID,
+		usage.InstanceID,
+		usage.NodeID,
+		usage.Property).Scan(&usage.ID, &usage.CreatedAt, &usage.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	usage.Active = true
+	return usage, nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Text nodes repository
+// just in case we want to switch to a different storage solution in the future.
+
+type ITextNodesRepository interface {
+	GetTextNodesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.TextNode, error)
+	GetTextNodesByPageID(ctx context.Context, pageID int64) ([]models.TextNode, error)
+	CreateTextNode(ctx context.Context, textNode *models.TextNode) (*models.TextNode, error)
+}
+
+type TextNodesRepository struct {
+	DB db_manager.DB
+}
+
+func NewTextNodesRepository(db db_manager.DB) *TextNodesRepository {
+	return &TextNodesRepository{DB: db}
+}
+
+func (r *TextNodesRepository) GetTextNodesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.TextNode, error) {
+	query := "SELECT id, figma_file_id, page_id, component_id, instance_id, node_id, name, characters, font_family, font_weight, font_size, style, style_overrides, created_at, updated_at, active FROM text_nodes WHERE figma_file_id = $1 AND active = TRUE ORDER BY id ASC"
+	return r.getTextNodes(ctx, query, figmaFileID)
+}
+
+func (r *TextNodesRepository) GetTextNodesByPageID(ctx context.Context, pageID int64) ([]models.TextNode, error) {
+	query := "SELECT id, figma_file_id, page_id, component_id, instance_id, node_id, name, characters, font_family, font_weight, font_size, style, style_overrides, created_at, updated_at, active FROM text_nodes WHERE page_id = $1 AND active = TRUE ORDER BY id ASC"
+	return r.getTextNodes(ctx, query, pageID)
+}
+
+func (r *TextNodesRepository) getTextNodes(ctx context.Context, query string, args ...interface{}) ([]models.TextNode, error) {
+	rows, err := r.DB.GetRecords(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var textNodes []models.TextNode
+	for rows.Next() {
+		var textNode models.TextNode
+		// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+		err := rows.Scan(
+			&textNode.ID,
+			&textNode.FigmaFileID,
+			&textNode.PageID,
+			&textNode.ComponentID,
+			&textNode.InstanceID,
+			&textNode.NodeID,
+			&textNode.Name,
+			&textNode.Characters,
+			&textNode.FontFamily,
+			&textNode.FontWeight,
+			&textNode.FontSize,
+			&textNode.Style,
+			&textNode.StyleOverrides,
+			&textNode.CreatedAt,
+			&textNode.UpdatedAt,
+			&textNode.Active)
+		if err != nil {
+			return nil, err
+		}
+		textNodes = append(textNodes, textNode)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return textNodes, nil
+}
+
+func (r *TextNodesRepository) CreateTextNode(ctx context.Context, textNode *models.TextNode) (*models.TextNode, error) {
+	query := "INSERT INTO text_nodes (figma_file_id, page_id, component_id, instance_id, node_id, name, characters, font_family, font_weight, font_size, style, style_overrides, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		textNode.FigmaFileID,
+		textNode.PageID,
+		textNode.ComponentID,
+		textNode.InstanceID,
+		textNode.NodeID,
+		textNode.Name,
+		textNode.Characters,
+		textNode.FontFamily,
+		textNode.FontWeight,
+		textNode.FontSize,
+		textNode.Style,
+		textNode.StyleOverrides).Scan(&textNode.ID, &textNode.CreatedAt, &textNode.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	textNode.Active = true
+	return textNode, nil
+}
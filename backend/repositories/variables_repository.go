@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"context"
+	"parser-service/internal/db_manager"
+	"parser-service/models"
+)
+
+// Interface to support dependency injection for Variables repository
+// just in case we want to switch to a different storage solution in the future.
+
+type IVariablesRepository interface {
+	GetVariableCollectionsByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.VariableCollection, error)
+	GetVariablesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Variable, error)
+	GetVariableValuesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.VariableValue, error)
+	CreateVariableCollection(ctx context.Context, collection *models.VariableCollection) (*models.VariableCollection, error)
+	CreateVariable(ctx context.Context, variable *models.Variable) (*models.Variable, error)
+	CreateVariableValue(ctx context.Context, value *models.VariableValue) (*models.VariableValue, error)
+	CreateVariableBinding(ctx context.Context, binding *models.VariableBinding) (*models.VariableBinding, error)
+}
+
+type VariablesRepository struct {
+	DB db_manager.DB
+}
+
+func NewVariablesRepository(db db_manager.DB) *VariablesRepository {
+	return &VariablesRepository{DB: db}
+}
+
+func (r *VariablesRepository) GetVariableCollectionsByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.VariableCollection, error) {
+	query := "SELECT id, figma_file_id, figma_collection_id, collection_key, name, modes, default_mode_id, is_external, is_published, created_at, updated_at, active FROM variable_collections WHERE figma_file_id = $1 AND active = TRUE ORDER BY name ASC, id ASC"
+	rows, err := r.DB.GetRecords(ctx, query, figmaFileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []models.VariableCollection
+	for rows.Next() {
+		var collection models.VariableCollection
+		// scanning to individual columns for backward compatibility in future. This is better than using * and scanning to struct directly
+		err := rows.Scan(
+			&collection.ID,
+			&collection.FigmaFileID,
+			&collection.FigmaCollectionID,
+			&collection.Key,
+			&collection.Name,
+			&collection.Modes,
+			&collection.DefaultModeID,
+			&collection.IsExternal,
+			&collection.IsPublished,
+			&collection.CreatedAt,
+			&collection.UpdatedAt,
+			&collection.Active)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// GetVariablesByFigmaFileID returns the variables of a file, without their values, with how many components and
+// instances are bound to them
+func (r *VariablesRepository) GetVariablesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.Variable, error) {
+	query := `SELECT v.id, v.figma_file_id, v.variable_collection_id, v.figma_variable_id, v.variable_key, v.name, v.resolved_type, v.description,
+			  v.scopes, v.is_external, v.is_published,
+			  (SELECT COUNT(DISTINCT vb.component_id) FROM variable_bindings vb WHERE vb.variable_id = v.id AND vb.active = TRUE),
+			  (SELECT COUNT(DISTINCT vb.instance_id) FROM variable_bindings vb WHERE vb.variable_id = v.id AND vb.active = TRUE),
+			  v.created_at, v.updated_at, v.active
+			  FROM variables v
+			  WHERE v.figma_file_id = $1 AND v.active = TRUE
+			  ORDER BY v.name ASC, v.id ASC`
+	rows, err := r.DB.GetRecords(ctx, query, figmaFileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variables []models.Variable
+	for rows.Next() {
+		var variable models.Variable
+		err := rows.Scan(
+			&variable.ID,
+			&variable.FigmaFileID,
+			&variable.VariableCollectionID,
+			&variable.FigmaVariableID,
+			&variable.Key,
+			&variable.Name,
+			&variable.ResolvedType,
+			&variable.Description,
+			&variable.Scopes,
+			&variable.IsExternal,
+			&variable.IsPublished,
+			&variable.ComponentCount,
+			&variable.InstanceCount,
+			&variable.CreatedAt,
+			&variable.UpdatedAt,
+			&variable.Active)
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, variable)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
+
+// GetVariableValuesByFigmaFileID returns the values of every variable of a file, in the order they were saved
+func (r *VariablesRepository) GetVariableValuesByFigmaFileID(ctx context.Context, figmaFileID int64) ([]models.VariableValue, error) {
+	query := `SELECT vv.id, vv.variable_id, vv.mode_id, vv.mode_name, vv.value, vv.alias_of, vv.alias_name, vv.resolved_value, vv.resolution_error,
+			  vv.created_at, vv.updated_at, vv.active
+			  FROM variable_values vv
+			  INNER JOIN variables v ON vv.variable_id = v.id
+			  WHERE v.figma_file_id = $1 AND v.active = TRUE AND vv.active = TRUE
+			  ORDER BY vv.id ASC`
+	rows, err := r.DB.GetRecords(ctx, query, figmaFileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []models.VariableValue
+	for rows.Next() {
+		var value models.VariableValue
+		err := rows.Scan(
+			&value.ID,
+			&value.VariableID,
+			&value.ModeID,
+			&value.ModeName,
+			&value.Value,
+			&value.AliasOf,
+			&value.AliasName,
+			&value.ResolvedValue,
+			&value.ResolutionError,
+			&value.CreatedAt,
+			&value.UpdatedAt,
+			&value.Active)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (r *VariablesRepository) CreateVariableCollection(ctx context.Context, collection *models.VariableCollection) (*models.VariableCollection, error) {
+	query := "INSERT INTO variable_collections (figma_file_id, figma_collection_id, collection_key, name, modes, default_mode_id, is_external, is_published, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		collection.FigmaFileID,
+		collection.FigmaCollectionID,
+		collection.Key,
+		collection.Name,
+		collection.Modes,
+		collection.DefaultModeID,
+		collection.IsExternal,
+		collection.IsPublished).Scan(&collection.ID, &collection.CreatedAt, &collection.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	collection.Active = true
+	return collection, nil
+}
+
+func (r *VariablesRepository) CreateVariable(ctx context.Context, variable *models.Variable) (*models.Variable, error) {
+	query := "INSERT INTO variables (figma_file_id, variable_collection_id, figma_variable_id, variable_key, name, resolved_type, description, scopes, is_external, is_published, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		variable.FigmaFileID,
+		variable.VariableCollectionID,
+		variable.FigmaVariableID,
+		variable.Key,
+		variable.Name,
+		variable.ResolvedType,
+		variable.Description,
+		variable.Scopes,
+		variable.IsExternal,
+		variable.IsPublished).Scan(&variable.ID, &variable.CreatedAt, &variable.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	variable.Active = true
+	return variable, nil
+}
+
+func (r *VariablesRepository) CreateVariableValue(ctx context.Context, value *models.VariableValue) (*models.VariableValue, error) {
+	query := "INSERT INTO variable_values (variable_id, mode_id, mode_name, value, alias_of, alias_name, resolved_value, resolution_error, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		value.VariableID,
+		value.ModeID,
+		value.ModeName,
+		value.Value,
+		value.AliasOf,
+		value.AliasName,
+		value.ResolvedValue,
+		value.ResolutionError).Scan(&value.ID, &value.CreatedAt, &value.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	value.Active = true
+	return value, nil
+}
+
+func (r *VariablesRepository) CreateVariableBinding(ctx context.Context, binding *models.VariableBinding) (*models.VariableBinding, error) {
+	query := "INSERT INTO variable_bindings (variable_id, component_id, instance_id, node_id, property, created_at, updated_at, active) VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), TRUE) RETURNING id, created_at, updated_at"
+	err := r.DB.CreateRecord(ctx, query,
+		binding.VariableID,
+		binding.ComponentID,
+		binding.InstanceID,
+		binding.NodeID,
+		binding.Property).Scan(&binding.ID, &binding.CreatedAt, &binding.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	binding.Active = true
+	return binding, nil
+}
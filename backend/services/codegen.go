@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parser-service/internal/codegen"
+	"parser-service/internal/errors"
+)
+
+// GenerateComponentCode - Generate the code of a component for target. A variant generates its component set,
+// which is what the instances of every variant refer to
+func (s *ParserService) GenerateComponentCode(ctx context.Context, componentID int64, target codegen.Target) (*codegen.File, error) {
+	component, err := s.ComponentsRepository.GetComponentByID(ctx, componentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.NotFound("component %d not found", componentID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get component: %w", err)
+	}
+	if component.IsExternal {
+		return nil, errors.InvalidInput("component %d comes from a library file, generate it from the file defining it", componentID)
+	}
+	if component.ComponentSetID != nil {
+		componentSetID := *component.ComponentSetID
+		component, err = s.ComponentsRepository.GetComponentByID(ctx, componentSetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFound("component set %d of component %d not found", componentSetID, componentID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get component set: %w", err)
+		}
+	}
+
+	nodes, err := s.NodesRepository.GetNodeTree(ctx, component.FigmaFileID, component.NodeID, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	components, err := s.ComponentsRepository.GetComponentsByFigmaFileID(ctx, component.FigmaFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get components: %w", err)
+	}
+	instances, err := s.InstancesRepository.GetInstancesByFigmaFileID(ctx, component.FigmaFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instances: %w", err)
+	}
+	textNodes, err := s.TextNodesRepository.GetTextNodesByFigmaFileID(ctx, component.FigmaFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text nodes: %w", err)
+	}
+
+	return codegen.Generate(codegen.Source{
+		Component:  *component,
+		Nodes:      nodes,
+		Components: components,
+		Instances:  instances,
+		TextNodes:  textNodes,
+	}, target)
+}
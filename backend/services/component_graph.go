@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"parser-service/models"
+	"sort"
+)
+
+// Kinds of component graph edges
+const (
+	// EdgeKindInstance - the source component places an instance of the target, directly in its definition
+	// or nested in an instance it holds
+	EdgeKindInstance = "instance"
+	// EdgeKindInstanceSwap - the source component swaps the target in through an INSTANCE_SWAP property,
+	// as the property default or as the value set on one of its instances
+	EdgeKindInstanceSwap = "instance_swap"
+)
+
+// ComponentGraph is the dependency graph of the components of a file, e.g. Card -> Button -> Icon
+type ComponentGraph struct {
+	Nodes []ComponentGraphNode `json:"nodes"`
+	Edges []ComponentGraphEdge `json:"edges"`
+}
+
+type ComponentGraphNode struct {
+	ID             int64  `json:"id"`
+	NodeID         string `json:"node_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	ComponentSetID *int64 `json:"component_set_id"`
+}
+
+// ComponentGraphEdge reads "Source uses Target", Count being the number of usages found
+type ComponentGraphEdge struct {
+	Source int64  `json:"source"`
+	Target int64  `json:"target"`
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+}
+
+// GetFigmaFileComponentGraph - Retrieve which components of a Figma file use which other components
+func (s *ParserService) GetFigmaFileComponentGraph(ctx context.Context, fileID int64) (*ComponentGraph, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	components, err := s.ComponentsRepository.GetComponentsByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get components: %w", err)
+	}
+
+	instances, err := s.InstancesRepository.GetInstancesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instances: %w", err)
+	}
+
+	return BuildComponentGraph(components, instances), nil
+}
+
+// BuildComponentGraph derives the component dependency graph from the instances placed in components
+// (or nested in instances) and from INSTANCE_SWAP properties. Nodes and edges are sorted by ID
+func BuildComponentGraph(components []models.Component, instances []models.Instance) *ComponentGraph {
+	graph := &ComponentGraph{Nodes: []ComponentGraphNode{}, Edges: []ComponentGraphEdge{}}
+
+	componentIDs := make(map[string]int64, len(components))
+	for _, component := range components {
+		componentIDs[component.NodeID] = component.ID
+		graph.Nodes = append(graph.Nodes, ComponentGraphNode{
+			ID:             component.ID,
+			NodeID:         component.NodeID,
+			Name:           component.Name,
+			Type:           component.Type,
+			ComponentSetID: component.ComponentSetID,
+		})
+	}
+	instanceComponents := make(map[int64]int64, len(instances))
+	for _, instance := range instances {
+		instanceComponents[instance.ID] = instance.ComponentID
+	}
+
+	edges := make(map[ComponentGraphEdge]int)
+	addEdge := func(source, target int64, kind string) {
+		if source != 0 && target != 0 && source != target {
+			edges[ComponentGraphEdge{Source: source, Target: target, Kind: kind}]++
+		}
+	}
+
+	for _, component := range components {
+		var definitions map[string]models.ComponentPropertyDefinition
+		if json.Unmarshal(component.PropertyDefinitions, &definitions) != nil {
+			continue
+		}
+		for _, definition := range definitions {
+			if swapNodeID, ok := definition.DefaultValue.(string); ok && definition.Type == "INSTANCE_SWAP" {
+				addEdge(component.ID, componentIDs[swapNodeID], EdgeKindInstanceSwap)
+			}
+		}
+	}
+
+	for _, instance := range instances {
+		// the closest container wins: the instance of the Card a Button is nested in, else the component it is placed in
+		if instance.ParentInstanceID != nil {
+			addEdge(instanceComponents[*instance.ParentInstanceID], instance.ComponentID, EdgeKindInstance)
+		} else if instance.ContainingComponentID != nil {
+			addEdge(*instance.ContainingComponentID, instance.ComponentID, EdgeKindInstance)
+		}
+
+		var properties map[string]models.InstanceComponentProperty
+		if json.Unmarshal(instance.ComponentProperties, &properties) != nil {
+			continue
+		}
+		for _, property := range properties {
+			if swapNodeID, ok := property.Value.(string); ok && property.Type == "INSTANCE_SWAP" {
+				addEdge(instance.ComponentID, componentIDs[swapNodeID], EdgeKindInstanceSwap)
+			}
+		}
+	}
+
+	for edge, count := range edges {
+		edge.Count = count
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Kind < b.Kind
+	})
+
+	return graph
+}
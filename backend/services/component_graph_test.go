@@ -0,0 +1,42 @@
+package services_test
+
+import (
+	"encoding/json"
+	"parser-service/models"
+	"parser-service/services"
+	"reflect"
+	"testing"
+)
+
+func TestBuildComponentGraph(t *testing.T) {
+	card, button, icon := int64(1), int64(2), int64(3)
+	cardInstance := int64(10)
+	components := []models.Component{
+		{ID: card, NodeID: "3:1", Name: "Card", Type: "COMPONENT"},
+		{ID: button, NodeID: "2:2", Name: "Button", Type: "COMPONENT",
+			PropertyDefinitions: json.RawMessage(`{"Icon#2:6": {"type": "INSTANCE_SWAP", "defaultValue": "9:9"}}`)},
+		{ID: icon, NodeID: "9:9", Name: "Icon", Type: "COMPONENT"},
+	}
+	instances := []models.Instance{
+		// Button placed in the Card definition
+		{ID: 11, ComponentID: button, ContainingComponentID: &card},
+		// a Card on a page, with the Button nested in it
+		{ID: cardInstance, ComponentID: card},
+		{ID: 12, ComponentID: button, ParentInstanceID: &cardInstance},
+		// a Button on a page swapping its icon
+		{ID: 13, ComponentID: button, ComponentProperties: json.RawMessage(`{"Icon#2:6": {"type": "INSTANCE_SWAP", "value": "9:9"}}`)},
+	}
+
+	graph := services.BuildComponentGraph(components, instances)
+
+	if len(graph.Nodes) != 3 || graph.Nodes[0].Name != "Card" {
+		t.Errorf("Expected the 3 components as nodes, got %+v", graph.Nodes)
+	}
+	expected := []services.ComponentGraphEdge{
+		{Source: card, Target: button, Kind: services.EdgeKindInstance, Count: 2},
+		{Source: button, Target: icon, Kind: services.EdgeKindInstanceSwap, Count: 2},
+	}
+	if !reflect.DeepEqual(graph.Edges, expected) {
+		t.Errorf("Expected edges %+v, got %+v", expected, graph.Edges)
+	}
+}
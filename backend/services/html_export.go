@@ -0,0 +1,132 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"parser-service/internal/codegen"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"strconv"
+)
+
+// exportableTypes are the nodes that can be exported as a static site
+var exportableTypes = map[string]bool{
+	"CANVAS": true, "FRAME": true, "SECTION": true, "GROUP": true, "COMPONENT": true, "COMPONENT_SET": true, "INSTANCE": true,
+}
+
+// HTMLExport is a page or frame exported as a static site, zipped with the images of its image fills by WriteZip
+type HTMLExport struct {
+	FileName     string // archive name, e.g. landing.zip
+	fileID       int64
+	fileKey      string
+	site         *codegen.Site
+	figmaManager figma_manager.IFigmaManager
+}
+
+// ExportFigmaFileHTML - Export a page (by page ID) or a frame (by Figma node ID) of a Figma file as a static site.
+// Everything that can fail the export is checked here, the archive is written afterwards by WriteZip
+func (s *ParserService) ExportFigmaFileHTML(ctx context.Context, fileID int64, page string) (*HTMLExport, error) {
+	file, err := s.getFigmaFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootNodeID := page
+	if pageID, err := strconv.ParseInt(page, 10, 64); err == nil {
+		model, err := s.PagesRepository.GetPageByID(ctx, pageID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && model.FigmaFileID != fileID) {
+			return nil, errors.NotFound("page %d not found in figma file %d", pageID, fileID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page: %w", err)
+		}
+		rootNodeID = model.NodeID
+	}
+
+	nodes, err := s.NodesRepository.GetNodeTree(ctx, fileID, rootNodeID, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, errors.NotFound("node %s not found in figma file %d, parse it again if it is new", rootNodeID, fileID)
+	}
+	if !exportableTypes[nodes[0].Type] {
+		return nil, errors.InvalidInput("node %s is a %s, only pages and frames can be exported", rootNodeID, nodes[0].Type)
+	}
+	textNodes, err := s.TextNodesRepository.GetTextNodesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text nodes: %w", err)
+	}
+
+	source := codegen.PageSource{Name: nodes[0].Name, RootNodeID: rootNodeID, Nodes: nodes, TextNodes: textNodes}
+	if nodes[0].Type == "CANVAS" {
+		pages, err := s.PagesRepository.GetPagesByFigmaFileID(ctx, fileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pages: %w", err)
+		}
+		for _, model := range pages {
+			if model.NodeID == rootNodeID {
+				source.Background = model.BackgroundColor
+			}
+		}
+	}
+	site, err := codegen.ExportHTML(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTMLExport{FileName: site.Name + ".zip", fileID: fileID, fileKey: file.FileKey, site: site, figmaManager: s.FigmaManager}, nil
+}
+
+// WriteZip streams the archive to w, downloading the images one at a time and naming them after their type. The
+// site is still usable without its images, those that can't be downloaded are left out of the archive
+func (e *HTMLExport) WriteZip(ctx context.Context, w io.Writer) error {
+	writer := zip.NewWriter(w)
+	add := func(name string, content []byte) error {
+		entry, err := writer.Create(name)
+		if err == nil {
+			_, err = entry.Write(content)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	// The images go first, index.html and styles.css then refer to them by the names their types gave them
+	files := make(map[string]string, len(e.site.Assets))
+	if len(e.site.Assets) > 0 {
+		var writeErr error
+		err := e.figmaManager.GetImageFills(ctx, e.fileKey, e.site.Assets, func(imageRef string, image []byte) error {
+			files[imageRef] = codegen.AssetFile(imageRef, image)
+			writeErr = add(files[imageRef], image)
+			return writeErr
+		})
+		if writeErr != nil {
+			return writeErr
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Could not download the image fills of figma file %d, exporting without them: %v", e.fileID, err)
+		}
+	}
+
+	htmlContent, css := e.site.LinkAssets(files)
+	if err := add("index.html", htmlContent); err != nil {
+		return err
+	}
+	if err := add("styles.css", css); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
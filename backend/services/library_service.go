@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"parser-service/internal/db_manager"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/models"
+	"parser-service/repositories"
+	"regexp"
+)
+
+// teamIDPattern matches Figma team IDs, the number in https://www.figma.com/files/team/<team id>/...
+var teamIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// LibraryService keeps a copy of what a team published to its Figma library, so the external components
+// of parsed files can be linked to their library definition
+type LibraryService struct {
+	DB                   db_manager.ItxDB
+	FigmaManager         figma_manager.IFigmaManager
+	LibraryRepository    repositories.ILibraryRepository
+	ComponentsRepository repositories.IComponentsRepository
+}
+
+func NewLibraryService(
+	db db_manager.ItxDB,
+	figmaManager figma_manager.IFigmaManager,
+	libraryRepo repositories.ILibraryRepository,
+	componentsRepo repositories.IComponentsRepository,
+) *LibraryService {
+	return &LibraryService{
+		DB:                   db,
+		FigmaManager:         figmaManager,
+		LibraryRepository:    libraryRepo,
+		ComponentsRepository: componentsRepo,
+	}
+}
+
+// LibrarySyncResult sums up a team library sync
+type LibrarySyncResult struct {
+	TeamID           string `json:"team_id"`
+	Components       int    `json:"components"`
+	ComponentSets    int    `json:"component_sets"`
+	Styles           int    `json:"styles"`
+	LinkedComponents int64  `json:"linked_components"` // external components of parsed files newly linked to the library
+}
+
+// TeamLibraryDetails is the synced library of a team
+type TeamLibraryDetails struct {
+	TeamID     string                    `json:"team_id"`
+	Components []models.LibraryComponent `json:"components"`
+	Styles     []models.LibraryStyle     `json:"styles"`
+}
+
+// SyncTeamLibrary - Fetches the published components, component sets and styles of a team and replaces the stored
+// copy of its library all-or-nothing. Entries no longer published are deactivated
+func (s *LibraryService) SyncTeamLibrary(ctx context.Context, teamID string) (*LibrarySyncResult, error) {
+	if !teamIDPattern.MatchString(teamID) {
+		return nil, errors.InvalidInput("team_id must be a Figma team ID, got %q", teamID)
+	}
+
+	library, err := s.FigmaManager.GetTeamLibrary(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team library: %w", err)
+	}
+
+	result := &LibrarySyncResult{TeamID: teamID}
+	err = db_manager.WrapInTransaction(ctx, s.DB, func(ctx context.Context) error {
+		if err := s.LibraryRepository.DeactivateTeamLibrary(ctx, teamID); err != nil {
+			return fmt.Errorf("failed to deactivate team library: %w", err)
+		}
+
+		for _, published := range library.ComponentSets {
+			if _, err := s.LibraryRepository.UpsertLibraryComponent(ctx, libraryComponent(teamID, "COMPONENT_SET", published)); err != nil {
+				return fmt.Errorf("failed to save library component set %s: %w", published.Name, err)
+			}
+			result.ComponentSets++
+		}
+		for _, published := range library.Components {
+			if _, err := s.LibraryRepository.UpsertLibraryComponent(ctx, libraryComponent(teamID, "COMPONENT", published)); err != nil {
+				return fmt.Errorf("failed to save library component %s: %w", published.Name, err)
+			}
+			result.Components++
+		}
+		for _, published := range library.Styles {
+			style := &models.LibraryStyle{
+				TeamID:       teamID,
+				Key:          published.Key,
+				FileKey:      published.FileKey,
+				NodeID:       published.NodeID,
+				Name:         published.Name,
+				StyleType:    published.StyleType,
+				Description:  published.Description,
+				ThumbnailURL: published.ThumbnailURL,
+			}
+			if _, err := s.LibraryRepository.UpsertLibraryStyle(ctx, style); err != nil {
+				return fmt.Errorf("failed to save library style %s: %w", published.Name, err)
+			}
+			result.Styles++
+		}
+
+		// Files parsed before the sync get their external components linked too, and lose links to deactivated entries
+		linked, err := s.ComponentsRepository.LinkLibraryComponents(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("failed to link library components: %w", err)
+		}
+		result.LinkedComponents = linked
+		return nil
+	}, func(err error) {
+		log.Printf("Rolled back sync of team library %s: %v", teamID, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetTeamLibrary - Retrieve the synced library of a team
+func (s *LibraryService) GetTeamLibrary(ctx context.Context, teamID string) (*TeamLibraryDetails, error) {
+	components, err := s.LibraryRepository.GetLibraryComponentsByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library components: %w", err)
+	}
+	styles, err := s.LibraryRepository.GetLibraryStylesByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library styles: %w", err)
+	}
+	if len(components) == 0 && len(styles) == 0 {
+		return nil, errors.NotFound("no library synced for team %s", teamID)
+	}
+
+	return &TeamLibraryDetails{TeamID: teamID, Components: components, Styles: styles}, nil
+}
+
+// libraryComponent converts a published component or component set to a LibraryComponent model
+func libraryComponent(teamID, componentType string, published figma_manager.PublishedComponent) *models.LibraryComponent {
+	component := &models.LibraryComponent{
+		TeamID:       teamID,
+		Key:          published.Key,
+		FileKey:      published.FileKey,
+		NodeID:       published.NodeID,
+		Name:         published.Name,
+		Type:         componentType,
+		Description:  published.Description,
+		ThumbnailURL: published.ThumbnailURL,
+	}
+	if frame := published.ContainingFrame; frame != nil {
+		component.PageName = frame.PageName
+		component.FrameName = frame.Name
+		if frame.ContainingStateGroup != nil {
+			component.ComponentSetNodeID = frame.ContainingStateGroup.NodeID
+		}
+	}
+	return component
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"parser-service/internal/db_manager"
+	"parser-service/internal/errors"
+	"parser-service/internal/figma_manager"
+	"parser-service/internal/figma_manager/figmatest"
+	"parser-service/repositories"
+	"parser-service/services"
+	"testing"
+)
+
+func newTestLibraryService(store *fakeStore, server *figmatest.Server) *services.LibraryService {
+	db := db_manager.NewDB(sql.OpenDB(store))
+	client := figma_manager.NewFigmaClientWithConfig(server.BaseURL(), server.Client()).WithRateLimiter(nil)
+	return services.NewLibraryService(
+		db,
+		figma_manager.NewFigmaManagerWithClient(client),
+		repositories.NewLibraryRepository(*db),
+		repositories.NewComponentsRepository(*db),
+	)
+}
+
+func TestSyncTeamLibrary_CommitsLibraryRows(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	store := newFakeStore()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	result, err := newTestLibraryService(store, server).SyncTeamLibrary(ctx, figmatest.SampleTeamID)
+	if err != nil {
+		t.Fatalf("Expected sync to succeed, got: %v", err)
+	}
+	if result.Components != 3 || result.ComponentSets != 1 || result.Styles != 2 {
+		t.Errorf("Expected 3 components, 1 component set and 2 styles synced, got %+v", result)
+	}
+
+	expected := map[string]int{"library_components": 4, "library_styles": 2}
+	for table, count := range expected {
+		if store.committed[table] != count {
+			t.Errorf("Expected %d committed rows in %s, got %d", count, table, store.committed[table])
+		}
+	}
+}
+
+func TestSyncTeamLibrary_RejectsInvalidTeamID(t *testing.T) {
+	server := figmatest.NewServer()
+	defer server.Close()
+	ctx := context.WithValue(context.Background(), "figma_token", figmatest.ValidToken)
+
+	_, err := newTestLibraryService(newFakeStore(), server).SyncTeamLibrary(ctx, "../files")
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Expected an invalid input error, got %v", err)
+	}
+	if count := server.RequestCount("/v1/teams"); count != 0 {
+		t.Errorf("Expected no Figma request, got %d", count)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"parser-service/internal/progress"
+	"sync"
+	"time"
+)
+
+const (
+	// progressRetention is how long a finished job keeps its events for late subscribers
+	progressRetention = time.Minute
+	// subscriberBufferSize is how many events a slow subscriber may lag behind before intermediate ones are dropped
+	subscriberBufferSize = 64
+)
+
+// progressBroker fans out progress events of running parse jobs to SSE subscribers
+type progressBroker struct {
+	mu   sync.Mutex
+	jobs map[int64]*jobProgress
+}
+
+type jobProgress struct {
+	events      []progress.Event // latest event per stage, in the order stages were first seen
+	subscribers map[chan progress.Event]struct{}
+	finished    bool
+}
+
+func newProgressBroker() *progressBroker {
+	return &progressBroker{jobs: make(map[int64]*jobProgress)}
+}
+
+// getOrCreate must be called with the lock held
+func (b *progressBroker) getOrCreate(jobID int64) *jobProgress {
+	job, ok := b.jobs[jobID]
+	if !ok {
+		job = &jobProgress{subscribers: make(map[chan progress.Event]struct{})}
+		b.jobs[jobID] = job
+	}
+	return job
+}
+
+// publish records the event and forwards it to every subscriber of the job
+func (b *progressBroker) publish(jobID int64, event progress.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job := b.getOrCreate(jobID)
+	if job.finished {
+		return
+	}
+
+	replaced := false
+	for i := range job.events {
+		if job.events[i].Stage == event.Stage {
+			job.events[i] = event
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		job.events = append(job.events, event)
+	}
+
+	for subscriber := range job.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			if !event.IsFinal() {
+				// subscriber is lagging, it will catch up with the next event of this stage
+				continue
+			}
+			// the final event must get through: make room by dropping the oldest pending event
+			select {
+			case <-subscriber:
+			default:
+			}
+			subscriber <- event
+		}
+	}
+
+	if event.IsFinal() {
+		job.finished = true
+		for subscriber := range job.subscribers {
+			close(subscriber)
+		}
+		job.subscribers = nil
+		time.AfterFunc(progressRetention, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.jobs, jobID)
+		})
+	}
+}
+
+// subscribe returns the events published so far and a channel receiving the following ones,
+// closed after the final event. finished is true if the job already published its final event.
+func (b *progressBroker) subscribe(jobID int64) (history []progress.Event, events chan progress.Event, finished bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job := b.getOrCreate(jobID)
+	history = append([]progress.Event(nil), job.events...)
+	if job.finished {
+		return history, nil, true
+	}
+
+	events = make(chan progress.Event, subscriberBufferSize)
+	job.subscribers[events] = struct{}{}
+	return history, events, false
+}
+
+// unsubscribe stops delivering events to a subscriber that went away.
+// Entries of jobs that published nothing yet are dropped with their last subscriber, publish recreates them.
+func (b *progressBroker) unsubscribe(jobID int64, events chan progress.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job, ok := b.jobs[jobID]
+	if !ok || job.finished {
+		return
+	}
+	delete(job.subscribers, events)
+	if len(job.subscribers) == 0 && len(job.events) == 0 {
+		delete(b.jobs, jobID)
+	}
+}
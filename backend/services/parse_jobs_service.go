@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"parser-service/internal/errors"
+	"parser-service/internal/progress"
+	"parser-service/models"
+	"parser-service/repositories"
+	"time"
+)
+
+const (
+	// DefaultParseWorkers is the number of parse jobs running concurrently
+	DefaultParseWorkers = 4
+	// parseJobQueueSize bounds the number of jobs waiting for a worker
+	parseJobQueueSize = 100
+	// parseJobTimeout bounds a single job, including Figma API retries and persistence
+	parseJobTimeout = 30 * time.Minute
+	// parseJobHeartbeatInterval is how often an instance reports being alive for the jobs it owns
+	parseJobHeartbeatInterval = 30 * time.Second
+	// parseJobHeartbeatTimeout is how long without a heartbeat before the jobs of an instance are swept
+	parseJobHeartbeatTimeout = 4 * parseJobHeartbeatInterval
+)
+
+// ParseJobsService runs ParseAndSaveFigmaFile in the background on a pool of workers.
+// Job state lives in the parse_jobs table so it can be polled and survives restarts. Each job is owned by the
+// instance holding its request, identified by workerID, so replicas sharing the table only sweep their own jobs
+// and those of instances that stopped heartbeating.
+type ParseJobsService struct {
+	ParserService       *ParserService
+	ParseJobsRepository repositories.IParseJobsRepository
+	workerID            string
+	workers             int
+	queue               chan parseJobRequest
+	progress            *progressBroker
+}
+
+// parseJobRequest is what a worker needs to run a job. The Figma token is only kept in memory, never persisted
+type parseJobRequest struct {
+	jobID      int64
+	figmaURL   string
+	figmaToken string
+}
+
+// NewParseJobsService creates the worker pool of an instance. workerID must be unique among the replicas and stable
+// across restarts of one, so that a restarted instance sweeps the jobs of its previous run. Start refuses an empty one
+func NewParseJobsService(parserService *ParserService, parseJobsRepo repositories.IParseJobsRepository, workers int, workerID string) *ParseJobsService {
+	if workers < 1 {
+		workers = DefaultParseWorkers
+	}
+	return &ParseJobsService{
+		ParserService:       parserService,
+		ParseJobsRepository: parseJobsRepo,
+		workerID:            workerID,
+		workers:             workers,
+		queue:               make(chan parseJobRequest, parseJobQueueSize),
+		progress:            newProgressBroker(),
+	}
+}
+
+// Start sweeps the jobs left unfinished by a previous run of this instance, then starts the worker pool and the
+// heartbeat. Unfinished jobs can't be resumed as the Figma token they were submitted with is not stored, they are
+// failed for their callers to submit them again.
+func (s *ParseJobsService) Start(ctx context.Context) error {
+	if s.workerID == "" {
+		return fmt.Errorf("a worker ID is required to own parse jobs")
+	}
+	if err := s.sweepParseJobs(ctx, s.workerID); err != nil {
+		return fmt.Errorf("failed to clean up unfinished parse jobs: %w", err)
+	}
+
+	for i := 0; i < s.workers; i++ {
+		go s.runWorker(ctx)
+	}
+	go s.runHeartbeat(ctx)
+	return nil
+}
+
+// sweepParseJobs fails the queued and running jobs of workerID and of instances that stopped heartbeating. An empty
+// workerID only sweeps the latter
+func (s *ParseJobsService) sweepParseJobs(ctx context.Context, workerID string) error {
+	interrupted, err := s.ParseJobsRepository.FailInterruptedParseJobs(ctx, workerID, parseJobHeartbeatTimeout, "parse job interrupted by a service restart, please submit it again")
+	if err != nil {
+		return err
+	}
+	if interrupted > 0 {
+		log.Printf("Marked %d interrupted parse jobs as failed", interrupted)
+	}
+	return nil
+}
+
+// runHeartbeat keeps the jobs of this instance owned and sweeps those of instances that are gone
+func (s *ParseJobsService) runHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(parseJobHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ParseJobsRepository.HeartbeatParseJobs(ctx, s.workerID); err != nil {
+				log.Printf("Failed to record parse jobs heartbeat: %v", err)
+			}
+			if err := s.sweepParseJobs(ctx, ""); err != nil {
+				log.Printf("Failed to sweep parse jobs of stopped instances: %v", err)
+			}
+		}
+	}
+}
+
+// EnqueueParseJob - Creates a queued job for the Figma URL and hands it to the worker pool.
+// The Figma token is taken from the context, like for synchronous parses
+func (s *ParseJobsService) EnqueueParseJob(ctx context.Context, figmaURL string) (*models.ParseJob, error) {
+	figmaToken, ok := ctx.Value("figma_token").(string)
+	if !ok || figmaToken == "" {
+		return nil, errors.InvalidInput("figma token is required")
+	}
+
+	job, err := s.ParseJobsRepository.CreateParseJob(ctx, &models.ParseJob{FigmaURL: figmaURL, WorkerID: s.workerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parse job: %w", err)
+	}
+
+	select {
+	case s.queue <- parseJobRequest{jobID: job.ID, figmaURL: figmaURL, figmaToken: figmaToken}:
+		return job, nil
+	default:
+		// don't leave a job queued forever that no worker will pick up
+		if err := s.ParseJobsRepository.MarkParseJobFailed(ctx, job.ID, "parse job queue is full"); err != nil {
+			log.Printf("Failed to mark parse job %d as failed: %v", job.ID, err)
+		}
+		return nil, &errors.RateLimitError{Message: "parse job queue is full, please retry later", RetryAfter: time.Minute}
+	}
+}
+
+// GetParseJob - Returns the current state of a parse job
+func (s *ParseJobsService) GetParseJob(ctx context.Context, jobID int64) (*models.ParseJob, error) {
+	job, err := s.ParseJobsRepository.GetParseJobByID(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.NotFound("parse job %d not found", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parse job: %w", err)
+	}
+	return job, nil
+}
+
+// SubscribeParseJobProgress - Returns the progress events of a job so far and a channel with the following ones,
+// closed after the final done/error event. For jobs that already finished the channel is nil and the history
+// ends with the final event. unsubscribe must be called once the caller stops reading.
+func (s *ParseJobsService) SubscribeParseJobProgress(ctx context.Context, jobID int64) (history []progress.Event, events <-chan progress.Event, unsubscribe func(), err error) {
+	job, err := s.GetParseJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	history, channel, finished := s.progress.subscribe(jobID)
+	if finished {
+		return history, nil, func() {}, nil
+	}
+	if job.Status == models.ParseJobSucceeded || job.Status == models.ParseJobFailed {
+		// finished before this process started or before the retention period ended, only the stored outcome is known
+		s.progress.unsubscribe(jobID, channel)
+		return []progress.Event{finalProgressEvent(job)}, nil, func() {}, nil
+	}
+
+	return history, channel, func() { s.progress.unsubscribe(jobID, channel) }, nil
+}
+
+// finalProgressEvent builds the done/error event matching a finished job
+func finalProgressEvent(job *models.ParseJob) progress.Event {
+	if job.Status == models.ParseJobFailed {
+		return progress.Event{Stage: progress.StageError, Message: job.Error, Time: time.Now()}
+	}
+	event := progress.Event{Stage: progress.StageDone, Time: time.Now()}
+	if job.FigmaFileID != nil {
+		event.FigmaFileID = *job.FigmaFileID
+	}
+	return event
+}
+
+func (s *ParseJobsService) runWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case request := <-s.queue:
+			s.runJob(ctx, request)
+		}
+	}
+}
+
+// runJob parses and persists one file, recording the outcome on the job
+func (s *ParseJobsService) runJob(ctx context.Context, request parseJobRequest) {
+	if err := s.ParseJobsRepository.MarkParseJobRunning(ctx, request.jobID); err != nil {
+		log.Printf("Failed to mark parse job %d as running: %v", request.jobID, err)
+	}
+
+	jobCtx, cancel := context.WithTimeout(context.WithValue(ctx, "figma_token", request.figmaToken), parseJobTimeout)
+	defer cancel()
+	jobCtx = progress.WithReporter(jobCtx, func(event progress.Event) {
+		s.progress.publish(request.jobID, event)
+	})
+
+	savedFile, err := s.ParserService.ParseAndSaveFigmaFile(jobCtx, request.figmaURL)
+	if err != nil {
+		log.Printf("Parse job %d failed: %v", request.jobID, err)
+		if markErr := s.ParseJobsRepository.MarkParseJobFailed(ctx, request.jobID, err.Error()); markErr != nil {
+			log.Printf("Failed to mark parse job %d as failed: %v", request.jobID, markErr)
+		}
+		progress.Report(jobCtx, progress.Event{Stage: progress.StageError, Message: err.Error()})
+		return
+	}
+
+	if err := s.ParseJobsRepository.MarkParseJobSucceeded(ctx, request.jobID, savedFile.ID); err != nil {
+		log.Printf("Failed to mark parse job %d as succeeded: %v", request.jobID, err)
+	}
+	progress.Report(jobCtx, progress.Event{Stage: progress.StageDone, FigmaFileID: savedFile.ID})
+}
@@ -0,0 +1,293 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"parser-service/internal/errors"
+	"parser-service/internal/progress"
+	"parser-service/models"
+	"parser-service/services"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryParseJobsRepository keeps parse jobs in memory
+type memoryParseJobsRepository struct {
+	mu   sync.Mutex
+	jobs map[int64]models.ParseJob
+}
+
+func newMemoryParseJobsRepository() *memoryParseJobsRepository {
+	return &memoryParseJobsRepository{jobs: map[int64]models.ParseJob{}}
+}
+
+func (r *memoryParseJobsRepository) GetParseJobByID(ctx context.Context, id int64) (*models.ParseJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &job, nil
+}
+
+func (r *memoryParseJobsRepository) CreateParseJob(ctx context.Context, job *models.ParseJob) (*models.ParseJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	job.ID = int64(len(r.jobs) + 1)
+	job.Status = models.ParseJobQueued
+	job.HeartbeatAt = &now
+	r.jobs[job.ID] = *job
+	return job, nil
+}
+
+func (r *memoryParseJobsRepository) update(id int64, apply func(job *models.ParseJob)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	apply(&job)
+	r.jobs[id] = job
+	return nil
+}
+
+func (r *memoryParseJobsRepository) MarkParseJobRunning(ctx context.Context, id int64) error {
+	return r.update(id, func(job *models.ParseJob) { job.Status = models.ParseJobRunning })
+}
+
+func (r *memoryParseJobsRepository) MarkParseJobSucceeded(ctx context.Context, id int64, figmaFileID int64) error {
+	return r.update(id, func(job *models.ParseJob) {
+		job.Status = models.ParseJobSucceeded
+		job.FigmaFileID = &figmaFileID
+	})
+}
+
+func (r *memoryParseJobsRepository) MarkParseJobFailed(ctx context.Context, id int64, errorMessage string) error {
+	return r.update(id, func(job *models.ParseJob) {
+		job.Status = models.ParseJobFailed
+		job.Error = errorMessage
+	})
+}
+
+func (r *memoryParseJobsRepository) HeartbeatParseJobs(ctx context.Context, workerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, job := range r.jobs {
+		if job.WorkerID == workerID && (job.Status == models.ParseJobQueued || job.Status == models.ParseJobRunning) {
+			job.HeartbeatAt = &now
+			r.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+func (r *memoryParseJobsRepository) FailInterruptedParseJobs(ctx context.Context, workerID string, heartbeatTimeout time.Duration, errorMessage string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	staleBefore := time.Now().Add(-heartbeatTimeout)
+	var count int64
+	for id, job := range r.jobs {
+		if job.Status != models.ParseJobQueued && job.Status != models.ParseJobRunning {
+			continue
+		}
+		if job.WorkerID == workerID || job.HeartbeatAt == nil || job.HeartbeatAt.Before(staleBefore) {
+			job.Status = models.ParseJobFailed
+			job.Error = errorMessage
+			r.jobs[id] = job
+			count++
+		}
+	}
+	return count, nil
+}
+
+// waitForJob polls the job until it reaches a final status
+func waitForJob(t *testing.T, service *services.ParseJobsService, jobID int64) *models.ParseJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.GetParseJob(context.Background(), jobID)
+		if err != nil {
+			t.Fatalf("Failed to get parse job: %v", err)
+		}
+		if job.Status == models.ParseJobSucceeded || job.Status == models.ParseJobFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Parse job %d did not finish in time", jobID)
+	return nil
+}
+
+func TestParseJobsService(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tokenCtx := context.WithValue(ctx, "figma_token", "figd_test")
+
+	t.Run("Succeeded job reports the saved file", func(t *testing.T) {
+		store := newFakeStore()
+		jobsRepo := newMemoryParseJobsRepository()
+		service := services.NewParseJobsService(newTestService(store), jobsRepo, 2, "worker-a")
+		if err := service.Start(ctx); err != nil {
+			t.Fatalf("Failed to start workers: %v", err)
+		}
+
+		job, err := service.EnqueueParseJob(tokenCtx, "https://www.figma.com/design/abcdefghijklmnop/Sample")
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+		if job.Status != models.ParseJobQueued {
+			t.Errorf("Expected new job to be queued, got %s", job.Status)
+		}
+
+		finished := waitForJob(t, service, job.ID)
+		if finished.Status != models.ParseJobSucceeded || finished.FigmaFileID == nil {
+			t.Fatalf("Expected job to succeed with a figma file id, got %+v", finished)
+		}
+		if store.committed["figma_files"] != 1 {
+			t.Errorf("Expected the file to be persisted, got %d rows", store.committed["figma_files"])
+		}
+	})
+
+	t.Run("Failed job reports the error", func(t *testing.T) {
+		store := newFakeStore()
+		store.failTable = "components"
+		jobsRepo := newMemoryParseJobsRepository()
+		service := services.NewParseJobsService(newTestService(store), jobsRepo, 1, "worker-a")
+		if err := service.Start(ctx); err != nil {
+			t.Fatalf("Failed to start workers: %v", err)
+		}
+
+		job, err := service.EnqueueParseJob(tokenCtx, "https://www.figma.com/design/abcdefghijklmnop/Sample")
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+		finished := waitForJob(t, service, job.ID)
+		if finished.Status != models.ParseJobFailed || finished.Error == "" {
+			t.Fatalf("Expected job to fail with an error message, got %+v", finished)
+		}
+	})
+
+	t.Run("Progress events end with done", func(t *testing.T) {
+		jobsRepo := newMemoryParseJobsRepository()
+		service := services.NewParseJobsService(newTestService(newFakeStore()), jobsRepo, 1, "worker-a")
+		if err := service.Start(ctx); err != nil {
+			t.Fatalf("Failed to start workers: %v", err)
+		}
+
+		job, err := service.EnqueueParseJob(tokenCtx, "https://www.figma.com/design/abcdefghijklmnop/Sample")
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+		history, events, unsubscribe, err := service.SubscribeParseJobProgress(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+		defer unsubscribe()
+
+		received := append([]progress.Event(nil), history...)
+		if events != nil {
+			timeout := time.After(5 * time.Second)
+		collect:
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						break collect
+					}
+					received = append(received, event)
+				case <-timeout:
+					t.Fatal("Timed out waiting for progress events")
+				}
+			}
+		}
+
+		if len(received) == 0 {
+			t.Fatal("Expected progress events")
+		}
+		last := received[len(received)-1]
+		if last.Stage != progress.StageDone || last.FigmaFileID == 0 {
+			t.Errorf("Expected stream to end with done and the figma file id, got %+v", last)
+		}
+
+		// subscribing after the job finished replays the outcome
+		history, events, _, err = service.SubscribeParseJobProgress(ctx, job.ID)
+		if err != nil || events != nil || len(history) == 0 || history[len(history)-1].Stage != progress.StageDone {
+			t.Errorf("Expected finished job to replay its final event, got %+v (err %v)", history, err)
+		}
+	})
+
+	t.Run("Start sweeps the jobs of this worker only", func(t *testing.T) {
+		jobsRepo := newMemoryParseJobsRepository()
+		figmaURL := "https://www.figma.com/design/abcdefghijklmnop/Sample"
+		newJob := func(workerID string, status models.ParseJobStatus, heartbeat time.Duration) int64 {
+			job, _ := jobsRepo.CreateParseJob(ctx, &models.ParseJob{FigmaURL: figmaURL, WorkerID: workerID})
+			jobsRepo.update(job.ID, func(job *models.ParseJob) {
+				lastHeartbeat := time.Now().Add(-heartbeat)
+				job.Status, job.HeartbeatAt = status, &lastHeartbeat
+			})
+			return job.ID
+		}
+		ownRunning := newJob("worker-a", models.ParseJobRunning, 0)
+		ownQueued := newJob("worker-a", models.ParseJobQueued, 0)
+		otherRunning := newJob("worker-b", models.ParseJobRunning, 0)
+		otherQueued := newJob("worker-b", models.ParseJobQueued, 0)
+		staleRunning := newJob("worker-c", models.ParseJobRunning, time.Hour)
+		staleQueued := newJob("worker-c", models.ParseJobQueued, time.Hour)
+
+		service := services.NewParseJobsService(newTestService(newFakeStore()), jobsRepo, 1, "worker-a")
+		if err := service.Start(ctx); err != nil {
+			t.Fatalf("Failed to start workers: %v", err)
+		}
+
+		expectations := []struct {
+			name   string
+			id     int64
+			status models.ParseJobStatus
+		}{
+			{"running job of this worker", ownRunning, models.ParseJobFailed},
+			{"queued job of this worker", ownQueued, models.ParseJobFailed},
+			{"running job of another worker", otherRunning, models.ParseJobRunning},
+			{"queued job of another worker", otherQueued, models.ParseJobQueued},
+			{"running job of a stopped worker", staleRunning, models.ParseJobFailed},
+			{"queued job of a stopped worker", staleQueued, models.ParseJobFailed},
+		}
+		for _, expected := range expectations {
+			job, _ := jobsRepo.GetParseJobByID(ctx, expected.id)
+			if job.Status != expected.status {
+				t.Errorf("Expected %s to be %s, got %s", expected.name, expected.status, job.Status)
+			}
+			if job.Status == models.ParseJobFailed && !strings.Contains(job.Error, "submit it again") {
+				t.Errorf("Expected %s to ask for a new submission, got %q", expected.name, job.Error)
+			}
+		}
+
+		// resubmitting the URL creates a job of its own
+		job, err := service.EnqueueParseJob(tokenCtx, figmaURL)
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+		if job.ID == ownQueued || job.ID == otherQueued {
+			t.Fatalf("Expected a new job, got the existing job %d", job.ID)
+		}
+		if finished := waitForJob(t, service, job.ID); finished.Status != models.ParseJobSucceeded {
+			t.Errorf("Expected the new job to succeed, got %+v", finished)
+		}
+
+		if _, err := service.GetParseJob(ctx, 999); !errors.Is(err, errors.ErrNotFound) {
+			t.Errorf("Expected not found for unknown job, got: %v", err)
+		}
+	})
+
+	t.Run("Start requires a worker ID", func(t *testing.T) {
+		service := services.NewParseJobsService(newTestService(newFakeStore()), newMemoryParseJobsRepository(), 1, "")
+		if err := service.Start(ctx); err == nil {
+			t.Error("Expected workers without a worker ID not to start")
+		}
+	})
+}
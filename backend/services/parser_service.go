@@ -2,30 +2,54 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"io"
+	"log"
+	"parser-service/internal/db_manager"
+	"parser-service/internal/errors"
 	"parser-service/internal/figma_manager"
+	"parser-service/internal/progress"
 	"parser-service/models"
 	"parser-service/repositories"
 )
 
 type ParserService struct {
+	DB                   db_manager.ItxDB
 	FigmaManager         figma_manager.IFigmaManager
 	FigmaFilesRepository repositories.IFigmaFilesRepository
+	PagesRepository      repositories.IPagesRepository
 	ComponentsRepository repositories.IComponentsRepository
 	InstancesRepository  repositories.IInstancesRepository
+	NodesRepository      repositories.INodesRepository
+	TextNodesRepository  repositories.ITextNodesRepository
+	StylesRepository     repositories.IStylesRepository
+	VariablesRepository  repositories.IVariablesRepository
 }
 
 func NewParserService(
+	db db_manager.ItxDB,
 	figmaManager figma_manager.IFigmaManager,
 	figmaFilesRepo repositories.IFigmaFilesRepository,
+	pagesRepo repositories.IPagesRepository,
 	componentsRepo repositories.IComponentsRepository,
 	instancesRepo repositories.IInstancesRepository,
+	nodesRepo repositories.INodesRepository,
+	textNodesRepo repositories.ITextNodesRepository,
+	stylesRepo repositories.IStylesRepository,
+	variablesRepo repositories.IVariablesRepository,
 ) *ParserService {
 	return &ParserService{
+		DB:                   db,
 		FigmaManager:         figmaManager,
 		FigmaFilesRepository: figmaFilesRepo,
+		PagesRepository:      pagesRepo,
 		ComponentsRepository: componentsRepo,
 		InstancesRepository:  instancesRepo,
+		NodesRepository:      nodesRepo,
+		TextNodesRepository:  textNodesRepo,
+		StylesRepository:     stylesRepo,
+		VariablesRepository:  variablesRepo,
 	}
 }
 
@@ -36,75 +60,411 @@ func (s *ParserService) ParseAndSaveFigmaFile(ctx context.Context, figmaURL stri
 		return nil, fmt.Errorf("failed to parse Figma file: %w", err)
 	}
 
+	return s.persistParsedData(ctx, parsedData)
+}
+
+// ParseAndSaveFigmaJSON - Parses a saved Figma file JSON export (offline, no Figma API calls) and saves all extracted data
+func (s *ParserService) ParseAndSaveFigmaJSON(ctx context.Context, fileJSON io.Reader, figmaURL string) (*models.FigmaFile, error) {
+	parsedData, err := s.FigmaManager.ParseFigmaFileFromJSON(ctx, fileJSON, figmaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Figma file JSON: %w", err)
+	}
+
+	return s.persistParsedData(ctx, parsedData)
+}
+
+// persistParsedData saves file, components and instances all-or-nothing,
+// so a failure halfway never leaves a partially saved file behind
+func (s *ParserService) persistParsedData(ctx context.Context, parsedData *figma_manager.ParsedFigmaData) (*models.FigmaFile, error) {
+	var savedFile *models.FigmaFile
+	err := db_manager.WrapInTransaction(ctx, s.DB, func(ctx context.Context) (err error) {
+		savedFile, err = s.saveParsedData(ctx, parsedData)
+		return err
+	}, func(err error) {
+		log.Printf("Rolled back save of Figma file %s: %v", parsedData.File.FileKey, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return savedFile, nil
+}
+
+// saveParsedData saves the file record, its pages, node tree, components, instances, text nodes, styles and variables.
+// Must run inside a transaction
+func (s *ParserService) saveParsedData(ctx context.Context, parsedData *figma_manager.ParsedFigmaData) (*models.FigmaFile, error) {
+	rows := &rowCounter{ctx: ctx}
+
 	// Save the Figma file record first
 	savedFile, err := s.FigmaFilesRepository.CreateFigmaFile(ctx, parsedData.File)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save Figma file: %w", err)
 	}
+	rows.add()
+
+	// Save pages first so components and instances can reference them
+	pageIDs, err := s.savePages(ctx, parsedData.Pages, savedFile.ID, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save pages: %w", err)
+	}
+
+	// Save the whole node hierarchy
+	err = s.saveNodes(ctx, parsedData.Nodes, savedFile.ID, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save nodes: %w", err)
+	}
 
 	// Save components with the file ID
-	savedComponents, err := s.saveComponents(ctx, parsedData.Components, savedFile.ID)
+	savedComponents, componentIDs, err := s.saveComponents(ctx, parsedData.Components, savedFile.ID, pageIDs, rows)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save components: %w", err)
 	}
 
+	// Link external components to the synced team library, if any
+	if _, err := s.ComponentsRepository.LinkLibraryComponents(ctx, savedFile.ID); err != nil {
+		return nil, fmt.Errorf("failed to link library components: %w", err)
+	}
+
 	// Save instances with proper component references
-	err = s.saveInstances(ctx, parsedData.Instances, savedComponents)
+	instanceIDs, err := s.saveInstances(ctx, parsedData.Instances, savedComponents, componentIDs, pageIDs, rows)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save instances: %w", err)
 	}
 
+	// Save text nodes linked to their owning component or instance
+	err = s.saveTextNodes(ctx, parsedData.TextNodes, savedFile.ID, pageIDs, componentIDs, instanceIDs, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save text nodes: %w", err)
+	}
+
+	// Save styles and link them to the components and instances using them
+	err = s.saveStyles(ctx, parsedData.Styles, parsedData.StyleUsages, savedFile.ID, componentIDs, instanceIDs, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save styles: %w", err)
+	}
+
+	// Save variables with their values, and link them to the components and instances bound to them
+	err = s.saveVariables(ctx, parsedData, savedFile.ID, componentIDs, instanceIDs, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save variables: %w", err)
+	}
+
+	rows.flush()
 	return savedFile, nil
 }
 
-// GetFigmaFileWithDetails - Retrieve a complete Figma file with components and instances
-func (s *ParserService) GetFigmaFileWithDetails(ctx context.Context, fileID int64) (*FigmaFileDetails, error) {
+// GetFigmaFileWithDetails - Retrieve a complete Figma file with pages, components and instances.
+// Components and instances are limited to a page when pageID is not 0
+func (s *ParserService) GetFigmaFileWithDetails(ctx context.Context, fileID int64, pageID int64) (*FigmaFileDetails, error) {
 	// Get file
-	file, err := s.FigmaFilesRepository.GetFigmaFileByID(ctx, fileID)
+	file, err := s.getFigmaFile(ctx, fileID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file: %w", err)
+		return nil, err
+	}
+
+	// Get pages
+	pages, err := s.PagesRepository.GetPagesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
 	}
 
 	// Get components
-	components, err := s.ComponentsRepository.GetComponentsByFigmaFileID(ctx, fileID)
+	components, err := s.GetFigmaFileComponents(ctx, fileID, repositories.ComponentFilter{PageID: pageID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get components: %w", err)
+		return nil, err
 	}
 
 	// Get instances for the file
-	instances, err := s.InstancesRepository.GetInstancesByFigmaFileID(ctx, fileID)
+	instances, err := s.GetFigmaFileInstances(ctx, fileID, repositories.InstanceFilter{PageID: pageID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get instances: %w", err)
+		return nil, err
 	}
 
 	return &FigmaFileDetails{
 		File:       file,
+		Pages:      pages,
 		Components: components,
 		Instances:  instances,
 	}, nil
 }
 
-// saveComponents saves components and returns the saved components with database IDs
-func (s *ParserService) saveComponents(ctx context.Context, components []models.Component, fileID int64) ([]models.Component, error) {
+// GetFigmaFilePages - Retrieve the pages of a Figma file, in document order
+func (s *ParserService) GetFigmaFilePages(ctx context.Context, fileID int64) ([]models.Page, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	pages, err := s.PagesRepository.GetPagesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+	return pages, nil
+}
+
+// GetFigmaFileComponents - Retrieve the components of a Figma file, narrowed down by page, component set
+// and variant properties when set in filter
+func (s *ParserService) GetFigmaFileComponents(ctx context.Context, fileID int64, filter repositories.ComponentFilter) ([]models.Component, error) {
+	if err := s.checkPage(ctx, fileID, filter.PageID); err != nil {
+		return nil, err
+	}
+
+	components, err := s.ComponentsRepository.FindComponents(ctx, fileID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get components: %w", err)
+	}
+
+	for i := range components {
+		components[i].Layout = models.LayoutFromProperties(components[i].Properties)
+	}
+	return components, nil
+}
+
+// GetFigmaFileInstances - Retrieve the instances of a Figma file, narrowed down by page and minimum number
+// of overrides when set in filter
+func (s *ParserService) GetFigmaFileInstances(ctx context.Context, fileID int64, filter repositories.InstanceFilter) ([]models.Instance, error) {
+	if err := s.checkPage(ctx, fileID, filter.PageID); err != nil {
+		return nil, err
+	}
+
+	instances, err := s.InstancesRepository.FindInstances(ctx, fileID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instances: %w", err)
+	}
+
+	for i := range instances {
+		instances[i].Layout = models.LayoutFromProperties(instances[i].Properties)
+	}
+	return instances, nil
+}
+
+// GetFigmaFileStyles - Retrieve the shared styles of a Figma file with how many components and instances use them,
+// only those of styleType when not empty
+func (s *ParserService) GetFigmaFileStyles(ctx context.Context, fileID int64, styleType string) ([]models.Style, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	styles, err := s.StylesRepository.GetStylesByFigmaFileID(ctx, fileID, styleType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get styles: %w", err)
+	}
+	return styles, nil
+}
+
+// GetFigmaFileVariables - Retrieve the variable collections of a Figma file, each with its variables and their
+// values by mode
+func (s *ParserService) GetFigmaFileVariables(ctx context.Context, fileID int64) ([]models.VariableCollection, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	collections, err := s.VariablesRepository.GetVariableCollectionsByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variable collections: %w", err)
+	}
+	variables, err := s.VariablesRepository.GetVariablesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variables: %w", err)
+	}
+	values, err := s.VariablesRepository.GetVariableValuesByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variable values: %w", err)
+	}
+
+	valuesByVariable := make(map[int64][]models.VariableValue, len(variables))
+	for _, value := range values {
+		valuesByVariable[value.VariableID] = append(valuesByVariable[value.VariableID], value)
+	}
+	collectionIndexes := make(map[int64]int, len(collections))
+	for i, collection := range collections {
+		collectionIndexes[collection.ID] = i
+	}
+	for _, variable := range variables {
+		i, exists := collectionIndexes[variable.VariableCollectionID]
+		if !exists {
+			continue
+		}
+		variable.Values = valuesByVariable[variable.ID]
+		collections[i].Variables = append(collections[i].Variables, variable)
+	}
+
+	if collections == nil {
+		collections = []models.VariableCollection{}
+	}
+	return collections, nil
+}
+
+// GetFigmaFileTextNodes - Retrieve the text nodes of a Figma file, limited to a page when pageID is not 0
+func (s *ParserService) GetFigmaFileTextNodes(ctx context.Context, fileID int64, pageID int64) ([]models.TextNode, error) {
+	if err := s.checkPage(ctx, fileID, pageID); err != nil {
+		return nil, err
+	}
+
+	var textNodes []models.TextNode
+	var err error
+	if pageID != 0 {
+		textNodes, err = s.TextNodesRepository.GetTextNodesByPageID(ctx, pageID)
+	} else {
+		textNodes, err = s.TextNodesRepository.GetTextNodesByFigmaFileID(ctx, fileID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text nodes: %w", err)
+	}
+	return textNodes, nil
+}
+
+// getFigmaFile returns the file, or a NotFound error when it doesn't exist
+func (s *ParserService) getFigmaFile(ctx context.Context, fileID int64) (*models.FigmaFile, error) {
+	file, err := s.FigmaFilesRepository.GetFigmaFileByID(ctx, fileID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.NotFound("figma file %d not found", fileID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return file, nil
+}
+
+// checkPage makes sure the file exists and, when pageID is not 0, that the page belongs to it
+func (s *ParserService) checkPage(ctx context.Context, fileID int64, pageID int64) error {
+	if pageID == 0 {
+		_, err := s.getFigmaFile(ctx, fileID)
+		return err
+	}
+
+	page, err := s.PagesRepository.GetPageByID(ctx, pageID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && page.FigmaFileID != fileID) {
+		return errors.NotFound("page %d not found in figma file %d", pageID, fileID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get page: %w", err)
+	}
+	return nil
+}
+
+// GetFigmaFileTree - Retrieve the node hierarchy of a Figma file, starting at rootNodeID (the document when empty)
+// and going at most maxDepth levels down (unlimited when negative)
+func (s *ParserService) GetFigmaFileTree(ctx context.Context, fileID int64, rootNodeID string, maxDepth int) (*NodeTree, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.NodesRepository.GetNodeTree(ctx, fileID, rootNodeID, maxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		if rootNodeID != "" {
+			return nil, errors.NotFound("node %s not found in figma file %d", rootNodeID, fileID)
+		}
+		return nil, errors.NotFound("figma file %d has no node tree, parse it again to capture it", fileID)
+	}
+
+	return buildNodeTree(nodes), nil
+}
+
+// buildNodeTree links nodes into a tree. Nodes must be ordered parents first, the first one being the root
+func buildNodeTree(nodes []models.Node) *NodeTree {
+	trees := make(map[int64]*NodeTree, len(nodes))
+	root := &NodeTree{Node: nodes[0]}
+	trees[root.ID] = root
+
+	root.Layout = models.LayoutFromProperties(root.Properties)
+
+	for _, node := range nodes[1:] {
+		node.Layout = models.LayoutFromProperties(node.Properties)
+		tree := &NodeTree{Node: node}
+		trees[node.ID] = tree
+		if node.ParentID == nil {
+			continue
+		}
+		if parent, exists := trees[*node.ParentID]; exists {
+			parent.Children = append(parent.Children, tree)
+		}
+	}
+
+	return root
+}
+
+// savePages saves the pages and returns their database IDs by Figma node ID
+func (s *ParserService) savePages(ctx context.Context, pages []models.Page, fileID int64, rows *rowCounter) (map[string]int64, error) {
+	pageIDs := make(map[string]int64, len(pages))
+
+	for _, page := range pages {
+		page.FigmaFileID = fileID
+
+		savedPage, err := s.PagesRepository.CreatePage(ctx, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save page %s: %w", page.Name, err)
+		}
+
+		pageIDs[savedPage.NodeID] = savedPage.ID
+		rows.add()
+	}
+
+	return pageIDs, nil
+}
+
+// saveNodes saves the node hierarchy, resolving each node's parent to its database ID.
+// Nodes come in pre-order so a parent is always saved before its children
+func (s *ParserService) saveNodes(ctx context.Context, nodes []models.Node, fileID int64, rows *rowCounter) error {
+	nodeIDMap := make(map[string]int64, len(nodes))
+
+	for _, node := range nodes {
+		node.FigmaFileID = fileID
+		if parentID, exists := nodeIDMap[node.ParentNodeID]; exists {
+			node.ParentID = &parentID
+		}
+
+		savedNode, err := s.NodesRepository.CreateNode(ctx, &node)
+		if err != nil {
+			return fmt.Errorf("failed to save node %s: %w", node.NodeID, err)
+		}
+
+		nodeIDMap[savedNode.NodeID] = savedNode.ID
+		rows.add()
+	}
+
+	return nil
+}
+
+// saveComponents saves components and returns the saved components with database IDs, and those IDs by Figma node ID
+func (s *ParserService) saveComponents(ctx context.Context, components []models.Component, fileID int64, pageIDs map[string]int64, rows *rowCounter) ([]models.Component, map[string]int64, error) {
 	var savedComponents []models.Component
+	componentIDs := make(map[string]int64, len(components))
+	// The parser puts component sets first, so a set is saved before its variants
+	componentSetIDs := make(map[string]int64)
 
 	for _, component := range components {
-		// Set the file ID for database foreign key
+		// Set the file, page and component set IDs for database foreign keys
 		component.FigmaFileID = fileID
+		if pageID, exists := pageIDs[component.PageNodeID]; exists {
+			component.PageID = &pageID
+		}
+		if componentSetID, exists := componentSetIDs[component.ComponentSetNodeID]; exists {
+			component.ComponentSetID = &componentSetID
+		}
 
 		savedComponent, err := s.ComponentsRepository.CreateComponent(ctx, &component)
 		if err != nil {
-			return nil, fmt.Errorf("failed to save component %s: %w", component.Name, err)
+			return nil, nil, fmt.Errorf("failed to save component %s: %w", component.Name, err)
 		}
 
 		savedComponents = append(savedComponents, *savedComponent)
+		componentIDs[savedComponent.NodeID] = savedComponent.ID
+		if savedComponent.Type == "COMPONENT_SET" {
+			componentSetIDs[savedComponent.NodeID] = savedComponent.ID
+		}
+		rows.add()
 	}
 
-	return savedComponents, nil
+	return savedComponents, componentIDs, nil
 }
 
-// saveInstances saves instances and resolves component relationships
-func (s *ParserService) saveInstances(ctx context.Context, instances []models.Instance, savedComponents []models.Component) error {
+// saveInstances saves instances, resolving component relationships, and returns the database IDs of the saved
+// instances by Figma node ID
+func (s *ParserService) saveInstances(ctx context.Context, instances []models.Instance, savedComponents []models.Component, componentIDs map[string]int64, pageIDs map[string]int64, rows *rowCounter) (map[string]int64, error) {
+
 	// Create a map of temporary component IDs to actual database IDs
 	componentIDMap := make(map[int64]int64)
 	for i, component := range savedComponents {
@@ -113,27 +473,202 @@ func (s *ParserService) saveInstances(ctx context.Context, instances []models.In
 		componentIDMap[tempID] = component.ID
 	}
 
+	// instances come parents first, so a parent instance is saved before the instances nested in it
+	instanceIDs := make(map[string]int64, len(instances))
+
 	for _, instance := range instances {
 		// Resolve the temporary component ID to actual database ID
 		if actualComponentID, exists := componentIDMap[instance.ComponentID]; exists {
 			instance.ComponentID = actualComponentID
+			if pageID, exists := pageIDs[instance.PageNodeID]; exists {
+				instance.PageID = &pageID
+			}
+			if parentInstanceID, exists := instanceIDs[instance.ParentInstanceNodeID]; exists {
+				instance.ParentInstanceID = &parentInstanceID
+			}
+			if containingComponentID, exists := componentIDs[instance.ContainingComponentNodeID]; exists {
+				instance.ContainingComponentID = &containingComponentID
+			}
 
-			_, err := s.InstancesRepository.CreateInstance(ctx, &instance)
+			savedInstance, err := s.InstancesRepository.CreateInstance(ctx, &instance)
 			if err != nil {
-				return fmt.Errorf("failed to save instance %s: %w", instance.Name, err)
+				return nil, fmt.Errorf("failed to save instance %s: %w", instance.Name, err)
+			}
+			instanceIDs[savedInstance.NodeID] = savedInstance.ID
+			rows.add()
+		} else {
+			// Log warning but don't fail - the parser adds stubs for unknown components so this shouldn't happen
+			log.Printf("Could not resolve component ID %d for instance %s, skipping it", instance.ComponentID, instance.NodeID)
+		}
+	}
+
+	return instanceIDs, nil
+}
+
+// saveTextNodes saves text nodes, resolving their page and owning component or instance to database IDs.
+// Text inside an instance that couldn't be saved is kept without an owner
+func (s *ParserService) saveTextNodes(ctx context.Context, textNodes []models.TextNode, fileID int64, pageIDs map[string]int64, componentIDs map[string]int64, instanceIDs map[string]int64, rows *rowCounter) error {
+	for _, textNode := range textNodes {
+		textNode.FigmaFileID = fileID
+		if pageID, exists := pageIDs[textNode.PageNodeID]; exists {
+			textNode.PageID = &pageID
+		}
+		if componentID, exists := componentIDs[textNode.ComponentNodeID]; exists {
+			textNode.ComponentID = &componentID
+		}
+		if instanceID, exists := instanceIDs[textNode.InstanceNodeID]; exists {
+			textNode.InstanceID = &instanceID
+		}
+
+		_, err := s.TextNodesRepository.CreateTextNode(ctx, &textNode)
+		if err != nil {
+			return fmt.Errorf("failed to save text node %s: %w", textNode.NodeID, err)
+		}
+		rows.add()
+	}
+
+	return nil
+}
+
+// saveStyles saves the file styles, then their usages resolved to the database IDs of the style and of the
+// owning component or instance. Usages whose owner couldn't be saved are skipped
+func (s *ParserService) saveStyles(ctx context.Context, styles []models.Style, usages []models.StyleUsage, fileID int64, componentIDs map[string]int64, instanceIDs map[string]int64, rows *rowCounter) error {
+	styleIDs := make(map[string]int64, len(styles))
+	for _, style := range styles {
+		style.FigmaFileID = fileID
+
+		savedStyle, err := s.StylesRepository.CreateStyle(ctx, &style)
+		if err != nil {
+			return fmt.Errorf("failed to save style %s: %w", style.Name, err)
+		}
+		styleIDs[savedStyle.NodeID] = savedStyle.ID
+		rows.add()
+	}
+
+	for _, usage := range usages {
+		styleID, exists := styleIDs[usage.StyleNodeID]
+		if !exists {
+			continue
+		}
+		usage.StyleID = styleID
+		if componentID, exists := componentIDs[usage.ComponentNodeID]; exists {
+			usage.ComponentID = &componentID
+		} else if instanceID, exists := instanceIDs[usage.InstanceNodeID]; exists {
+			usage.InstanceID = &instanceID
+		} else {
+			continue
+		}
+
+		if _, err := s.StylesRepository.CreateStyleUsage(ctx, &usage); err != nil {
+			return fmt.Errorf("failed to save usage of style %s by %s: %w", usage.StyleNodeID, usage.NodeID, err)
+		}
+		rows.add()
+	}
+
+	return nil
+}
+
+// saveVariables saves the variable collections of the file, their variables with a value per mode, then the
+// variable bindings resolved to the database IDs of the variable and of the owning component or instance.
+// Bindings whose owner couldn't be saved are skipped
+func (s *ParserService) saveVariables(ctx context.Context, parsedData *figma_manager.ParsedFigmaData, fileID int64, componentIDs map[string]int64, instanceIDs map[string]int64, rows *rowCounter) error {
+	collectionIDs := make(map[string]int64, len(parsedData.VariableCollections))
+	for _, collection := range parsedData.VariableCollections {
+		collection.FigmaFileID = fileID
+
+		savedCollection, err := s.VariablesRepository.CreateVariableCollection(ctx, &collection)
+		if err != nil {
+			return fmt.Errorf("failed to save variable collection %s: %w", collection.Name, err)
+		}
+		collectionIDs[savedCollection.FigmaCollectionID] = savedCollection.ID
+		rows.add()
+	}
+
+	variableIDs := make(map[string]int64, len(parsedData.Variables))
+	for _, variable := range parsedData.Variables {
+		collectionID, exists := collectionIDs[variable.FigmaCollectionID]
+		if !exists {
+			log.Printf("Could not resolve collection %s of variable %s, skipping it", variable.FigmaCollectionID, variable.Name)
+			continue
+		}
+		variable.FigmaFileID = fileID
+		variable.VariableCollectionID = collectionID
+
+		savedVariable, err := s.VariablesRepository.CreateVariable(ctx, &variable)
+		if err != nil {
+			return fmt.Errorf("failed to save variable %s: %w", variable.Name, err)
+		}
+		variableIDs[savedVariable.FigmaVariableID] = savedVariable.ID
+		rows.add()
+
+		for _, value := range variable.Values {
+			value.VariableID = savedVariable.ID
+			if _, err := s.VariablesRepository.CreateVariableValue(ctx, &value); err != nil {
+				return fmt.Errorf("failed to save value of variable %s in mode %s: %w", variable.Name, value.ModeID, err)
 			}
+			rows.add()
+		}
+	}
+
+	for _, binding := range parsedData.VariableBindings {
+		variableID, exists := variableIDs[binding.FigmaVariableID]
+		if !exists {
+			continue
+		}
+		binding.VariableID = variableID
+		if componentID, exists := componentIDs[binding.ComponentNodeID]; exists {
+			binding.ComponentID = &componentID
+		} else if instanceID, exists := instanceIDs[binding.InstanceNodeID]; exists {
+			binding.InstanceID = &instanceID
 		} else {
-			// Log warning but don't fail - this might happen if component wasn't found during parsing
-			fmt.Printf("Warning: Could not resolve component ID %d for instance %s\n", instance.ComponentID, instance.Name)
+			continue
 		}
+
+		if _, err := s.VariablesRepository.CreateVariableBinding(ctx, &binding); err != nil {
+			return fmt.Errorf("failed to save binding of variable %s to %s: %w", binding.FigmaVariableID, binding.NodeID, err)
+		}
+		rows.add()
 	}
 
 	return nil
 }
 
+// rowsPersistedReportInterval is how often (in rows) persistence progress is reported
+const rowsPersistedReportInterval = 100
+
+// rowCounter counts saved rows and reports them as progress events
+type rowCounter struct {
+	ctx          context.Context
+	rows         int64
+	lastReported int64
+}
+
+func (c *rowCounter) add() {
+	c.rows++
+	if c.rows-c.lastReported >= rowsPersistedReportInterval {
+		c.flush()
+	}
+}
+
+// flush reports the rows saved so far, if not reported yet
+func (c *rowCounter) flush() {
+	if c.rows == c.lastReported {
+		return
+	}
+	c.lastReported = c.rows
+	progress.Report(c.ctx, progress.Event{Stage: progress.StageRowsPersisted, Count: c.rows})
+}
+
 // FigmaFileDetails represents a complete Figma file with all related data
 type FigmaFileDetails struct {
 	File       *models.FigmaFile  `json:"file"`
+	Pages      []models.Page      `json:"pages"`
 	Components []models.Component `json:"components"`
 	Instances  []models.Instance  `json:"instances"`
 }
+
+// NodeTree is a node with its children, as returned by GetFigmaFileTree
+type NodeTree struct {
+	models.Node
+	Children []*NodeTree `json:"children,omitempty"`
+}
@@ -0,0 +1,243 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"parser-service/internal/db_manager"
+	"parser-service/internal/figma_manager"
+	"parser-service/models"
+	"parser-service/repositories"
+	"parser-service/services"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore is a minimal transactional row counter behind a database/sql driver.
+// Inserts made inside a transaction only become visible once it commits.
+type fakeStore struct {
+	mu        sync.Mutex
+	nextID    int64
+	committed map[string]int
+	failTable string // table whose insert fails once failAfter inserts succeeded
+	failAfter int
+	attempts  map[string]int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{committed: map[string]int{}, attempts: map[string]int{}}
+}
+
+func (s *fakeStore) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{store: s}, nil
+}
+
+func (s *fakeStore) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	store   *fakeStore
+	pending map[string]int // non-nil while a transaction is open
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = map[string]int{}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	for table, count := range c.pending {
+		c.store.committed[table] += count
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	// updates don't add rows, they only need to succeed
+	if strings.HasPrefix(s.query, "UPDATE ") {
+		return driver.RowsAffected(0), nil
+	}
+	return nil, fmt.Errorf("exec not supported by fake store: %s", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	table, ok := strings.CutPrefix(s.query, "INSERT INTO ")
+	if !ok {
+		return nil, fmt.Errorf("query not supported by fake store: %s", s.query)
+	}
+	table = strings.Fields(table)[0]
+
+	store := s.conn.store
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if table == store.failTable && store.attempts[table] >= store.failAfter {
+		return nil, fmt.Errorf("injected failure inserting into %s", table)
+	}
+	store.attempts[table]++
+	store.nextID++
+	if s.conn.pending != nil {
+		s.conn.pending[table]++
+	} else {
+		store.committed[table]++
+	}
+	now := time.Now()
+	return &fakeRows{values: []driver.Value{store.nextID, now, now}}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "created_at", "updated_at"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.done = true
+	return nil
+}
+
+// fakeFigmaManager returns canned parse results instead of calling Figma
+type fakeFigmaManager struct {
+	figma_manager.IFigmaManager
+	data *figma_manager.ParsedFigmaData
+}
+
+func (m *fakeFigmaManager) ParseFigmaFileFromURL(ctx context.Context, figmaURL string) (*figma_manager.ParsedFigmaData, error) {
+	return m.data, nil
+}
+
+func sampleParsedData() *figma_manager.ParsedFigmaData {
+	return &figma_manager.ParsedFigmaData{
+		File: &models.FigmaFile{Name: "Sample", FileKey: "abcdefghijklmnop"},
+		Pages: []models.Page{
+			{NodeID: "0:1", Name: "Page 1"},
+		},
+		Nodes: []models.Node{
+			{NodeID: "0:0", Name: "Document", Type: "DOCUMENT"},
+			{NodeID: "0:1", ParentNodeID: "0:0", Name: "Page 1", Type: "CANVAS", Depth: 1},
+			{NodeID: "1:1", ParentNodeID: "0:1", Name: "Button", Type: "COMPONENT", Depth: 2},
+		},
+		Components: []models.Component{
+			{NodeID: "1:1", Name: "Button", Type: "COMPONENT", PageNodeID: "0:1"},
+			{NodeID: "1:2", Name: "Icon", Type: "COMPONENT"},
+		},
+		Instances: []models.Instance{
+			{NodeID: "2:1", Name: "Button", ComponentID: 1},
+			{NodeID: "2:2", Name: "Icon", ComponentID: 2},
+		},
+		TextNodes: []models.TextNode{
+			{NodeID: "1:3", Name: "Label", Characters: "Click me", ComponentNodeID: "1:1", PageNodeID: "0:1"},
+		},
+		Styles: []models.Style{
+			{NodeID: "5:1", Name: "Brand/Blue", StyleType: "FILL"},
+		},
+		StyleUsages: []models.StyleUsage{
+			{StyleNodeID: "5:1", ComponentNodeID: "1:1", NodeID: "1:1", Property: "fill"},
+			{StyleNodeID: "5:1", InstanceNodeID: "2:1", NodeID: "2:1", Property: "fill"},
+		},
+		VariableCollections: []models.VariableCollection{
+			{FigmaCollectionID: "VariableCollectionId:1:1", Name: "Semantic"},
+		},
+		Variables: []models.Variable{
+			{FigmaCollectionID: "VariableCollectionId:1:1", FigmaVariableID: "VariableID:1:2", Name: "color/primary", ResolvedType: "COLOR", Values: []models.VariableValue{
+				{ModeID: "1:0", ModeName: "Light", Value: []byte(`"#3366FF"`), ResolvedValue: []byte(`"#3366FF"`)},
+				{ModeID: "1:1", ModeName: "Dark", Value: []byte(`"#1A1A1A"`), ResolvedValue: []byte(`"#1A1A1A"`)},
+			}},
+		},
+		VariableBindings: []models.VariableBinding{
+			{FigmaVariableID: "VariableID:1:2", ComponentNodeID: "1:1", NodeID: "1:1", Property: "fills"},
+		},
+	}
+}
+
+func newTestService(store *fakeStore) *services.ParserService {
+	db := db_manager.NewDB(sql.OpenDB(store))
+	return services.NewParserService(
+		db,
+		&fakeFigmaManager{data: sampleParsedData()},
+		repositories.NewFigmaFilesRepository(*db),
+		repositories.NewPagesRepository(*db),
+		repositories.NewComponentsRepository(*db),
+		repositories.NewInstancesRepository(*db),
+		repositories.NewNodesRepository(*db),
+		repositories.NewTextNodesRepository(*db),
+		repositories.NewStylesRepository(*db),
+		repositories.NewVariablesRepository(*db),
+	)
+}
+
+func TestParseAndSaveFigmaFile_CommitsAllRows(t *testing.T) {
+	store := newFakeStore()
+	service := newTestService(store)
+
+	savedFile, err := service.ParseAndSaveFigmaFile(context.Background(), "https://www.figma.com/design/abcdefghijklmnop/Sample")
+	if err != nil {
+		t.Fatalf("Expected save to succeed, got: %v", err)
+	}
+	if savedFile.ID == 0 {
+		t.Error("Expected saved file to have a database ID")
+	}
+
+	expected := map[string]int{"figma_files": 1, "pages": 1, "nodes": 3, "components": 2, "instances": 2, "text_nodes": 1, "styles": 1, "style_usages": 2,
+		"variable_collections": 1, "variables": 1, "variable_values": 2, "variable_bindings": 1}
+	for table, count := range expected {
+		if store.committed[table] != count {
+			t.Errorf("Expected %d committed rows in %s, got %d", count, table, store.committed[table])
+		}
+	}
+}
+
+func TestParseAndSaveFigmaFile_RollsBackOnMidSaveFailure(t *testing.T) {
+	store := newFakeStore()
+	// fail on the second instance, after the file and all components were written
+	store.failTable = "instances"
+	store.failAfter = 1
+	service := newTestService(store)
+
+	_, err := service.ParseAndSaveFigmaFile(context.Background(), "https://www.figma.com/design/abcdefghijklmnop/Sample")
+	if err == nil {
+		t.Fatal("Expected save to fail on injected error")
+	}
+	if !strings.Contains(err.Error(), "injected failure") {
+		t.Errorf("Expected injected failure to be surfaced, got: %v", err)
+	}
+	if store.attempts["figma_files"] != 1 || store.attempts["nodes"] != 3 || store.attempts["components"] != 2 {
+		t.Fatalf("Expected file, nodes and components to be written before the failure, got %v", store.attempts)
+	}
+
+	for table, count := range store.committed {
+		if count != 0 {
+			t.Errorf("Expected no rows left behind in %s, got %d", table, count)
+		}
+	}
+}
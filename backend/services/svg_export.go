@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"parser-service/internal/codegen"
+	"parser-service/internal/errors"
+)
+
+// ExportFigmaNodeSVG - Draw a node of a Figma file, typically an icon, as SVG from the vector geometry stored when
+// the file was parsed
+func (s *ParserService) ExportFigmaNodeSVG(ctx context.Context, fileID int64, nodeID string) (*codegen.File, error) {
+	if _, err := s.getFigmaFile(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.NodesRepository.GetNodeTree(ctx, fileID, nodeID, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, errors.NotFound("node %s not found in figma file %d", nodeID, fileID)
+	}
+
+	return codegen.ExportSVG(nodes, nodeID)
+}
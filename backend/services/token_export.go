@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"parser-service/internal/token_exporter"
+)
+
+// ExportFigmaFileTokens - Export the design tokens of a Figma file in format: its variables first, then its color,
+// text and effect styles, then the corner radii and spacings of its components
+func (s *ParserService) ExportFigmaFileTokens(ctx context.Context, fileID int64, format token_exporter.Format) ([]byte, error) {
+	file, err := s.getFigmaFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := s.GetFigmaFileVariables(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	styles, err := s.StylesRepository.GetStylesByFigmaFileID(ctx, fileID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get styles: %w", err)
+	}
+	components, err := s.ComponentsRepository.GetComponentsByFigmaFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get components: %w", err)
+	}
+
+	// Earlier sources win when two tokens end up with the same name
+	tokens := token_exporter.NewTokenSet(file.Name)
+	tokens.AddVariables(collections)
+	tokens.AddStyles(styles)
+	tokens.AddComponents(components)
+
+	return token_exporter.Export(tokens, format)
+}
@@ -14,7 +14,7 @@ export const api = {
     
     if (!response.ok) {
       const errorData = await response.json().catch(() => ({}));
-      throw new Error(errorData.error || `Failed to parse Figma file: ${response.statusText}`);
+      throw new Error(errorData.message || errorData.err || `Failed to parse Figma file: ${response.statusText}`);
     }
     
     return response.json();